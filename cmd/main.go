@@ -1,7 +1,28 @@
 package main
 
-import "github.com/S1FFFkA/todo-list/internal/app"
+import (
+	"os"
+
+	"github.com/S1FFFkA/todo-list/internal/app"
+)
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			app.Migrate(os.Args[2:])
+			return
+		case "backup":
+			app.Backup(os.Args[2:])
+			return
+		case "restore":
+			app.Restore(os.Args[2:])
+			return
+		case "events":
+			app.Events(os.Args[2:])
+			return
+		}
+	}
+
 	app.Run()
 }
@@ -6,9 +6,12 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/S1FFFkA/todo-list/internal/config"
+	"github.com/S1FFFkA/todo-list/internal/domain"
 	"github.com/S1FFFkA/todo-list/internal/handlers"
 	"github.com/S1FFFkA/todo-list/internal/server"
 	"github.com/S1FFFkA/todo-list/internal/service"
@@ -21,17 +24,70 @@ func Run() {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 
-	taskService := service.NewTaskService()
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Logger.Error("failed to load config", "error", err.Error())
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	store, err := openStorage(cfg.Storage)
+	if err != nil {
+		logger.Logger.Error("failed to open storage", "driver", cfg.Storage.Driver, "error", err.Error())
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+
+	taskService, err := service.NewTaskService(context.Background(), store.tasks, service.WithUndoDepth(cfg.UndoDepth))
+	if err != nil {
+		logger.Logger.Error("failed to initialize task service", "error", err.Error())
+		log.Fatalf("Failed to initialize task service: %v", err)
+	}
+
+	if cfg.OrphanTasksOwner != "" {
+		if err := claimOrphanTasks(context.Background(), taskService, store.users, cfg.OrphanTasksOwner); err != nil {
+			logger.Logger.Error("failed to claim tasks without owner", "user", cfg.OrphanTasksOwner, "error", err.Error())
+			log.Fatalf("Failed to claim tasks without owner: %v", err)
+		}
+	}
+
+	signer, err := newSigner(cfg.Tokens)
+	if err != nil {
+		logger.Logger.Error("failed to initialize token signer", "algorithm", cfg.Tokens.Algorithm, "error", err.Error())
+		log.Fatalf("Failed to initialize token signer: %v", err)
+	}
+	authService := service.NewAuthService(store.users, store.apiKeys, store.tokens, service.TokenConfig{
+		Signer:     signer,
+		Issuer:     cfg.Tokens.Issuer,
+		AccessTTL:  cfg.Tokens.AccessTTL,
+		RefreshTTL: cfg.Tokens.RefreshTTL,
+	})
+
+	// Фоновые очистка корзины и архивация обслуживают задачи всех
+	// пользователей и останавливаются перед закрытием хранилища
+	jobsCtx, stopJobs := context.WithCancel(domain.WithAllUsers(context.Background()))
+	var jobs sync.WaitGroup
+	if cfg.Trash.Retention > 0 {
+		jobs.Go(func() { runTrashPurge(jobsCtx, taskService, cfg.Trash) })
+	}
+	if cfg.Archive.After > 0 {
+		jobs.Go(func() { runAutoArchive(jobsCtx, taskService, cfg.Archive) })
+	}
+	jobs.Go(func() { runTokenCleanup(jobsCtx, authService, cfg.Tokens) })
+
 	taskHandler := handlers.NewTaskHandler(taskService)
-	router := server.NewRouter(taskHandler)
+	userService := service.NewUserService(store.users, store.apiKeys)
+	userHandler := handlers.NewUserHandler(userService)
+	authHandler := handlers.NewAuthHandler(authService)
+	router := server.NewRouter(taskHandler, userHandler, authHandler,
+		server.WithIdempotencyTTL(cfg.IdempotencyTTL),
+		server.WithAuthenticator(authService, cfg.AuthRequired),
+	)
 
-	port := ":8080"
 	srv := &http.Server{
-		Addr:    port,
+		Addr:    cfg.HTTPAddr,
 		Handler: router,
 	}
 
-	logger.Logger.Info("starting server", "port", port)
+	logger.Logger.Info("starting server", "port", cfg.HTTPAddr, "storage", cfg.Storage.Driver, "auth_required", cfg.AuthRequired, "jwt_algorithm", signer.Alg())
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -54,5 +110,12 @@ func Run() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	stopJobs()
+	jobs.Wait()
+
+	if err := store.close(); err != nil {
+		logger.Logger.Error("failed to close storage", "error", err.Error())
+	}
+
 	logger.Logger.Info("server exited gracefully")
 }
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/config"
+	"github.com/S1FFFkA/todo-list/internal/service"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+// runAutoArchive убирает в архив задачи, закрытые дольше cfg.After: сразу
+// при старте и затем раз в cfg.Interval. Возвращается после отмены ctx.
+func runAutoArchive(ctx context.Context, taskService *service.TaskService, cfg config.Archive) {
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := taskService.ArchiveClosed(ctx, time.Now().Add(-cfg.After))
+		if err != nil && ctx.Err() == nil {
+			logger.Logger.Error("failed to archive tasks", "error", err.Error())
+		}
+		if n > 0 {
+			logger.Logger.Info("archived tasks", "tasks", n, "after", cfg.After.String())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
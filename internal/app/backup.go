@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/config"
+	"github.com/S1FFFkA/todo-list/internal/repository/sqlstore"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+// Backup реализует подкоманду backup <файл>: согласованная копия базы SQLite
+// снимается без остановки сервера.
+func Backup(args []string) {
+	cfg := loadSQLiteConfig("backup", args)
+
+	db, _, err := openSQL(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	started := time.Now()
+	if err := sqlstore.BackupSQLite(ctx, db, args[0]); err != nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+	logger.Logger.Info("backup created", "source", cfg.Storage.SQLite.Path, "dest", args[0], "duration", time.Since(started).String())
+}
+
+// Restore реализует подкоманду restore <файл>: база SQLite заменяется
+// проверенной резервной копией. Сервер должен быть остановлен.
+func Restore(args []string) {
+	cfg := loadSQLiteConfig("restore", args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	if err := sqlstore.RestoreSQLite(ctx, args[0], cfg.Storage.SQLite.Path); err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+	logger.Logger.Info("database restored", "source", args[0], "dest", cfg.Storage.SQLite.Path)
+}
+
+func loadSQLiteConfig(command string, args []string) *config.Config {
+	if err := logger.InitLogger(); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	if len(args) != 1 {
+		log.Fatalf("Usage: todo-list %s <file>", command)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Storage.Driver != "sqlite" {
+		log.Fatalf("%s is supported only for STORAGE_DRIVER=sqlite, got %q", command, cfg.Storage.Driver)
+	}
+	return cfg
+}
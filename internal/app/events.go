@@ -0,0 +1,69 @@
+package app
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/S1FFFkA/todo-list/internal/config"
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository/file"
+)
+
+// Events реализует подкоманду events для разбора журнала событий
+// STORAGE_DIR: "list [id задачи]" (по умолчанию) печатает события по одному
+// JSON в строке, "replay <seq>" — состояние задач сразу после события seq.
+// Журнал только читается, поэтому сервер останавливать не нужно.
+func Events(args []string) {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	action := "list"
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	switch action {
+	case "list":
+		taskID := 0
+		if len(args) > 0 {
+			if taskID, err = strconv.Atoi(args[0]); err != nil {
+				log.Fatalf("Invalid task ID %q", args[0])
+			}
+		}
+		err = file.ReadEvents(cfg.Storage.File.Dir, func(e *domain.Event) error {
+			if taskID != 0 && e.TaskID != taskID {
+				return nil
+			}
+			return enc.Encode(e)
+		})
+	case "replay":
+		if len(args) != 1 {
+			log.Fatalf("Usage: todo-list events replay <seq>")
+		}
+		seq, perr := strconv.ParseUint(args[0], 10, 64)
+		if perr != nil {
+			log.Fatalf("Invalid event seq %q", args[0])
+		}
+		state := domain.NewProjection()
+		err = file.ReadEvents(cfg.Storage.File.Dir, func(e *domain.Event) error {
+			if e.Seq > seq {
+				return nil
+			}
+			return state.Apply(e)
+		})
+		if err == nil {
+			enc.SetIndent("", "    ")
+			err = enc.Encode(state.SortedTasks())
+		}
+	default:
+		log.Fatalf("Unknown events action %q, expected list or replay", action)
+	}
+	if err != nil {
+		log.Fatalf("Failed to read events: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/config"
+	"github.com/S1FFFkA/todo-list/internal/repository/sqlstore"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+// Migrate реализует подкоманду migrate: "up" (по умолчанию) применяет
+// недостающие миграции, "status" печатает их состояние.
+func Migrate(args []string) {
+	if err := logger.InitLogger(); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	db, dialect, err := openSQL(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+	defer db.Close()
+
+	switch action {
+	case "up":
+		if err := migrate(db, dialect); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	case "status":
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		migrations, err := sqlstore.MigrationStatus(ctx, db, dialect)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, m := range migrations {
+			state := "pending"
+			if m.AppliedAt != nil {
+				state = "applied " + m.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(os.Stdout, "%04d %-40s %s\n", m.Version, m.Name, state)
+		}
+	default:
+		log.Fatalf("Unknown migrate action %q, expected up or status", action)
+	}
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/service"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+// claimOrphanTasks передаёт пользователю name задачи без владельца, см.
+// service.TaskService.ClaimTasks.
+func claimOrphanTasks(ctx context.Context, taskService *service.TaskService, users repository.UserRepository, name string) error {
+	name, err := domain.NormalizeUserName(name)
+	if err != nil {
+		return err
+	}
+	user, err := users.GetByName(ctx, name)
+	if err != nil {
+		return fmt.Errorf("user %q: %w", name, err)
+	}
+
+	n, err := taskService.ClaimTasks(domain.WithAllUsers(ctx), user.ID)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.Logger.Info("claimed tasks without owner", "tasks", n, "user", user.Name)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/config"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/file"
+	"github.com/S1FFFkA/todo-list/internal/repository/memory"
+	"github.com/S1FFFkA/todo-list/internal/repository/sqlstore"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+// storage — хранилища задач, пользователей, ключей API и токенов. close
+// нужно вызвать при остановке сервера.
+type storage struct {
+	tasks   repository.TaskRepository
+	users   repository.UserRepository
+	apiKeys repository.APIKeyRepository
+	tokens  repository.TokenRepository
+	close   func() error
+}
+
+// openStorage открывает хранилища по конфигурации. Файловые драйверы
+// хранят пользователей, ключи и токены в том же каталоге, SQL — в той же
+// базе.
+func openStorage(cfg config.Storage) (*storage, error) {
+	switch cfg.Driver {
+	case "memory":
+		return &storage{
+			tasks:   memory.NewTaskRepository(),
+			users:   memory.NewUserRepository(),
+			apiKeys: memory.NewAPIKeyRepository(),
+			tokens:  memory.NewTokenRepository(),
+			close:   func() error { return nil },
+		}, nil
+	case "file":
+		policy, err := file.ParseFsyncPolicy(cfg.File.Fsync)
+		if err != nil {
+			return nil, err
+		}
+
+		opts := file.Options{
+			FsyncPolicy:       policy,
+			FsyncInterval:     cfg.File.FsyncInterval,
+			SnapshotInterval:  cfg.File.SnapshotInterval,
+			SnapshotThreshold: cfg.File.SnapshotThreshold,
+			OnError: func(err error) {
+				logger.Logger.Error("file storage background error", "error", err.Error())
+			},
+		}
+		repo, err := file.OpenTaskRepository(cfg.File.Dir, opts)
+		if err != nil {
+			return nil, err
+		}
+		users, err := file.OpenUserRepository(cfg.File.Dir, opts)
+		if err != nil {
+			repo.Close()
+			return nil, err
+		}
+		apiKeys, err := file.OpenAPIKeyRepository(cfg.File.Dir, opts)
+		if err != nil {
+			repo.Close()
+			users.Close()
+			return nil, err
+		}
+		tokens, err := file.OpenTokenRepository(cfg.File.Dir, opts)
+		if err != nil {
+			repo.Close()
+			users.Close()
+			apiKeys.Close()
+			return nil, err
+		}
+
+		recovery := repo.Recovery()
+		logger.Logger.Info("file storage recovered",
+			"dir", cfg.File.Dir,
+			"fsync", policy.String(),
+			"snapshot_seq", recovery.SnapshotSeq,
+			"snapshot_tasks", recovery.SnapshotItems,
+			"replayed_records", recovery.Replayed,
+		)
+		if recovery.TruncatedBytes > 0 {
+			logger.Logger.Warn("truncated damaged WAL tail", "bytes", recovery.TruncatedBytes)
+		}
+
+		return &storage{
+			tasks:   repo,
+			users:   users,
+			apiKeys: apiKeys,
+			tokens:  tokens,
+			close:   func() error { return errors.Join(repo.Close(), users.Close(), apiKeys.Close(), tokens.Close()) },
+		}, nil
+	case "events":
+		policy, err := file.ParseFsyncPolicy(cfg.File.Fsync)
+		if err != nil {
+			return nil, err
+		}
+
+		opts := file.Options{
+			FsyncPolicy:   policy,
+			FsyncInterval: cfg.File.FsyncInterval,
+			OnError: func(err error) {
+				logger.Logger.Error("event storage background error", "error", err.Error())
+			},
+		}
+		repo, err := file.OpenEventRepository(cfg.File.Dir, opts)
+		if err != nil {
+			return nil, err
+		}
+		users, err := file.OpenUserRepository(cfg.File.Dir, opts)
+		if err != nil {
+			repo.Close()
+			return nil, err
+		}
+		apiKeys, err := file.OpenAPIKeyRepository(cfg.File.Dir, opts)
+		if err != nil {
+			repo.Close()
+			users.Close()
+			return nil, err
+		}
+		tokens, err := file.OpenTokenRepository(cfg.File.Dir, opts)
+		if err != nil {
+			repo.Close()
+			users.Close()
+			apiKeys.Close()
+			return nil, err
+		}
+
+		recovery := repo.Recovery()
+		logger.Logger.Info("event log replayed",
+			"dir", cfg.File.Dir,
+			"fsync", policy.String(),
+			"replayed_events", recovery.Replayed,
+		)
+		if recovery.TruncatedBytes > 0 {
+			logger.Logger.Warn("truncated damaged event log tail", "bytes", recovery.TruncatedBytes)
+		}
+
+		return &storage{
+			tasks:   repo,
+			users:   users,
+			apiKeys: apiKeys,
+			tokens:  tokens,
+			close:   func() error { return errors.Join(repo.Close(), users.Close(), apiKeys.Close(), tokens.Close()) },
+		}, nil
+	case "postgres", "sqlite":
+		db, dialect, err := openSQL(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.AutoMigrate {
+			if err := migrate(db, dialect); err != nil {
+				db.Close()
+				return nil, err
+			}
+		}
+
+		return &storage{
+			tasks:   sqlstore.NewTaskRepository(db, dialect),
+			users:   sqlstore.NewUserRepository(db, dialect),
+			apiKeys: sqlstore.NewAPIKeyRepository(db, dialect),
+			tokens:  sqlstore.NewTokenRepository(db, dialect),
+			close:   db.Close,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+func openSQL(cfg config.Storage) (*sql.DB, *sqlstore.Dialect, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	switch cfg.Driver {
+	case "postgres":
+		db, err := sqlstore.Open(ctx, sqlstore.Postgres, cfg.Postgres.DSN)
+		return db, sqlstore.Postgres, err
+	case "sqlite":
+		db, err := sqlstore.OpenSQLite(ctx, cfg.SQLite.Path)
+		return db, sqlstore.SQLite, err
+	default:
+		return nil, nil, fmt.Errorf("storage driver %q is not SQL-backed", cfg.Driver)
+	}
+}
+
+func migrate(db *sql.DB, dialect *sqlstore.Dialect) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	applied, err := sqlstore.Migrate(ctx, db, dialect)
+	for _, m := range applied {
+		logger.Logger.Info("applied migration", "dialect", dialect.Name, "version", m.Version, "name", m.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", dialect.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/auth"
+	"github.com/S1FFFkA/todo-list/internal/config"
+	"github.com/S1FFFkA/todo-list/internal/service"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+// newSigner создаёт подпись токенов доступа по конфигурации. Без
+// JWT_SECRET ключ HS256 генерируется случайно: токены доступа перестанут
+// приниматься после перезапуска, и клиентам придётся их обновить.
+func newSigner(cfg config.Tokens) (auth.Signer, error) {
+	switch cfg.Algorithm {
+	case auth.HS256:
+		secret := []byte(cfg.Secret)
+		if len(secret) == 0 {
+			logger.Logger.Warn("JWT_SECRET is not set, using a random key; access tokens will not survive a restart")
+			secret = make([]byte, auth.MinHMACSecretLength)
+			rand.Read(secret)
+		}
+		return auth.NewHMACSigner(secret)
+	case auth.EdDSA:
+		data, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key, err := auth.ParseEd25519PrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.PrivateKeyFile, err)
+		}
+		return auth.NewEd25519Signer(key)
+	default:
+		return nil, fmt.Errorf("unknown JWT algorithm %q", cfg.Algorithm)
+	}
+}
+
+// runTokenCleanup удаляет истёкшие токены обновления и записи об
+// отозванных токенах доступа: сразу при старте и затем раз в
+// cfg.CleanupInterval. Возвращается после отмены ctx.
+func runTokenCleanup(ctx context.Context, authService *service.AuthService, cfg config.Tokens) {
+	ticker := time.NewTicker(cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := authService.PurgeExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Logger.Error("failed to delete expired tokens", "error", err.Error())
+		}
+		if n > 0 {
+			logger.Logger.Info("deleted expired tokens", "tokens", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
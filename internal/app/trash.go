@@ -0,0 +1,34 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/config"
+	"github.com/S1FFFkA/todo-list/internal/service"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+// runTrashPurge удаляет навсегда задачи, пролежавшие в корзине дольше
+// cfg.Retention: сразу при старте и затем раз в cfg.PurgeInterval.
+// Возвращается после отмены ctx.
+func runTrashPurge(ctx context.Context, taskService *service.TaskService, cfg config.Trash) {
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := taskService.PurgeTrash(ctx, time.Now().Add(-cfg.Retention))
+		if err != nil && ctx.Err() == nil {
+			logger.Logger.Error("failed to purge trash", "error", err.Error())
+		}
+		if n > 0 {
+			logger.Logger.Info("purged trash", "tasks", n, "retention", cfg.Retention.String())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
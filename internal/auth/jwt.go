@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Алгоритмы подписи токенов.
+const (
+	HS256 = "HS256"
+	EdDSA = "EdDSA"
+)
+
+// MinHMACSecretLength — наименьшая длина секрета HS256 в байтах: секрет не
+// должен быть короче выхода SHA-256.
+const MinHMACSecretLength = 32
+
+// clockSkew — допустимое расхождение часов при проверке iat.
+const clockSkew = time.Minute
+
+var ErrInvalidToken = errors.New("invalid token")
+
+var b64url = base64.RawURLEncoding
+
+// Claims — поля токена доступа.
+type Claims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Name      string `json:"name,omitempty"`
+	ID        string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// Signer подписывает и проверяет токены одним алгоритмом. Проверка
+// принимает только токены этого алгоритма, так что токен с "alg": "none"
+// или подписанный открытым ключом как секретом HMAC не пройдёт.
+type Signer interface {
+	Alg() string
+	sign(data []byte) []byte
+	verify(data []byte, sig []byte) bool
+}
+
+type hmacSigner struct {
+	secret []byte
+}
+
+// NewHMACSigner возвращает подпись HS256 с общим секретом.
+func NewHMACSigner(secret []byte) (Signer, error) {
+	if len(secret) < MinHMACSecretLength {
+		return nil, fmt.Errorf("HS256 secret must be at least %d bytes", MinHMACSecretLength)
+	}
+	return &hmacSigner{secret: secret}, nil
+}
+
+func (s *hmacSigner) Alg() string { return HS256 }
+
+func (s *hmacSigner) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func (s *hmacSigner) verify(data []byte, sig []byte) bool {
+	return hmac.Equal(s.sign(data), sig)
+}
+
+type ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewEd25519Signer возвращает подпись EdDSA закрытым ключом Ed25519.
+func NewEd25519Signer(key ed25519.PrivateKey) (Signer, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("Ed25519 private key must be %d bytes", ed25519.PrivateKeySize)
+	}
+	return &ed25519Signer{key: key}, nil
+}
+
+func (s *ed25519Signer) Alg() string { return EdDSA }
+
+func (s *ed25519Signer) sign(data []byte) []byte {
+	return ed25519.Sign(s.key, data)
+}
+
+func (s *ed25519Signer) verify(data []byte, sig []byte) bool {
+	return ed25519.Verify(s.key.Public().(ed25519.PublicKey), data, sig)
+}
+
+// ParseEd25519PrivateKey читает закрытый ключ Ed25519 в PEM (PKCS #8), как
+// его выдаёт openssl genpkey -algorithm ed25519.
+func ParseEd25519PrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("Ed25519 key must be a PEM PRIVATE KEY block")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key is %T, not Ed25519", key)
+	}
+	return edKey, nil
+}
+
+// Sign кодирует claims в подписанный токен JWT.
+func Sign(s Signer, claims Claims) (string, error) {
+	h, err := json.Marshal(header{Alg: s.Alg(), Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	data := b64url.EncodeToString(h) + "." + b64url.EncodeToString(payload)
+	return data + "." + b64url.EncodeToString(s.sign([]byte(data))), nil
+}
+
+// Verify проверяет подпись, издателя и срок действия токена и возвращает
+// его поля. Любая ошибка оборачивает ErrInvalidToken.
+func Verify(s Signer, token string, issuer string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, err
+	}
+	if h.Alg != s.Alg() {
+		return nil, fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidToken, h.Alg)
+	}
+	sig, err := b64url.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if !s.verify([]byte(parts[0]+"."+parts[1]), sig) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	switch {
+	case claims.Issuer != issuer:
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	case now.Unix() >= claims.ExpiresAt:
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidToken)
+	case time.Unix(claims.IssuedAt, 0).After(now.Add(clockSkew)):
+		return nil, fmt.Errorf("%w: token issued in the future", ErrInvalidToken)
+	}
+	return &claims, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := b64url.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	return nil
+}
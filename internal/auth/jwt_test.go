@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSigners(t *testing.T) []Signer {
+	hs, err := NewHMACSigner([]byte(strings.Repeat("s", MinHMACSecretLength)))
+	if err != nil {
+		t.Fatalf("hmac: %v", err)
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	ed, err := NewEd25519Signer(key)
+	if err != nil {
+		t.Fatalf("ed25519: %v", err)
+	}
+	return []Signer{hs, ed}
+}
+
+func TestSignVerify(t *testing.T) {
+	now := time.Now()
+	claims := Claims{Issuer: "todo", Subject: "42", Name: "alice", ID: "abc", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()}
+
+	for _, s := range testSigners(t) {
+		t.Run(s.Alg(), func(t *testing.T) {
+			token, err := Sign(s, claims)
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			got, err := Verify(s, token, "todo", now)
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if *got != claims {
+				t.Errorf("claims: %+v", got)
+			}
+
+			if _, err := Verify(s, token, "todo", now.Add(time.Minute)); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expired: want ErrInvalidToken, got %v", err)
+			}
+			if _, err := Verify(s, token, "other", now); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("issuer: want ErrInvalidToken, got %v", err)
+			}
+			parts := strings.Split(token, ".")
+			forged, _ := Sign(s, Claims{Issuer: "todo", Subject: "1", ExpiresAt: claims.ExpiresAt})
+			tampered := parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+			if _, err := Verify(s, tampered, "todo", now); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("tampered: want ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsOtherAlgorithm(t *testing.T) {
+	signers := testSigners(t)
+	now := time.Now()
+	token, err := Sign(signers[1], Claims{Issuer: "todo", ExpiresAt: now.Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := Verify(signers[0], token, "todo", now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("want ErrInvalidToken, got %v", err)
+	}
+
+	none := b64url.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + strings.Split(token, ".")[1] + "."
+	if _, err := Verify(signers[0], none, "todo", now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("alg none: want ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestNewHMACSignerShortSecret(t *testing.T) {
+	if _, err := NewHMACSigner([]byte("short")); err == nil {
+		t.Error("no error for short secret")
+	}
+}
+
+func TestParseEd25519PrivateKey(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	got, err := ParseEd25519PrivateKey(data)
+	if err != nil || !got.Equal(key) {
+		t.Errorf("parse: %v", err)
+	}
+	if _, err := ParseEd25519PrivateKey([]byte("not a key")); err == nil {
+		t.Error("no error for garbage")
+	}
+}
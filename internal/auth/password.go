@@ -0,0 +1,120 @@
+// Package auth реализует хеширование паролей и подписанные токены доступа
+// JWT.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Параметры Argon2id по второй рекомендации RFC 9106: 64 МиБ памяти,
+// три прохода. Параметры записываются в хеш, поэтому их можно менять, не
+// ломая сохранённые пароли.
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	argonKeyLen  = 32
+	argonSaltLen = 16
+	// maxArgonMemory — предел памяти из параметров сохранённого хеша, КиБ.
+	maxArgonMemory = 4 * argonMemory
+)
+
+// maxConcurrentHashes — сколько вычислений Argon2id идёт одновременно.
+// Каждое занимает 64 МиБ, поэтому без предела поток входов и регистраций
+// исчерпал бы память; остальные запросы ждут очереди.
+const maxConcurrentHashes = 4
+
+var hashSlots = make(chan struct{}, maxConcurrentHashes)
+
+// idKey вычисляет Argon2id, дождавшись свободного места. Если ctx
+// завершился раньше, возвращается его ошибка.
+func idKey(ctx context.Context, password string, salt []byte, time uint32, memory uint32, threads uint8, keyLen uint32) ([]byte, error) {
+	select {
+	case hashSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-hashSlots }()
+	return argon2.IDKey([]byte(password), salt, time, memory, threads, keyLen), nil
+}
+
+var ErrInvalidHash = errors.New("invalid password hash")
+
+var b64 = base64.RawStdEncoding
+
+// HashPassword хеширует пароль Argon2id со случайной солью и возвращает
+// хеш в формате PHC:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<соль>$<хеш>
+func HashPassword(ctx context.Context, password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := idKey(ctx, password, salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argonMemory, argonTime, argonThreads, b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+// VerifyPassword сравнивает пароль с хешем из HashPassword за время, не
+// зависящее от совпадения. Хеш, которому нужно больше maxArgonMemory
+// памяти, считается неверным.
+func VerifyPassword(ctx context.Context, hash string, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return false, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrInvalidHash
+	}
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil || time == 0 || threads == 0 || memory > maxArgonMemory {
+		return false, ErrInvalidHash
+	}
+	salt, err := b64.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+	want, err := b64.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return false, ErrInvalidHash
+	}
+
+	got, err := idKey(ctx, password, salt, time, memory, threads, uint32(len(want)))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
+
+// dummyHash — хеш случайного пароля. Проверка по нему занимает столько
+// же, сколько настоящая, и скрывает, существует ли пользователь.
+var dummyHash = sync.OnceValue(func() string {
+	hash, err := HashPassword(context.Background(), rand.Text())
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// WastePasswordCheck выполняет проверку пароля, результат которой не
+// нужен: её вызывают для неизвестного пользователя, чтобы ответ не
+// приходил быстрее, чем для существующего.
+func WastePasswordCheck(ctx context.Context, password string) {
+	VerifyPassword(ctx, dummyHash(), password)
+}
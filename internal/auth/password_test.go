@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHashPassword(t *testing.T) {
+	ctx := context.Background()
+	hash, err := HashPassword(ctx, "correct horse")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$") || strings.Contains(hash, "correct horse") {
+		t.Errorf("hash: %s", hash)
+	}
+	if other, _ := HashPassword(ctx, "correct horse"); other == hash {
+		t.Error("same hash for two calls, salt is not random")
+	}
+
+	if ok, err := VerifyPassword(ctx, hash, "correct horse"); !ok || err != nil {
+		t.Errorf("right password: %v, %v", ok, err)
+	}
+	if ok, err := VerifyPassword(ctx, hash, "correct horse "); ok || err != nil {
+		t.Errorf("wrong password: %v, %v", ok, err)
+	}
+}
+
+func TestVerifyPasswordInvalidHash(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"plain",
+		"$argon2i$v=19$m=65536,t=3,p=4$c2FsdA$aGFzaA",
+		"$argon2id$v=16$m=65536,t=3,p=4$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=65536,t=0,p=4$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=65536,t=3,p=4$!!$aGFzaA",
+		"$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$",
+		"$argon2id$v=19$m=4194304,t=3,p=4$c2FsdA$aGFzaA",
+	} {
+		if _, err := VerifyPassword(context.Background(), hash, "x"); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("%q: want ErrInvalidHash, got %v", hash, err)
+		}
+	}
+}
+
+func TestPasswordHashingLimit(t *testing.T) {
+	hash, err := HashPassword(context.Background(), "correct horse")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+
+	// Все места заняты: вычисление ждёт, пока не истечёт контекст
+	for range maxConcurrentHashes {
+		hashSlots <- struct{}{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := HashPassword(ctx, "correct horse"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("hash: want DeadlineExceeded, got %v", err)
+	}
+	if _, err := VerifyPassword(ctx, hash, "correct horse"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("verify: want DeadlineExceeded, got %v", err)
+	}
+
+	done := make(chan bool)
+	go func() {
+		ok, _ := VerifyPassword(context.Background(), hash, "correct horse")
+		done <- ok
+	}()
+	<-hashSlots
+	if !<-done {
+		t.Error("verify after slot freed failed")
+	}
+	for range maxConcurrentHashes - 1 {
+		<-hashSlots
+	}
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+type Config struct {
+	HTTPAddr string
+	Storage  Storage
+	Trash    Trash
+	Archive  Archive
+	// UndoDepth — сколько последних операций каждого клиента можно
+	// отменить; 0 отключает отмену.
+	UndoDepth int
+	// IdempotencyTTL — сколько хранится ответ на запрос с Idempotency-Key;
+	// 0 отключает поддержку ключей.
+	IdempotencyTTL time.Duration
+	// AuthRequired — запросы без ключа API отклоняются с 401. Если
+	// выключено, такие запросы работают с задачами без владельца.
+	AuthRequired bool
+	// OrphanTasksOwner — имя пользователя, которому при старте передаются
+	// задачи без владельца; пусто — задачи остаются без владельца.
+	OrphanTasksOwner string
+	Tokens           Tokens
+}
+
+// Tokens настраивает вход по паролю: подпись токенов доступа и сроки
+// жизни токенов.
+type Tokens struct {
+	// Algorithm — алгоритм подписи токенов доступа: HS256 или EdDSA.
+	Algorithm string
+	// Secret — ключ HS256 не короче 32 байт. Если он пуст, ключ
+	// генерируется при старте и выданные токены не переживут перезапуск.
+	Secret string
+	// PrivateKeyFile — PEM-файл с закрытым ключом Ed25519 для EdDSA.
+	PrivateKeyFile string
+	Issuer         string
+	AccessTTL      time.Duration
+	RefreshTTL     time.Duration
+	// CleanupInterval — как часто удаляются истёкшие токены обновления и
+	// записи об отозванных токенах доступа.
+	CleanupInterval time.Duration
+}
+
+type Archive struct {
+	// After — через сколько после закрытия задача уходит в архив;
+	// 0 отключает автоматическую архивацию.
+	After time.Duration
+	// Interval — как часто фоновая архивация проверяет задачи.
+	Interval time.Duration
+}
+
+type Trash struct {
+	// Retention — сколько задача лежит в корзине до окончательного удаления;
+	// 0 отключает автоматическую очистку.
+	Retention time.Duration
+	// PurgeInterval — как часто фоновая очистка проверяет корзину.
+	PurgeInterval time.Duration
+}
+
+type Storage struct {
+	// Driver выбирает хранилище задач: memory, file, events, postgres или sqlite.
+	Driver   string
+	File     FileStorage
+	Postgres PostgresStorage
+	SQLite   SQLiteStorage
+	// AutoMigrate применяет миграции SQL-хранилища при старте сервера.
+	AutoMigrate bool
+}
+
+type FileStorage struct {
+	Dir               string
+	Fsync             string
+	FsyncInterval     time.Duration
+	SnapshotInterval  time.Duration
+	SnapshotThreshold int
+}
+
+type PostgresStorage struct {
+	DSN string
+}
+
+type SQLiteStorage struct {
+	Path string
+}
+
+// Load читает конфигурацию из переменных окружения.
+func Load() (*Config, error) {
+	cfg := &Config{
+		HTTPAddr: getEnv("HTTP_ADDR", ":8080"),
+		Storage: Storage{
+			Driver: getEnv("STORAGE_DRIVER", "memory"),
+			File: FileStorage{
+				Dir:   getEnv("STORAGE_DIR", "data"),
+				Fsync: getEnv("STORAGE_FSYNC", "always"),
+			},
+			Postgres: PostgresStorage{
+				DSN: getEnv("POSTGRES_DSN", ""),
+			},
+			SQLite: SQLiteStorage{
+				Path: getEnv("SQLITE_PATH", "data/todo.db"),
+			},
+		},
+	}
+
+	var err error
+	if cfg.Storage.File.FsyncInterval, err = getDuration("STORAGE_FSYNC_INTERVAL", time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Storage.File.SnapshotInterval, err = getDuration("STORAGE_SNAPSHOT_INTERVAL", 5*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.Storage.File.SnapshotThreshold, err = getInt("STORAGE_SNAPSHOT_THRESHOLD", 10000); err != nil {
+		return nil, err
+	}
+
+	if cfg.Storage.AutoMigrate, err = getBool("STORAGE_AUTO_MIGRATE", true); err != nil {
+		return nil, err
+	}
+
+	if cfg.Trash.Retention, err = getDuration("TRASH_RETENTION", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Trash.PurgeInterval, err = getDuration("TRASH_PURGE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Trash.Retention < 0 || cfg.Trash.PurgeInterval <= 0 {
+		return nil, fmt.Errorf("TRASH_RETENTION must not be negative and TRASH_PURGE_INTERVAL must be positive")
+	}
+
+	if cfg.Archive.After, err = getDuration("ARCHIVE_AFTER", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Archive.Interval, err = getDuration("ARCHIVE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Archive.After < 0 || cfg.Archive.Interval <= 0 {
+		return nil, fmt.Errorf("ARCHIVE_AFTER must not be negative and ARCHIVE_INTERVAL must be positive")
+	}
+
+	if cfg.UndoDepth, err = getInt("UNDO_DEPTH", 20); err != nil {
+		return nil, err
+	}
+	if cfg.UndoDepth < 0 {
+		return nil, fmt.Errorf("UNDO_DEPTH must not be negative")
+	}
+
+	if cfg.IdempotencyTTL, err = getDuration("IDEMPOTENCY_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.IdempotencyTTL < 0 {
+		return nil, fmt.Errorf("IDEMPOTENCY_TTL must not be negative")
+	}
+
+	if cfg.AuthRequired, err = getBool("AUTH_REQUIRED", true); err != nil {
+		return nil, err
+	}
+	cfg.OrphanTasksOwner = getEnv("ORPHAN_TASKS_OWNER", "")
+
+	cfg.Tokens = Tokens{
+		Algorithm:      getEnv("JWT_ALGORITHM", "HS256"),
+		Secret:         getEnv("JWT_SECRET", ""),
+		PrivateKeyFile: getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		Issuer:         getEnv("JWT_ISSUER", "todo-list"),
+	}
+	if cfg.Tokens.AccessTTL, err = getDuration("JWT_ACCESS_TTL", 15*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.Tokens.RefreshTTL, err = getDuration("JWT_REFRESH_TTL", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Tokens.CleanupInterval, err = getDuration("TOKEN_CLEANUP_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Tokens.AccessTTL <= 0 || cfg.Tokens.RefreshTTL <= 0 || cfg.Tokens.CleanupInterval <= 0 {
+		return nil, fmt.Errorf("JWT_ACCESS_TTL, JWT_REFRESH_TTL and TOKEN_CLEANUP_INTERVAL must be positive")
+	}
+	switch cfg.Tokens.Algorithm {
+	case "HS256":
+		if cfg.Tokens.Secret != "" && len(cfg.Tokens.Secret) < 32 {
+			return nil, fmt.Errorf("JWT_SECRET must be at least 32 bytes long")
+		}
+	case "EdDSA":
+		if cfg.Tokens.PrivateKeyFile == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for JWT_ALGORITHM=EdDSA")
+		}
+	default:
+		return nil, fmt.Errorf("unknown JWT_ALGORITHM %q", cfg.Tokens.Algorithm)
+	}
+
+	switch cfg.Storage.Driver {
+	case "memory", "file", "events", "sqlite":
+	case "postgres":
+		if cfg.Storage.Postgres.DSN == "" {
+			return nil, fmt.Errorf("POSTGRES_DSN is required for STORAGE_DRIVER=postgres")
+		}
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", cfg.Storage.Driver)
+	}
+
+	return cfg, nil
+}
+
+func getEnv(key string, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v := getEnv(key, "")
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+func getInt(key string, fallback int) (int, error) {
+	v := getEnv(key, "")
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func getBool(key string, fallback bool) (bool, error) {
+	v := getEnv(key, "")
+	if v == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// APIKeyPrefix начинает каждый ключ API, чтобы ключ можно было узнать
+	// в конфигах и найти сканерами секретов.
+	APIKeyPrefix = "todo_"
+	// MaxAPIKeyNameLength — наибольшая длина названия ключа.
+	MaxAPIKeyNameLength = 100
+
+	apiKeyHint = 4
+
+	secretBytes = 32
+)
+
+// APIKey — ключ API пользователя. Сам ключ не хранится: по Hash его можно
+// проверить, а по Hint — узнать в списке ключей.
+type APIKey struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"user_id"`
+	Name   string `json:"name"`
+	// Hash — SHA-256 ключа в hex, см. HashAPIKey.
+	Hash       string     `json:"hash"`
+	Hint       string     `json:"hint"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k *APIKey) Clone() *APIKey {
+	clone := *k
+	clone.LastUsedAt = cloneTime(k.LastUsedAt)
+	clone.RevokedAt = cloneTime(k.RevokedAt)
+	return &clone
+}
+
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// NewAPIKey создаёт ключ пользователя userID и возвращает его вместе с
+// секретом — строкой, которую клиент передаёт в заголовке Authorization.
+// Секрет больше нигде не сохраняется.
+func NewAPIKey(userID int, name string, now time.Time) (*APIKey, string, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	secret = APIKeyPrefix + secret
+
+	key := &APIKey{
+		UserID:    userID,
+		Name:      name,
+		Hash:      HashAPIKey(secret),
+		Hint:      secret[:len(APIKeyPrefix)+apiKeyHint],
+		CreatedAt: now,
+	}
+	return key, secret, nil
+}
+
+// HashAPIKey возвращает хеш, под которым хранится ключ.
+func HashAPIKey(secret string) string {
+	return hashSecret(secret)
+}
+
+// IsAPIKey сообщает, похожа ли строка на ключ API.
+func IsAPIKey(secret string) bool {
+	return strings.HasPrefix(secret, APIKeyPrefix)
+}
+
+// NormalizeAPIKeyName обрезает пробелы и проверяет длину названия ключа.
+func NormalizeAPIKeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > MaxAPIKeyNameLength {
+		return "", fmt.Errorf("%w: key name is longer than %d characters", ErrInvalidRequest, MaxAPIKeyNameLength)
+	}
+	return name, nil
+}
+
+// randomSecret возвращает 256 случайных бит в base64url.
+func randomSecret() (string, error) {
+	buf := make([]byte, secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashSecret хеширует секрет из randomSecret для хранения. У секрета 256
+// бит случайности, поэтому медленная функция вроде Argon2 не нужна.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
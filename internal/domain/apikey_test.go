@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	key, secret, err := NewAPIKey(7, "ci", time.Now())
+	if err != nil {
+		t.Fatalf("new key: %v", err)
+	}
+	if !IsAPIKey(secret) || !strings.HasPrefix(secret, key.Hint) || len(key.Hint) >= len(secret) {
+		t.Errorf("secret %q, hint %q", secret, key.Hint)
+	}
+	if key.UserID != 7 || key.Hash != HashAPIKey(secret) || strings.Contains(key.Hash, secret) {
+		t.Errorf("key: %+v", key)
+	}
+
+	_, other, err := NewAPIKey(7, "ci", time.Now())
+	if err != nil || other == secret {
+		t.Errorf("second key %q, %v", other, err)
+	}
+}
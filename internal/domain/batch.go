@@ -0,0 +1,49 @@
+package domain
+
+// Операции пакетного запроса.
+const (
+	BatchCreate   = "create"
+	BatchUpdate   = "update"
+	BatchComplete = "complete"
+	BatchDelete   = "delete"
+)
+
+// BatchOp — одна операция пакетного запроса.
+type BatchOp struct {
+	Kind string
+	// ID — задача операции; для create не задаётся.
+	ID int
+	// Version — версия задачи, которую ожидает клиент; 0 — без проверки.
+	Version int
+	// Input — содержимое новой задачи (create).
+	Input TaskInput
+	// Patch возвращает новое содержимое задачи по текущему (update).
+	Patch    func(task *Task) (TaskInput, error)
+	Complete CompleteOptions
+	Delete   DeleteOptions
+}
+
+// BatchResult — итог операции пакета: задача после неё (после delete — nil)
+// или ошибка.
+type BatchResult struct {
+	TaskID int
+	Task   *Task
+	Err    error
+}
+
+// BulkRequest — операция над всеми задачами, подходящими под фильтр.
+type BulkRequest struct {
+	Filter TaskFilter
+	// Op применяется к каждой подходящей задаче; ID и Version не задаются.
+	Op     BatchOp
+	Atomic bool
+	// DryRun — только найти задачи, ничего не меняя.
+	DryRun bool
+}
+
+// BulkResult — задачи, подошедшие под фильтр, в порядке обработки и итоги
+// операции над ними; при DryRun итогов нет.
+type BulkResult struct {
+	IDs     []int
+	Results []BatchResult
+}
@@ -3,9 +3,31 @@ package domain
 import "errors"
 
 var (
-	ErrInvalidRequest     = errors.New("invalid request")
-	ErrNotFound           = errors.New("resource not found")
-	ErrInternalError      = errors.New("internal server error")
-	ErrFailedToDecodeJSON = errors.New("failed to decode JSON")
-	ErrMethodNotAllowed   = errors.New("method not allowed")
+	ErrInvalidRequest        = errors.New("invalid request")
+	ErrNotFound              = errors.New("resource not found")
+	ErrAlreadyExists         = errors.New("resource already exists")
+	ErrInternalError         = errors.New("internal server error")
+	ErrFailedToDecodeJSON    = errors.New("failed to decode JSON")
+	ErrMethodNotAllowed      = errors.New("method not allowed")
+	ErrInvalidCursor         = errors.New("invalid cursor")
+	ErrCycle                 = errors.New("task hierarchy cycle")
+	ErrHasSubtasks           = errors.New("task has subtasks")
+	ErrDependencyCycle       = errors.New("dependency cycle")
+	ErrBlocked               = errors.New("task has open blockers")
+	ErrInvalidTransition     = errors.New("invalid status transition")
+	ErrParentDeleted         = errors.New("parent task is in trash")
+	ErrNotClosed             = errors.New("task is not closed")
+	ErrNothingToUndo         = errors.New("nothing to undo")
+	ErrNothingToRedo         = errors.New("nothing to redo")
+	ErrUndoConflict          = errors.New("task was changed after the operation")
+	ErrAnonymousClient       = errors.New("client is not identified: authenticate or set X-Actor")
+	ErrVersionMismatch       = errors.New("task version mismatch")
+	ErrPatchConflict         = errors.New("patch cannot be applied")
+	ErrUnsupportedMedia      = errors.New("unsupported media type")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was used with a different request")
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is in progress")
+	ErrIdempotencyStoreFull  = errors.New("too many idempotency keys, retry later")
+	ErrBatchAborted          = errors.New("batch aborted")
+	ErrUnauthorized          = errors.New("unauthorized")
+	ErrTokenReused           = errors.New("refresh token was already used")
 )
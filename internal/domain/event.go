@@ -0,0 +1,291 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// EventType — вид доменного события в журнале задач.
+type EventType string
+
+const (
+	EventTaskCreated        EventType = "task_created"
+	EventTaskContentUpdated EventType = "task_content_updated"
+	EventTaskStatusChanged  EventType = "task_status_changed"
+	EventTaskCompleted      EventType = "task_completed"
+	EventTaskArchived       EventType = "task_archived"
+	EventTaskUnarchived     EventType = "task_unarchived"
+	// EventTaskDeleted — задача перемещена в корзину, EventTaskRestored —
+	// возвращена из неё, EventTaskPurged — удалена навсегда.
+	EventTaskDeleted  EventType = "task_deleted"
+	EventTaskRestored EventType = "task_restored"
+	EventTaskPurged   EventType = "task_purged"
+	// EventTaskReverted — содержимое задачи возвращено к одной из ревизий.
+	EventTaskReverted EventType = "task_reverted"
+	// EventTaskClaimed — задаче без владельца назначен пользователь.
+	EventTaskClaimed EventType = "task_claimed"
+	// EventTaskUpdated — изменение, вид которого хранилищу не сообщили.
+	EventTaskUpdated EventType = "task_updated"
+	// EventRevisionRecorded — в историю задачи записана ревизия без
+	// изменения самой задачи.
+	EventRevisionRecorded EventType = "revision_recorded"
+)
+
+// eventActions — действие ревизии, которую записывает событие.
+var eventActions = map[EventType]string{
+	EventTaskCreated:        ActionCreate,
+	EventTaskContentUpdated: ActionUpdate,
+	EventTaskStatusChanged:  ActionTransition,
+	EventTaskCompleted:      ActionTransition,
+	EventTaskArchived:       ActionArchive,
+	EventTaskUnarchived:     ActionUnarchive,
+	EventTaskDeleted:        ActionDelete,
+	EventTaskRestored:       ActionRestore,
+	EventTaskReverted:       ActionRevert,
+	EventTaskClaimed:        ActionClaim,
+	EventTaskUpdated:        ActionUpdate,
+}
+
+// Action возвращает действие ревизии, которую записывает событие.
+func (t EventType) Action() string {
+	return eventActions[t]
+}
+
+// StatusEvent возвращает событие перехода задачи в статус to.
+func StatusEvent(to Status) EventType {
+	if to == StatusDone {
+		return EventTaskCompleted
+	}
+	return EventTaskStatusChanged
+}
+
+// Event — запись журнала событий. Текущее состояние задач не хранится
+// отдельно, а получается проигрыванием событий по порядку, см. Projection.
+type Event struct {
+	Seq    uint64    `json:"seq"`
+	Type   EventType `json:"type"`
+	TaskID int       `json:"task_id"`
+	Actor  string    `json:"actor"`
+	At     time.Time `json:"at"`
+	// Version — версия задачи после события.
+	Version int `json:"version,omitempty"`
+	// Task — новая задача для EventTaskCreated.
+	Task *Task `json:"task,omitempty"`
+	// Change — поля, которые поменяло событие изменения задачи.
+	Change *TaskChange `json:"change,omitempty"`
+	// Revision — ревизия, записанная вместе с изменением. Snapshot в журнал
+	// не попадает: проекция берёт его из состояния задачи после события.
+	Revision *Revision `json:"revision,omitempty"`
+}
+
+// TaskChange — изменённые поля задачи по группам; группа, в которой ничего
+// не поменялось, пуста.
+type TaskChange struct {
+	// OwnerID — новый владелец задачи.
+	OwnerID  *int         `json:"owner_id,omitempty"`
+	Content  *TaskContent `json:"content,omitempty"`
+	Status   *TaskStatus  `json:"status,omitempty"`
+	Archived *Timestamp   `json:"archived,omitempty"`
+	Deleted  *Timestamp   `json:"deleted,omitempty"`
+}
+
+// TaskContent — содержимое задачи: поля, которые задаёт клиент, и
+// выведенные из правила повторения.
+type TaskContent struct {
+	Headline    string     `json:"headline"`
+	Description string     `json:"description"`
+	Priority    Priority   `json:"priority"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	DueTimezone string     `json:"due_timezone,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    *int       `json:"parent_id,omitempty"`
+	BlockedBy   []int      `json:"blocked_by,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	Occurrence  int        `json:"occurrence,omitempty"`
+	SeriesID    int        `json:"series_id,omitempty"`
+}
+
+// TaskStatus — статус задачи и время переходов.
+type TaskStatus struct {
+	Status          Status     `json:"status,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty"`
+	Done            bool       `json:"done"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// Timestamp — новое значение поля-отметки времени; At равен nil, если
+// отметку сняли.
+type Timestamp struct {
+	At *time.Time `json:"at"`
+}
+
+// NewTaskChange возвращает группы полей, которыми after отличается от before.
+func NewTaskChange(before *Task, after *Task) *TaskChange {
+	c := &TaskChange{}
+	if before.OwnerID != after.OwnerID {
+		c.OwnerID = &after.OwnerID
+	}
+	if content := contentOf(after); !sameJSON(contentOf(before), content) {
+		c.Content = &content
+	}
+	if status := statusOf(after); !sameJSON(statusOf(before), status) {
+		c.Status = &status
+	}
+	if !sameJSON(before.ArchivedAt, after.ArchivedAt) {
+		c.Archived = &Timestamp{At: cloneTime(after.ArchivedAt)}
+	}
+	if !sameJSON(before.DeletedAt, after.DeletedAt) {
+		c.Deleted = &Timestamp{At: cloneTime(after.DeletedAt)}
+	}
+	return c
+}
+
+// ApplyTo переносит изменённые поля в задачу.
+func (c *TaskChange) ApplyTo(t *Task) {
+	if c.OwnerID != nil {
+		t.OwnerID = *c.OwnerID
+	}
+	if c.Content != nil {
+		t.Headline = c.Content.Headline
+		t.Description = c.Content.Description
+		t.Priority = c.Content.Priority
+		t.DueAt = cloneTime(c.Content.DueAt)
+		t.DueTimezone = c.Content.DueTimezone
+		t.Tags = slices.Clone(c.Content.Tags)
+		t.ParentID = cloneInt(c.Content.ParentID)
+		t.BlockedBy = slices.Clone(c.Content.BlockedBy)
+		t.Recurrence = c.Content.Recurrence
+		t.Occurrence = c.Content.Occurrence
+		t.SeriesID = c.Content.SeriesID
+	}
+	if c.Status != nil {
+		t.Status = c.Status.Status
+		t.StatusChangedAt = cloneTime(c.Status.StatusChangedAt)
+		t.StartedAt = cloneTime(c.Status.StartedAt)
+		t.CancelledAt = cloneTime(c.Status.CancelledAt)
+		t.Done = c.Status.Done
+		t.CompletedAt = cloneTime(c.Status.CompletedAt)
+	}
+	if c.Archived != nil {
+		t.ArchivedAt = cloneTime(c.Archived.At)
+	}
+	if c.Deleted != nil {
+		t.DeletedAt = cloneTime(c.Deleted.At)
+	}
+}
+
+func contentOf(t *Task) TaskContent {
+	return TaskContent{
+		Headline:    t.Headline,
+		Description: t.Description,
+		Priority:    t.Priority,
+		DueAt:       cloneTime(t.DueAt),
+		DueTimezone: t.DueTimezone,
+		Tags:        slices.Clone(t.Tags),
+		ParentID:    cloneInt(t.ParentID),
+		BlockedBy:   slices.Clone(t.BlockedBy),
+		Recurrence:  t.Recurrence,
+		Occurrence:  t.Occurrence,
+		SeriesID:    t.SeriesID,
+	}
+}
+
+func statusOf(t *Task) TaskStatus {
+	return TaskStatus{
+		Status:          t.Status,
+		StatusChangedAt: cloneTime(t.StatusChangedAt),
+		StartedAt:       cloneTime(t.StartedAt),
+		CancelledAt:     cloneTime(t.CancelledAt),
+		Done:            t.Done,
+		CompletedAt:     cloneTime(t.CompletedAt),
+	}
+}
+
+func sameJSON(a any, b any) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
+
+// Projection — состояние задач и их истории, восстановленное из событий.
+type Projection struct {
+	// Seq — номер последнего применённого события.
+	Seq       uint64
+	Tasks     map[int]*Task
+	Revisions map[int][]*Revision
+}
+
+func NewProjection() *Projection {
+	return &Projection{
+		Tasks:     make(map[int]*Task),
+		Revisions: make(map[int][]*Revision),
+	}
+}
+
+// Apply применяет событие к состоянию. События должны идти по порядку Seq.
+// Задачи в Tasks не меняются на месте: изменённая задача заменяется новой.
+func (p *Projection) Apply(e *Event) error {
+	if e.Seq <= p.Seq {
+		return fmt.Errorf("event %d: out of order after %d", e.Seq, p.Seq)
+	}
+
+	switch e.Type {
+	case EventTaskCreated:
+		if e.Task == nil {
+			return fmt.Errorf("event %d: %s without task", e.Seq, e.Type)
+		}
+		p.Tasks[e.TaskID] = e.Task.Clone()
+	case EventTaskContentUpdated, EventTaskStatusChanged, EventTaskCompleted, EventTaskArchived,
+		EventTaskUnarchived, EventTaskDeleted, EventTaskRestored, EventTaskReverted, EventTaskClaimed, EventTaskUpdated:
+		current, ok := p.Tasks[e.TaskID]
+		switch {
+		case e.Change != nil && ok:
+			task := current.Clone()
+			e.Change.ApplyTo(task)
+			task.Version = e.Version
+			p.Tasks[e.TaskID] = task
+		case e.Change != nil:
+			return fmt.Errorf("event %d: %s of unknown task %d", e.Seq, e.Type, e.TaskID)
+		default:
+			return fmt.Errorf("event %d: %s without change", e.Seq, e.Type)
+		}
+	case EventTaskPurged:
+		delete(p.Tasks, e.TaskID)
+		delete(p.Revisions, e.TaskID)
+	case EventRevisionRecorded:
+		if e.Revision == nil {
+			return fmt.Errorf("event %d: %s without revision", e.Seq, e.Type)
+		}
+	default:
+		return fmt.Errorf("event %d: unknown type %q", e.Seq, e.Type)
+	}
+
+	if e.Revision != nil {
+		rev := e.Revision.Clone()
+		if rev.Snapshot == nil {
+			task, ok := p.Tasks[e.TaskID]
+			if !ok {
+				return fmt.Errorf("event %d: revision of unknown task %d", e.Seq, e.TaskID)
+			}
+			rev.Snapshot = task.Clone()
+		}
+		p.Revisions[e.TaskID] = append(p.Revisions[e.TaskID], rev)
+	}
+	p.Seq = e.Seq
+	return nil
+}
+
+// SortedTasks возвращает задачи проекции по возрастанию ID.
+func (p *Projection) SortedTasks() []*Task {
+	tasks := make([]*Task, 0, len(p.Tasks))
+	for _, task := range p.Tasks {
+		tasks = append(tasks, task)
+	}
+	slices.SortFunc(tasks, func(a, b *Task) int { return a.ID - b.ID })
+	return tasks
+}
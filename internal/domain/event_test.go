@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskChange(t *testing.T) {
+	now := time.Now()
+	before := NewTask(1, "Task", "Description")
+	if err := before.Transition(StatusDone, now); err != nil {
+		t.Fatal(err)
+	}
+	before.ArchivedAt = &now
+	before.BlockedBy = []int{2, 3}
+
+	// Переоткрытие задачи меняет статус и достаёт её из архива
+	after := before.Clone()
+	if err := after.Transition(StatusInProgress, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	after.BlockedBy = []int{3}
+
+	change := NewTaskChange(before, after)
+	if change.Content == nil || change.Status == nil || change.Archived == nil || change.Deleted != nil {
+		t.Fatalf("change: %+v", change)
+	}
+	got := before.Clone()
+	change.ApplyTo(got)
+	if diff := Diff(after, got); len(diff) != 0 || !sameJSON(after, got) {
+		t.Errorf("applied change differs: %v", diff)
+	}
+
+	if change := NewTaskChange(before, before.Clone()); *change != (TaskChange{}) {
+		t.Errorf("no-op change: %+v", change)
+	}
+}
+
+func TestProjectionAppliesChanges(t *testing.T) {
+	p := NewProjection()
+	task := NewTask(1, "Task", "Description")
+	task.Version = 1
+	if err := p.Apply(&Event{Seq: 1, Type: EventTaskCreated, TaskID: 1, Task: task,
+		Revision: &Revision{TaskID: 1, Number: 1, Action: ActionCreate}}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	updated := task.Clone()
+	updated.Headline = "Renamed"
+	e := &Event{Seq: 2, Type: EventTaskContentUpdated, TaskID: 1, Version: 2, Change: NewTaskChange(task, updated),
+		Revision: &Revision{TaskID: 1, Number: 2, Action: ActionUpdate}}
+	if err := p.Apply(e); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := p.Tasks[1]; got.Headline != "Renamed" || got.Version != 2 || task.Headline != "Task" {
+		t.Errorf("task: %+v", got)
+	}
+	revisions := p.Revisions[1]
+	if len(revisions) != 2 || revisions[1].Snapshot == nil || revisions[1].Snapshot.Headline != "Renamed" {
+		t.Errorf("revisions: %+v", revisions)
+	}
+
+	if err := p.Apply(&Event{Seq: 3, Type: EventTaskArchived, TaskID: 2, Change: &TaskChange{}}); err == nil {
+		t.Error("change of unknown task applied")
+	}
+	if err := p.Apply(&Event{Seq: 3, Type: EventTaskArchived, TaskID: 1}); err == nil {
+		t.Error("event without change applied")
+	}
+}
+
+func TestProjectionRejectsOutOfOrderEvents(t *testing.T) {
+	p := NewProjection()
+	task := NewTask(1, "Task", "Description")
+	if err := p.Apply(&Event{Seq: 1, Type: EventTaskCreated, TaskID: 1, Task: task}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if err := p.Apply(&Event{Seq: 1, Type: EventTaskPurged, TaskID: 1}); err == nil {
+		t.Error("replayed event applied twice")
+	}
+	if err := p.Apply(&Event{Seq: 2, Type: "task_renamed", TaskID: 1}); err == nil {
+		t.Error("unknown event type applied")
+	}
+	if err := p.Apply(&Event{Seq: 2, Type: EventTaskPurged, TaskID: 1}); err != nil || len(p.Tasks) != 0 {
+		t.Errorf("purge: %v %d", err, len(p.Tasks))
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "fmt"
+
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// ParsePriority разбирает приоритет; пустая строка означает normal.
+func ParsePriority(s string) (Priority, error) {
+	switch p := Priority(s); p {
+	case "":
+		return PriorityNormal, nil
+	case PriorityLow, PriorityNormal, PriorityHigh, PriorityUrgent:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w: unknown priority %q", ErrInvalidRequest, s)
+	}
+}
+
+// Rank задаёт порядок приоритетов: чем больше, тем важнее. Задачи,
+// созданные до появления приоритетов, считаются normal.
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityLow:
+		return 0
+	case PriorityHigh:
+		return 2
+	case PriorityUrgent:
+		return 3
+	default:
+		return 1
+	}
+}
+
+func (p Priority) OrDefault() Priority {
+	if p == "" {
+		return PriorityNormal
+	}
+	return p
+}
@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type TaskFilter struct {
+	Done             *bool
+	Statuses         []Status
+	CreatedAfter     *time.Time
+	CreatedBefore    *time.Time
+	CompletedAfter   *time.Time
+	CompletedBefore  *time.Time
+	HeadlineContains string
+	Priorities       []Priority
+	DueBefore        *time.Time
+	Overdue          *bool
+	// Tags — условия по тегам, задача должна подходить под все.
+	Tags []TagClause
+	// Archived — nil означает задачи и в архиве, и вне его.
+	Archived *bool
+	// Blocked сравнивается с Task.IsBlocked, поэтому OpenBlockers задач
+	// должны быть заполнены заранее.
+	Blocked *bool
+	// Now — момент, относительно которого считается просрочка; нулевое
+	// значение означает текущее время.
+	Now time.Time
+}
+
+func (f TaskFilter) Match(t *Task) bool {
+	if f.Done != nil && t.Done != *f.Done {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, t.CurrentStatus()) {
+		return false
+	}
+	if f.CreatedAfter != nil && !t.CreatedAt.After(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !t.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	if f.CompletedAfter != nil && (t.CompletedAt == nil || !t.CompletedAt.After(*f.CompletedAfter)) {
+		return false
+	}
+	if f.CompletedBefore != nil && (t.CompletedAt == nil || !t.CompletedAt.Before(*f.CompletedBefore)) {
+		return false
+	}
+	if f.HeadlineContains != "" && !strings.Contains(strings.ToLower(t.Headline), strings.ToLower(f.HeadlineContains)) {
+		return false
+	}
+	if len(f.Priorities) > 0 && !slices.Contains(f.Priorities, t.Priority.OrDefault()) {
+		return false
+	}
+	if f.DueBefore != nil && (t.DueAt == nil || !t.DueAt.Before(*f.DueBefore)) {
+		return false
+	}
+	if f.Overdue != nil {
+		now := f.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		if t.IsOverdue(now) != *f.Overdue {
+			return false
+		}
+	}
+	if f.Archived != nil && t.IsArchived() != *f.Archived {
+		return false
+	}
+	if f.Blocked != nil && t.IsBlocked() != *f.Blocked {
+		return false
+	}
+	for _, clause := range f.Tags {
+		if !clause.Match(t) {
+			return false
+		}
+	}
+	return true
+}
+
+type SortField string
+
+const (
+	SortByID          SortField = "id"
+	SortByHeadline    SortField = "headline"
+	SortByDone        SortField = "done"
+	SortByCreatedAt   SortField = "created_at"
+	SortByCompletedAt SortField = "completed_at"
+	SortByPriority    SortField = "priority"
+	SortByDueAt       SortField = "due_at"
+)
+
+var sortFields = map[SortField]bool{
+	SortByID:          true,
+	SortByHeadline:    true,
+	SortByDone:        true,
+	SortByCreatedAt:   true,
+	SortByCompletedAt: true,
+	SortByPriority:    true,
+	SortByDueAt:       true,
+}
+
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+func (k SortKey) String() string {
+	if k.Desc {
+		return "-" + string(k.Field)
+	}
+	return string(k.Field)
+}
+
+// DefaultSort — порядок списка, если клиент не указал свой.
+var DefaultSort = []SortKey{{Field: SortByCreatedAt}}
+
+// ParseSort разбирает строку вида "-created_at,headline": минус означает
+// сортировку по убыванию.
+func ParseSort(s string) ([]SortKey, error) {
+	if s == "" {
+		return DefaultSort, nil
+	}
+
+	var keys []SortKey
+	seen := make(map[SortField]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		key := SortKey{Field: SortField(strings.TrimPrefix(part, "-")), Desc: strings.HasPrefix(part, "-")}
+		if !sortFields[key.Field] {
+			return nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidRequest, key.Field)
+		}
+		if seen[key.Field] {
+			return nil, fmt.Errorf("%w: duplicate sort field %q", ErrInvalidRequest, key.Field)
+		}
+		seen[key.Field] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func FormatSort(keys []SortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// CompareTasks сравнивает задачи по ключам сортировки. При равенстве всех
+// ключей задачи упорядочиваются по ID, поэтому порядок всегда однозначен.
+// Пустые значения (например, completed_at у невыполненной задачи) всегда
+// оказываются в конце, независимо от направления.
+func CompareTasks(a *Task, b *Task, keys []SortKey) int {
+	for _, key := range keys {
+		c, nullOrder := compareField(a, b, key.Field)
+		if c == 0 {
+			continue
+		}
+		if key.Desc && !nullOrder {
+			return -c
+		}
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// compareField возвращает результат сравнения и признак того, что он
+// определяется пустым значением и не зависит от направления сортировки.
+func compareField(a *Task, b *Task, field SortField) (int, bool) {
+	switch field {
+	case SortByID:
+		return cmp.Compare(a.ID, b.ID), false
+	case SortByHeadline:
+		if c := strings.Compare(strings.ToLower(a.Headline), strings.ToLower(b.Headline)); c != 0 {
+			return c, false
+		}
+		return strings.Compare(a.Headline, b.Headline), false
+	case SortByDone:
+		return compareBool(a.Done, b.Done), false
+	case SortByCreatedAt:
+		return a.CreatedAt.Compare(b.CreatedAt), false
+	case SortByCompletedAt:
+		return compareOptionalTime(a.CompletedAt, b.CompletedAt)
+	case SortByPriority:
+		return cmp.Compare(a.Priority.Rank(), b.Priority.Rank()), false
+	case SortByDueAt:
+		return compareOptionalTime(a.DueAt, b.DueAt)
+	}
+	return 0, false
+}
+
+func compareBool(a bool, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func compareOptionalTime(a *time.Time, b *time.Time) (int, bool) {
+	switch {
+	case a == nil && b == nil:
+		return 0, false
+	case a == nil:
+		return 1, true
+	case b == nil:
+		return -1, true
+	default:
+		return a.Compare(*b), false
+	}
+}
+
+type TaskQuery struct {
+	Filter TaskFilter
+	Sort   []SortKey
+	Limit  int
+	Cursor string
+}
+
+type TaskPage struct {
+	Tasks      []*Task
+	Total      int
+	Limit      int
+	NextCursor string
+}
+
+type SearchResult struct {
+	Task  *Task
+	Score float64
+}
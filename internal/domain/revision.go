@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// Действия, после которых записывается ревизия задачи.
+const (
+	ActionCreate     = "create"
+	ActionUpdate     = "update"
+	ActionTransition = "transition"
+	ActionArchive    = "archive"
+	ActionUnarchive  = "unarchive"
+	ActionDelete     = "delete"
+	ActionRestore    = "restore"
+	ActionRevert     = "revert"
+	// ActionClaim — задаче без владельца назначен пользователь.
+	ActionClaim = "claim"
+)
+
+// Revision — одно изменение задачи: кто, когда и какие поля поменял.
+type Revision struct {
+	TaskID int    `json:"task_id"`
+	Number int    `json:"number"`
+	Actor  string `json:"actor"`
+	Action string `json:"action"`
+	// RevertOf — номер ревизии, к которой вернули задачу, для ActionRevert.
+	RevertOf int           `json:"revert_of,omitempty"`
+	At       time.Time     `json:"at"`
+	Changes  []FieldChange `json:"changes"`
+	// Snapshot — задача сразу после изменения.
+	Snapshot *Task `json:"snapshot"`
+}
+
+func (r *Revision) Clone() *Revision {
+	clone := *r
+	clone.Changes = slices.Clone(r.Changes)
+	if r.Snapshot != nil {
+		clone.Snapshot = r.Snapshot.Clone()
+	}
+	return &clone
+}
+
+// FieldChange — старое и новое значение поля в JSON. From равен null для
+// только что созданной задачи.
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// trackedFields — поля задачи, изменения которых попадают в историю.
+// Время переходов между статусами выводится из статуса и не отслеживается.
+var trackedFields = []struct {
+	name  string
+	value func(t *Task) any
+}{
+	{"owner_id", func(t *Task) any { return ownerField(t.OwnerID) }},
+	{"headline", func(t *Task) any { return t.Headline }},
+	{"description", func(t *Task) any { return t.Description }},
+	{"priority", func(t *Task) any { return t.Priority.OrDefault() }},
+	{"due_at", func(t *Task) any { return t.DueAt }},
+	{"due_timezone", func(t *Task) any { return t.DueTimezone }},
+	{"tags", func(t *Task) any { return t.Tags }},
+	{"parent_id", func(t *Task) any { return t.ParentID }},
+	{"blocked_by", func(t *Task) any { return t.BlockedBy }},
+	{"recurrence", func(t *Task) any { return t.Recurrence }},
+	{"status", func(t *Task) any { return t.CurrentStatus() }},
+	{"archived_at", func(t *Task) any { return t.ArchivedAt }},
+	{"deleted_at", func(t *Task) any { return t.DeletedAt }},
+}
+
+// Diff возвращает поля, которые отличаются у before и after. before равен
+// nil для новой задачи: тогда в изменения попадают все непустые поля.
+func Diff(before *Task, after *Task) []FieldChange {
+	var changes []FieldChange
+	for _, f := range trackedFields {
+		to := marshalField(f.value(after))
+		from := json.RawMessage("null")
+		if before != nil {
+			from = marshalField(f.value(before))
+		}
+		if !bytes.Equal(from, to) {
+			changes = append(changes, FieldChange{Field: f.name, From: from, To: to})
+		}
+	}
+	return changes
+}
+
+// ownerField не даёт задаче без владельца записать owner_id: 0 в первую
+// ревизию.
+func ownerField(id int) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func marshalField(v any) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == `""` || string(b) == "[]" {
+		return json.RawMessage("null")
+	}
+	return b
+}
+
+type actorKey struct{}
+
+// AnonymousActor записывается в ревизии, если автор изменения неизвестен.
+const AnonymousActor = "anonymous"
+
+// WithActor сохраняет в контексте автора изменений.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom возвращает автора изменений из контекста или AnonymousActor.
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
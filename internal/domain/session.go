@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Способы, которыми запрос подтвердил пользователя.
+const (
+	AuthAPIKey = "api_key"
+	AuthJWT    = "jwt"
+)
+
+// Identity — пользователь запроса и то, чем он подтвердил личность.
+type Identity struct {
+	User   *User
+	Method string
+	// TokenID и ExpiresAt — jti и срок действия токена доступа; есть
+	// только у входа по JWT.
+	TokenID   string
+	ExpiresAt time.Time
+}
+
+type identityKey struct{}
+
+// WithIdentity сохраняет в контексте пользователя запроса вместе со
+// способом входа, см. WithUser.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	ctx = WithUser(ctx, identity.User)
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+func IdentityFrom(ctx context.Context) (identity *Identity, ok bool) {
+	identity, ok = ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// RefreshToken — токен обновления, по которому клиент получает новый
+// токен доступа. Токен одноразовый: при обновлении выдаётся следующий
+// токен того же семейства FamilyID, а повторное предъявление
+// использованного токена отзывает всё семейство — так украденный токен
+// перестаёт работать и у вора, и у владельца.
+type RefreshToken struct {
+	ID       int `json:"id"`
+	UserID   int `json:"user_id"`
+	FamilyID int `json:"family_id"`
+	// Hash — SHA-256 токена в hex; сам токен не хранится.
+	Hash      string     `json:"hash"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (t *RefreshToken) Clone() *RefreshToken {
+	clone := *t
+	clone.UsedAt = cloneTime(t.UsedAt)
+	clone.RevokedAt = cloneTime(t.RevokedAt)
+	return &clone
+}
+
+// Valid сообщает, можно ли обменять токен на новый в момент now.
+func (t *RefreshToken) Valid(now time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// NewRefreshToken создаёт токен обновления семейства familyID и
+// возвращает его вместе с секретом для клиента.
+func NewRefreshToken(userID int, familyID int, now time.Time, ttl time.Duration) (*RefreshToken, string, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	token := &RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		Hash:      HashRefreshToken(secret),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	return token, secret, nil
+}
+
+// HashRefreshToken возвращает хеш, под которым хранится токен обновления.
+func HashRefreshToken(secret string) string {
+	return hashSecret(secret)
+}
+
+// RevokedToken — отозванный до истечения срока токен доступа. Запись
+// нужна только до ExpiresAt: после него токен не примут и так.
+type RevokedToken struct {
+	ID        string    `json:"id"`
+	UserID    int       `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (t *RevokedToken) Clone() *RevokedToken {
+	clone := *t
+	return &clone
+}
+
+// Session — выданная при входе пара токенов.
+type Session struct {
+	User             *User
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusTodo       Status = "todo"
+	StatusInProgress Status = "in_progress"
+	StatusBlocked    Status = "blocked"
+	StatusDone       Status = "done"
+	StatusCancelled  Status = "cancelled"
+)
+
+// transitions — разрешённые переходы между статусами. Выполненную задачу
+// можно переоткрыть или вернуть в работу, отменённую — только переоткрыть.
+var transitions = map[Status][]Status{
+	StatusTodo:       {StatusInProgress, StatusBlocked, StatusDone, StatusCancelled},
+	StatusInProgress: {StatusTodo, StatusBlocked, StatusDone, StatusCancelled},
+	StatusBlocked:    {StatusTodo, StatusInProgress, StatusDone, StatusCancelled},
+	StatusDone:       {StatusTodo, StatusInProgress},
+	StatusCancelled:  {StatusTodo},
+}
+
+func ParseStatus(s string) (Status, error) {
+	status := Status(s)
+	if _, ok := transitions[status]; !ok {
+		return "", fmt.Errorf("%w: unknown status %q", ErrInvalidRequest, s)
+	}
+	return status, nil
+}
+
+// CanTransition сообщает, разрешён ли переход из s в to.
+func (s Status) CanTransition(to Status) bool {
+	return slices.Contains(transitions[s], to)
+}
+
+// IsClosed сообщает, что работа над задачей закончена: она выполнена или отменена.
+func (s Status) IsClosed() bool {
+	return s == StatusDone || s == StatusCancelled
+}
+
+// CurrentStatus возвращает статус задачи. Задачи, сохранённые до появления
+// статусов, считаются todo или done в зависимости от Done.
+func (t *Task) CurrentStatus() Status {
+	if t.Done {
+		return StatusDone
+	}
+	if t.Status == "" || t.Status == StatusDone {
+		return StatusTodo
+	}
+	return t.Status
+}
+
+// RestoreStatus возвращает задаче статус, время переходов и архив из prev —
+// её состояния до перехода. Нужен для отмены перехода, который правилами
+// transitions обратно не выполнить (например, done → blocked).
+func (t *Task) RestoreStatus(prev *Task) {
+	t.Status = prev.Status
+	t.StatusChangedAt = cloneTime(prev.StatusChangedAt)
+	t.StartedAt = cloneTime(prev.StartedAt)
+	t.CancelledAt = cloneTime(prev.CancelledAt)
+	t.Done = prev.Done
+	t.CompletedAt = cloneTime(prev.CompletedAt)
+	t.ArchivedAt = cloneTime(prev.ArchivedAt)
+}
+
+// Transition переводит задачу в статус to и обновляет время переходов.
+// Done и CompletedAt выводятся из статуса, переоткрытая задача покидает
+// архив. Вернуть ошибку может только запрещённый переход.
+func (t *Task) Transition(to Status, now time.Time) error {
+	from := t.CurrentStatus()
+	if !from.CanTransition(to) {
+		return fmt.Errorf("%w: %s → %s", ErrInvalidTransition, from, to)
+	}
+
+	t.Status = to
+	t.StatusChangedAt = &now
+	t.Done = to == StatusDone
+	t.CompletedAt = nil
+	t.CancelledAt = nil
+	if !to.IsClosed() {
+		t.ArchivedAt = nil
+	}
+	switch to {
+	case StatusInProgress:
+		if t.StartedAt == nil {
+			t.StartedAt = &now
+		}
+	case StatusDone:
+		t.CompletedAt = &now
+	case StatusCancelled:
+		t.CancelledAt = &now
+	}
+	return nil
+}
+
+// ClosedAt возвращает время, когда задачу выполнили или отменили, и false
+// для незакрытой задачи.
+func (t *Task) ClosedAt() (time.Time, bool) {
+	if !t.CurrentStatus().IsClosed() {
+		return time.Time{}, false
+	}
+	switch {
+	case t.StatusChangedAt != nil:
+		return *t.StatusChangedAt, true
+	case t.CompletedAt != nil:
+		return *t.CompletedAt, true
+	default:
+		return t.CreatedAt, true
+	}
+}
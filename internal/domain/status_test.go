@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskTransition(t *testing.T) {
+	task := NewTask(1, "H", "D")
+	now := time.Now()
+
+	if err := task.Transition(StatusDone, now); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if !task.Done || task.CompletedAt == nil || !task.StatusChangedAt.Equal(now) {
+		t.Errorf("done: %+v", task)
+	}
+
+	if err := task.Transition(StatusCancelled, now); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("done → cancelled: %v", err)
+	}
+	if task.CurrentStatus() != StatusDone {
+		t.Errorf("rejected transition changed status to %s", task.CurrentStatus())
+	}
+
+	if err := task.Transition(StatusInProgress, now); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if task.Done || task.CompletedAt != nil || task.StartedAt == nil {
+		t.Errorf("reopened: %+v", task)
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	if s, err := ParseStatus("in_progress"); err != nil || s != StatusInProgress {
+		t.Errorf("in_progress: %s %v", s, err)
+	}
+	for _, s := range []string{"", "DONE", "finished"} {
+		if _, err := ParseStatus(s); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("%q: want ErrInvalidRequest, got %v", s, err)
+		}
+	}
+}
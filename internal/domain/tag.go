@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	MaxTagLength   = 64
+	MaxTagsPerTask = 32
+)
+
+// TagCount — тег и число задач, у которых он стоит.
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// NormalizeTag приводит тег к каноническому виду: без пробелов по краям и в
+// нижнем регистре. Тег состоит из букв, цифр и символов "-", "_", ".", "/"
+// и не может начинаться с "-", чтобы не путаться с отрицанием в запросах.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", fmt.Errorf("%w: empty tag", ErrInvalidRequest)
+	}
+	if len([]rune(tag)) > MaxTagLength {
+		return "", fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidRequest, tag, MaxTagLength)
+	}
+	if strings.HasPrefix(tag, "-") {
+		return "", fmt.Errorf("%w: tag %q must not start with \"-\"", ErrInvalidRequest, tag)
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_./", r) {
+			return "", fmt.Errorf("%w: tag %q contains invalid character %q", ErrInvalidRequest, tag, r)
+		}
+	}
+	return tag, nil
+}
+
+// NormalizeTags нормализует теги, убирает повторы и сортирует их.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+
+	if len(normalized) > MaxTagsPerTask {
+		return nil, fmt.Errorf("%w: a task can have at most %d tags", ErrInvalidRequest, MaxTagsPerTask)
+	}
+	return normalized, nil
+}
+
+// HasTag сообщает, стоит ли у задачи тег. Теги задачи хранятся
+// отсортированными, поэтому поиск двоичный.
+func (t *Task) HasTag(tag string) bool {
+	_, found := slices.BinarySearch(t.Tags, tag)
+	return found
+}
+
+// TagClause — одно условие запроса по тегам: у задачи есть хотя бы один
+// из тегов AnyOf, а при Negate — нет ни одного из них.
+type TagClause struct {
+	AnyOf  []string
+	Negate bool
+}
+
+func (c TagClause) Match(t *Task) bool {
+	has := slices.ContainsFunc(c.AnyOf, t.HasTag)
+	return has != c.Negate
+}
+
+// ParseTagClause разбирает условие вида "backend", "backend,frontend"
+// (любой из тегов) или "-blocked" (тега нет).
+func ParseTagClause(s string) (TagClause, error) {
+	var clause TagClause
+	if rest, ok := strings.CutPrefix(strings.TrimSpace(s), "-"); ok {
+		clause.Negate = true
+		s = rest
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		tag, err := NormalizeTag(part)
+		if err != nil {
+			return clause, err
+		}
+		clause.AnyOf = append(clause.AnyOf, tag)
+	}
+	return clause, nil
+}
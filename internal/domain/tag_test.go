@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" Backend", "api/v2", "backend", "срочно"})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if !slices.Equal(tags, []string{"api/v2", "backend", "срочно"}) {
+		t.Errorf("tags: %v", tags)
+	}
+
+	for _, tag := range []string{"", "  ", "-blocked", "two words", "a,b", "tag!"} {
+		if _, err := NormalizeTag(tag); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("%q: want ErrInvalidRequest, got %v", tag, err)
+		}
+	}
+}
+
+func TestTagClauseMatch(t *testing.T) {
+	task := NewTask(1, "H", "D")
+	task.Tags = []string{"backend", "blocked"}
+
+	cases := []struct {
+		clause string
+		want   bool
+	}{
+		{"backend", true},
+		{"frontend", false},
+		{"frontend,backend", true},
+		{"-blocked", false},
+		{"-frontend", true},
+		{"-frontend,blocked", false},
+	}
+	for _, c := range cases {
+		clause, err := ParseTagClause(c.clause)
+		if err != nil {
+			t.Fatalf("%s: %v", c.clause, err)
+		}
+		if clause.Match(task) != c.want {
+			t.Errorf("%s: want %v", c.clause, c.want)
+		}
+	}
+}
@@ -1,16 +1,69 @@
 package domain
 
 import (
+	"slices"
 	"time"
 )
 
 type Task struct {
-	ID          int        `json:"id"`
+	ID int `json:"id"`
+	// Version — номер версии задачи, растёт при каждом её сохранении.
+	Version int `json:"version"`
+	// OwnerID — пользователь, создавший задачу; 0 — задача создана без
+	// пользователя.
+	OwnerID     int        `json:"owner_id,omitempty"`
 	Headline    string     `json:"headline"`
 	Description string     `json:"description"`
-	Done        bool       `json:"done"`
-	CreatedAt   time.Time  `json:"created_at"`
-	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Priority    Priority   `json:"priority"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	DueTimezone string     `json:"due_timezone,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	ParentID    *int       `json:"parent_id,omitempty"`
+	BlockedBy   []int      `json:"blocked_by,omitempty"`
+	// Recurrence — правило повторения (RRULE), Occurrence — номер повторения
+	// в серии, SeriesID — ID первой задачи серии.
+	Recurrence string `json:"recurrence,omitempty"`
+	Occurrence int    `json:"occurrence,omitempty"`
+	SeriesID   int    `json:"series_id,omitempty"`
+	// Status меняется только через Transition; Done и CompletedAt выводятся
+	// из него и остаются для обратной совместимости.
+	Status          Status     `json:"status,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty"`
+	Done            bool       `json:"done"`
+	CreatedAt       time.Time  `json:"created_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	// ArchivedAt — когда закрытую задачу убрали в архив.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// DeletedAt — когда задачу переместили в корзину; nil для обычных задач.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Вычисляемые поля: сервис заполняет их при чтении, в хранилище они не попадают.
+	// Subtasks — прогресс подзадач, OpenBlockers — невыполненные задачи из BlockedBy.
+	Subtasks     *Progress `json:"-"`
+	OpenBlockers []int     `json:"-"`
+	// NextOccurrenceID — повторение, созданное при завершении этой задачи.
+	// Заполняется только в ответе на завершение.
+	NextOccurrenceID int `json:"-"`
+}
+
+// TaskInput — поля задачи, которые задаёт клиент при создании и изменении.
+type TaskInput struct {
+	Headline    string
+	Description string
+	Priority    Priority
+	DueAt       *time.Time
+	// DueTimezone — IANA-зона срока, например Europe/Moscow.
+	DueTimezone string
+	// Tags — уже нормализованные теги, см. NormalizeTags.
+	Tags []string
+	// ParentID — родительская задача, nil для задачи верхнего уровня.
+	ParentID *int
+	// BlockedBy — задачи, которые нужно выполнить раньше этой.
+	BlockedBy []int
+	// Recurrence — правило повторения в каноническом виде; требует DueAt.
+	Recurrence string
 }
 
 func NewTask(id int, headline string, description string) *Task {
@@ -18,8 +71,154 @@ func NewTask(id int, headline string, description string) *Task {
 		ID:          id,
 		Headline:    headline,
 		Description: description,
+		Priority:    PriorityNormal,
+		Status:      StatusTodo,
 		Done:        false,
 		CreatedAt:   time.Now(),
 		CompletedAt: nil,
 	}
 }
+
+// Apply переносит в задачу поля, заданные клиентом.
+func (t *Task) Apply(input TaskInput) {
+	t.Headline = input.Headline
+	t.Description = input.Description
+	t.Priority = input.Priority.OrDefault()
+	t.DueAt = cloneTime(input.DueAt)
+	t.DueTimezone = ""
+	if t.DueAt != nil {
+		t.DueTimezone = input.DueTimezone
+	}
+	t.Tags = slices.Clone(input.Tags)
+	t.ParentID = cloneInt(input.ParentID)
+	t.BlockedBy = NormalizeBlockers(input.BlockedBy)
+	t.Recurrence = input.Recurrence
+	if t.Recurrence != "" && t.Occurrence == 0 {
+		t.Occurrence = 1
+		t.SeriesID = t.ID
+	}
+}
+
+// Input возвращает поля задачи, которые задаёт клиент, см. Apply.
+func (t *Task) Input() TaskInput {
+	return TaskInput{
+		Headline:    t.Headline,
+		Description: t.Description,
+		Priority:    t.Priority,
+		DueAt:       cloneTime(t.DueAt),
+		DueTimezone: t.DueTimezone,
+		Tags:        slices.Clone(t.Tags),
+		ParentID:    cloneInt(t.ParentID),
+		BlockedBy:   slices.Clone(t.BlockedBy),
+		Recurrence:  t.Recurrence,
+	}
+}
+
+// Occurrence — будущее повторение задачи: его номер в серии и срок.
+type Occurrence struct {
+	Number int
+	DueAt  time.Time
+}
+
+// NextOccurrence создаёт следующее повторение задачи со сроком dueAt.
+func (t *Task) NextOccurrence(id int, dueAt time.Time) *Task {
+	next := NewTask(id, t.Headline, t.Description)
+	next.OwnerID = t.OwnerID
+	next.Priority = t.Priority
+	next.DueAt = &dueAt
+	next.DueTimezone = t.DueTimezone
+	next.Tags = slices.Clone(t.Tags)
+	next.ParentID = cloneInt(t.ParentID)
+	next.Recurrence = t.Recurrence
+	next.Occurrence = t.Occurrence + 1
+	next.SeriesID = t.SeriesID
+	return next
+}
+
+// IsArchived сообщает, что задача в архиве.
+func (t *Task) IsArchived() bool {
+	return t.ArchivedAt != nil
+}
+
+// IsDeleted сообщает, что задача лежит в корзине.
+func (t *Task) IsDeleted() bool {
+	return t.DeletedAt != nil
+}
+
+// IsBlocked сообщает, что задача не закрыта и ждёт других задач.
+func (t *Task) IsBlocked() bool {
+	return !t.CurrentStatus().IsClosed() && len(t.OpenBlockers) > 0
+}
+
+// IsOverdue сообщает, что срок задачи прошёл, а она ещё не закрыта.
+func (t *Task) IsOverdue(now time.Time) bool {
+	return !t.CurrentStatus().IsClosed() && t.DueAt != nil && t.DueAt.Before(now)
+}
+
+// DueIn возвращает время до срока (отрицательное для просроченных задач)
+// или nil, если срок не задан.
+func (t *Task) DueIn(now time.Time) *time.Duration {
+	if t.DueAt == nil {
+		return nil
+	}
+	d := t.DueAt.Sub(now)
+	return &d
+}
+
+// DueLocation возвращает зону срока задачи или UTC, если зона не задана.
+func (t *Task) DueLocation() *time.Location {
+	if t.DueTimezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(t.DueTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (t *Task) Clone() *Task {
+	clone := *t
+	clone.CompletedAt = cloneTime(t.CompletedAt)
+	clone.StatusChangedAt = cloneTime(t.StatusChangedAt)
+	clone.StartedAt = cloneTime(t.StartedAt)
+	clone.CancelledAt = cloneTime(t.CancelledAt)
+	clone.ArchivedAt = cloneTime(t.ArchivedAt)
+	clone.DeletedAt = cloneTime(t.DeletedAt)
+	clone.DueAt = cloneTime(t.DueAt)
+	clone.Tags = slices.Clone(t.Tags)
+	clone.ParentID = cloneInt(t.ParentID)
+	clone.BlockedBy = slices.Clone(t.BlockedBy)
+	clone.OpenBlockers = slices.Clone(t.OpenBlockers)
+	if t.Subtasks != nil {
+		progress := *t.Subtasks
+		clone.Subtasks = &progress
+	}
+	return &clone
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// NormalizeBlockers сортирует ID блокирующих задач и убирает повторы.
+func NormalizeBlockers(ids []int) []int {
+	if len(ids) == 0 {
+		return nil
+	}
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+func cloneInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
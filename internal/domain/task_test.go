@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"errors"
 	"testing"
+	"time"
 )
 
 func TestNewTask(t *testing.T) {
@@ -33,3 +35,79 @@ func TestNewTask(t *testing.T) {
 		t.Error("CompletedAt != nil")
 	}
 }
+
+func TestTaskClone(t *testing.T) {
+	task := NewTask(1, "Headline", "Description")
+	completedAt := task.CreatedAt
+	task.CompletedAt = &completedAt
+
+	clone := task.Clone()
+	clone.Headline = "Changed"
+	*clone.CompletedAt = completedAt.Add(1)
+
+	if task.Headline != "Headline" {
+		t.Error("headline shared")
+	}
+	if !task.CompletedAt.Equal(completedAt) {
+		t.Error("CompletedAt shared")
+	}
+}
+
+func TestCompareTasksNullsLast(t *testing.T) {
+	completedAt := time.Now()
+	done := NewTask(1, "A", "")
+	done.CompletedAt = &completedAt
+	open := NewTask(2, "B", "")
+
+	for _, desc := range []bool{false, true} {
+		keys := []SortKey{{Field: SortByCompletedAt, Desc: desc}}
+		if CompareTasks(done, open, keys) >= 0 {
+			t.Errorf("desc=%v: completed task not first", desc)
+		}
+	}
+}
+
+func TestCompareTasksTieBreakByID(t *testing.T) {
+	a := NewTask(1, "Same", "")
+	b := NewTask(2, "Same", "")
+
+	keys := []SortKey{{Field: SortByHeadline, Desc: true}}
+	if CompareTasks(a, b, keys) >= 0 {
+		t.Error("tie not broken by ID")
+	}
+}
+
+func TestTaskIsOverdue(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	task := NewTask(1, "H", "D")
+	if task.IsOverdue(now) || task.DueIn(now) != nil {
+		t.Error("task without due_at")
+	}
+
+	dueAt := now.Add(-time.Minute)
+	task.DueAt = &dueAt
+	if !task.IsOverdue(now) {
+		t.Error("overdue != true")
+	}
+	if d := task.DueIn(now); d == nil || *d != -time.Minute {
+		t.Errorf("due_in: %v", d)
+	}
+
+	task.Done = true
+	if task.IsOverdue(now) {
+		t.Error("done task overdue")
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	p, err := ParsePriority("")
+	if err != nil || p != PriorityNormal {
+		t.Errorf("empty: %s, %v", p, err)
+	}
+	if _, err := ParsePriority("asap"); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("asap: %v", err)
+	}
+	if !(PriorityLow.Rank() < PriorityNormal.Rank() && PriorityHigh.Rank() < PriorityUrgent.Rank()) {
+		t.Error("rank order")
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+// Progress — сколько подзадач (на всех уровнях вложенности) выполнено.
+type Progress struct {
+	Done  int
+	Total int
+}
+
+// Percent возвращает долю выполненных подзадач в процентах, округлённую вниз.
+func (p Progress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Done * 100 / p.Total
+}
+
+// TaskNode — задача вместе со всеми подзадачами.
+type TaskNode struct {
+	Task     *Task
+	Children []*TaskNode
+}
+
+// CompleteOptions управляют завершением задачи.
+type CompleteOptions struct {
+	// Cascade — завершить и все невыполненные подзадачи.
+	Cascade bool
+	// Force — завершить задачу, даже если у неё остались невыполненные
+	// блокирующие задачи.
+	Force bool
+}
+
+// DeleteOptions управляют удалением задачи.
+type DeleteOptions struct {
+	// Cascade — удалить задачу вместе со всеми подзадачами. Без него задачу
+	// с подзадачами удалить нельзя.
+	Cascade bool
+}
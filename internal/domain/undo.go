@@ -0,0 +1,10 @@
+package domain
+
+// UndoResult — итог отмены или повтора операции: её вид (create, update,
+// complete или delete), задача, над которой она выполнялась, и состояние
+// задачи после отмены или повтора.
+type UndoResult struct {
+	Operation string
+	TaskID    int
+	Task      *Task
+}
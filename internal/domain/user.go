@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxUserNameLength — наибольшая длина имени пользователя.
+	MaxUserNameLength = 64
+	// MinPasswordLength и MaxPasswordLength ограничивают длину пароля в
+	// символах.
+	MinPasswordLength = 8
+	MaxPasswordLength = 256
+)
+
+// User — учётная запись. Задачи принадлежат создавшему их пользователю и
+// видны только ему.
+type User struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// PasswordHash — хеш пароля Argon2id; пустой, если пароль не задан и
+	// пользователь входит только по ключам API.
+	PasswordHash string    `json:"password_hash,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (u *User) Clone() *User {
+	clone := *u
+	return &clone
+}
+
+// NormalizeUserName приводит имя пользователя к нижнему регистру и
+// проверяет его: латинские буквы, цифры и символы "-", "_", ".", первый
+// символ — буква или цифра.
+func NormalizeUserName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("%w: user name is required", ErrInvalidRequest)
+	}
+	if len(name) > MaxUserNameLength {
+		return "", fmt.Errorf("%w: user name is longer than %d characters", ErrInvalidRequest, MaxUserNameLength)
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		case i > 0 && strings.ContainsRune("-_.", r):
+		default:
+			return "", fmt.Errorf("%w: user name %q contains invalid character %q", ErrInvalidRequest, name, r)
+		}
+	}
+	return name, nil
+}
+
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// ValidatePassword проверяет длину пароля.
+func ValidatePassword(password string) error {
+	n := utf8.RuneCountInString(password)
+	if n < MinPasswordLength {
+		return fmt.Errorf("%w: password must be at least %d characters", ErrInvalidRequest, MinPasswordLength)
+	}
+	if n > MaxPasswordLength {
+		return fmt.Errorf("%w: password is longer than %d characters", ErrInvalidRequest, MaxPasswordLength)
+	}
+	return nil
+}
+
+type userKey struct{}
+
+type allUsersKey struct{}
+
+// WithUser сохраняет в контексте пользователя, от имени которого идёт
+// запрос. Сервис задач видит в таком контексте только его задачи. В HTTP
+// пользователя кладёт проверка заголовка Authorization, см.
+// server.WithAuthenticator; без неё запросы идут без пользователя.
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom возвращает пользователя из контекста; ok — false для запроса
+// без пользователя.
+func UserFrom(ctx context.Context) (user *User, ok bool) {
+	user, ok = ctx.Value(userKey{}).(*User)
+	return user, ok && user != nil
+}
+
+// UserID возвращает ID пользователя из контекста или 0 для запроса без
+// пользователя. Такому запросу доступны только задачи без владельца.
+func UserID(ctx context.Context) int {
+	if user, ok := UserFrom(ctx); ok {
+		return user.ID
+	}
+	return 0
+}
+
+// WithAllUsers снимает с контекста ограничение по владельцу задач. Так
+// работают фоновые задачи сервера, обслуживающие всех пользователей.
+func WithAllUsers(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allUsersKey{}, true)
+}
+
+// AllUsers сообщает, что контексту доступны задачи всех пользователей.
+func AllUsers(ctx context.Context) bool {
+	all, _ := ctx.Value(allUsersKey{}).(bool)
+	return all
+}
+
+// VisibleTo сообщает, доступна ли задача из контекста ctx.
+func (t *Task) VisibleTo(ctx context.Context) bool {
+	return AllUsers(ctx) || t.OwnerID == UserID(ctx)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeUserName(t *testing.T) {
+	name, err := NormalizeUserName(" Alice.Smith_2 ")
+	if err != nil || name != "alice.smith_2" {
+		t.Errorf("got %q, %v", name, err)
+	}
+	for _, bad := range []string{"", "  ", "_alice", "al ice", "алиса", strings.Repeat("a", MaxUserNameLength+1)} {
+		if _, err := NormalizeUserName(bad); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("%q: want ErrInvalidRequest, got %v", bad, err)
+		}
+	}
+}
+
+func TestTaskVisibleTo(t *testing.T) {
+	task := &Task{OwnerID: 1}
+	if task.VisibleTo(context.Background()) {
+		t.Error("owned task visible without user")
+	}
+	if !task.VisibleTo(WithUser(context.Background(), &User{ID: 1})) {
+		t.Error("task not visible to owner")
+	}
+	if task.VisibleTo(WithUser(context.Background(), &User{ID: 2})) {
+		t.Error("task visible to another user")
+	}
+	if !task.VisibleTo(WithAllUsers(context.Background())) {
+		t.Error("task not visible with WithAllUsers")
+	}
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "context"
+
+type ifMatchKey struct{}
+
+// WithIfMatch сохраняет в контексте версии задачи, которые ожидает клиент.
+// Изменение задачи другой версии отклоняется с ErrVersionMismatch.
+func WithIfMatch(ctx context.Context, versions []int) context.Context {
+	return context.WithValue(ctx, ifMatchKey{}, versions)
+}
+
+// IfMatchFrom возвращает ожидаемые версии из контекста; ok — false, если
+// клиент их не задал.
+func IfMatchFrom(ctx context.Context) (versions []int, ok bool) {
+	versions, ok = ctx.Value(ifMatchKey{}).([]int)
+	return versions, ok
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+type CreateAPIKeyReq struct {
+	Name string `json:"name"`
+}
+
+func (r CreateAPIKeyReq) Validate() error {
+	_, err := domain.NormalizeAPIKeyName(r.Name)
+	return err
+}
+
+// APIKeyRes описывает ключ API. Key — сам ключ, он есть только в ответе
+// на создание ключа.
+type APIKeyRes struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Hint       string     `json:"hint"`
+	Key        string     `json:"key,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func NewAPIKeyRes(key *domain.APIKey) APIKeyRes {
+	return APIKeyRes{
+		ID:         key.ID,
+		Name:       key.Name,
+		Hint:       key.Hint,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+func NewCreatedAPIKeyRes(key *domain.APIKey, secret string) APIKeyRes {
+	res := NewAPIKeyRes(key)
+	res.Key = secret
+	return res
+}
+
+type APIKeyListRes struct {
+	Keys []APIKeyRes `json:"keys"`
+}
+
+func NewAPIKeyListRes(keys []*domain.APIKey) APIKeyListRes {
+	res := APIKeyListRes{Keys: make([]APIKeyRes, len(keys))}
+	for i, key := range keys {
+		res.Keys[i] = NewAPIKeyRes(key)
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+type LoginReq struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func (r LoginReq) Validate() error {
+	if r.Name == "" || r.Password == "" {
+		return fmt.Errorf("%w: name and password are required", domain.ErrInvalidRequest)
+	}
+	return nil
+}
+
+type RefreshReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (r RefreshReq) Validate() error {
+	if r.RefreshToken == "" {
+		return fmt.Errorf("%w: refresh_token is required", domain.ErrInvalidRequest)
+	}
+	return nil
+}
+
+// LogoutReq — запрос на выход. RefreshToken необязателен: если он
+// передан, отзывается и вся цепочка токенов обновления этой сессии.
+type LogoutReq struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type ChangePasswordReq struct {
+	CurrentPassword string `json:"current_password,omitempty"`
+	NewPassword     string `json:"new_password"`
+}
+
+func (r ChangePasswordReq) Validate() error {
+	if r.NewPassword == "" {
+		return fmt.Errorf("%w: new_password is required", domain.ErrInvalidRequest)
+	}
+	return domain.ValidatePassword(r.NewPassword)
+}
+
+// TokenRes — ответ на вход и обновление токенов. Сроки указаны в секундах.
+type TokenRes struct {
+	AccessToken      string  `json:"access_token"`
+	TokenType        string  `json:"token_type"`
+	ExpiresIn        int     `json:"expires_in"`
+	RefreshToken     string  `json:"refresh_token"`
+	RefreshExpiresIn int     `json:"refresh_expires_in"`
+	User             UserRes `json:"user"`
+}
+
+func NewTokenRes(session *domain.Session, now time.Time) TokenRes {
+	return TokenRes{
+		AccessToken:      session.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(session.AccessExpiresAt.Sub(now).Seconds()),
+		RefreshToken:     session.RefreshToken,
+		RefreshExpiresIn: int(session.RefreshExpiresAt.Sub(now).Seconds()),
+		User:             NewUserRes(session.User),
+	}
+}
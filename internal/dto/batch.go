@@ -0,0 +1,176 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// Режимы пакетного запроса.
+const (
+	BatchAtomic     = "atomic"
+	BatchBestEffort = "best_effort"
+)
+
+const maxBatchSize = 100
+
+// BatchReq — пакет операций над задачами. В режиме atomic (по умолчанию)
+// пакет выполняется целиком или не выполняется вовсе, в режиме best_effort
+// операции независимы.
+type BatchReq struct {
+	Mode       string       `json:"mode"`
+	Operations []BatchOpReq `json:"operations"`
+}
+
+// BatchOpReq — операция пакета. Task для create — тело POST /todos, для
+// update — JSON Merge Patch к задаче. Version, если задана, должна
+// совпадать с текущей версией задачи.
+type BatchOpReq struct {
+	Op      string          `json:"op"`
+	ID      int             `json:"id"`
+	Version int             `json:"version"`
+	Task    json.RawMessage `json:"task"`
+	Cascade bool            `json:"cascade"`
+	Force   bool            `json:"force"`
+}
+
+func (r BatchReq) Atomic() bool {
+	return r.Mode != BatchBestEffort
+}
+
+// ToOps проверяет пакет и переводит его в операции сервиса. Ошибка
+// возвращается как domain.ErrInvalidRequest с номером операции.
+func (r BatchReq) ToOps() ([]domain.BatchOp, error) {
+	if err := validateMode(r.Mode); err != nil {
+		return nil, err
+	}
+	if len(r.Operations) == 0 {
+		return nil, fmt.Errorf("%w: operations must not be empty", domain.ErrInvalidRequest)
+	}
+	if len(r.Operations) > maxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d operations per batch", domain.ErrInvalidRequest, maxBatchSize)
+	}
+
+	ops := make([]domain.BatchOp, len(r.Operations))
+	for i, req := range r.Operations {
+		op, err := req.toOp()
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		ops[i] = op
+	}
+	return ops, nil
+}
+
+func validateMode(mode string) error {
+	switch mode {
+	case "", BatchAtomic, BatchBestEffort:
+		return nil
+	}
+	return fmt.Errorf("%w: mode must be %s or %s", domain.ErrInvalidRequest, BatchAtomic, BatchBestEffort)
+}
+
+func (r BatchOpReq) toOp() (domain.BatchOp, error) {
+	if r.Version < 0 {
+		return domain.BatchOp{}, fmt.Errorf("%w: version must be positive", domain.ErrInvalidRequest)
+	}
+	if r.Op == domain.BatchCreate {
+		if r.ID != 0 || r.Version != 0 {
+			return domain.BatchOp{}, fmt.Errorf("%w: id and version are not allowed for create", domain.ErrInvalidRequest)
+		}
+	} else if r.ID <= 0 {
+		return domain.BatchOp{}, fmt.Errorf("%w: id is required", domain.ErrInvalidRequest)
+	}
+
+	op, err := parseOp(r.Op, r.Task, r.Cascade, r.Force)
+	if err != nil {
+		return op, err
+	}
+	op.ID, op.Version = r.ID, r.Version
+	return op, nil
+}
+
+// parseOp разбирает операцию без привязки к задаче.
+func parseOp(kind string, task json.RawMessage, cascade bool, force bool) (domain.BatchOp, error) {
+	op := domain.BatchOp{Kind: kind}
+	hasTask := len(bytes.TrimSpace(task)) > 0
+
+	switch kind {
+	case domain.BatchCreate:
+		if !hasTask {
+			return op, fmt.Errorf("%w: task is required", domain.ErrInvalidRequest)
+		}
+		var req CreateTaskReq
+		if err := json.Unmarshal(task, &req); err != nil {
+			return op, fmt.Errorf("%w: invalid task: %v", domain.ErrInvalidRequest, err)
+		}
+		if err := req.ValidateForCreate(); err != nil {
+			return op, err
+		}
+		op.Input = req.ToInput()
+	case domain.BatchUpdate:
+		if !hasTask {
+			return op, fmt.Errorf("%w: task is required", domain.ErrInvalidRequest)
+		}
+		patch, err := ParseMergePatch(task)
+		if err != nil {
+			return op, err
+		}
+		op.Patch = patch.Apply
+	case domain.BatchComplete:
+		op.Complete = domain.CompleteOptions{Cascade: cascade, Force: force}
+	case domain.BatchDelete:
+		op.Delete = domain.DeleteOptions{Cascade: cascade}
+	default:
+		return op, fmt.Errorf("%w: op must be one of %s, %s, %s, %s", domain.ErrInvalidRequest,
+			domain.BatchCreate, domain.BatchUpdate, domain.BatchComplete, domain.BatchDelete)
+	}
+	if hasTask && kind != domain.BatchCreate && kind != domain.BatchUpdate {
+		return op, fmt.Errorf("%w: task is not allowed for %s", domain.ErrInvalidRequest, kind)
+	}
+	return op, nil
+}
+
+type BatchRes struct {
+	Mode    string           `json:"mode"`
+	Results []BatchResultRes `json:"results"`
+}
+
+func NewBatchRes(atomic bool, results []BatchResultRes) BatchRes {
+	return BatchRes{Mode: batchMode(atomic), Results: results}
+}
+
+func batchMode(atomic bool) string {
+	if atomic {
+		return BatchAtomic
+	}
+	return BatchBestEffort
+}
+
+// BatchResultRes — итог операции пакета. Status — код, который вернул бы
+// отдельный запрос с этой операцией.
+type BatchResultRes struct {
+	Index  int      `json:"index"`
+	Op     string   `json:"op"`
+	ID     int      `json:"id,omitempty"`
+	Status int      `json:"status"`
+	Task   *TaskRes `json:"task,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+func NewBatchResultRes(index int, op string, res domain.BatchResult, status int, message string) BatchResultRes {
+	item := BatchResultRes{
+		Index:  index,
+		Op:     op,
+		ID:     res.TaskID,
+		Status: status,
+		Error:  message,
+	}
+	if res.Task != nil {
+		task := NewTaskRes(res.Task)
+		item.Task = &task
+	}
+	return item
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func TestBatchToOps(t *testing.T) {
+	var req BatchReq
+	body := `{"operations": [
+		{"op": "create", "task": {"headline": "New", "description": "D"}},
+		{"op": "update", "id": 1, "version": 2, "task": {"headline": "Renamed"}},
+		{"op": "complete", "id": 1, "cascade": true},
+		{"op": "delete", "id": 2}
+	]}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !req.Atomic() {
+		t.Error("batch must be atomic by default")
+	}
+
+	ops, err := req.ToOps()
+	if err != nil {
+		t.Fatalf("to ops: %v", err)
+	}
+	if ops[0].Kind != domain.BatchCreate || ops[0].Input.Headline != "New" {
+		t.Errorf("create: %+v", ops[0])
+	}
+	input, err := ops[1].Patch(patchTestTask())
+	if err != nil || input.Headline != "Renamed" || input.Description != "Квартальный" || ops[1].Version != 2 {
+		t.Errorf("update: %+v, %v", input, err)
+	}
+	if !ops[2].Complete.Cascade || ops[3].ID != 2 {
+		t.Errorf("complete and delete: %+v %+v", ops[2], ops[3])
+	}
+}
+
+func TestBatchToOpsValidation(t *testing.T) {
+	for _, body := range []string{
+		`{"operations": []}`,
+		`{"mode": "partial", "operations": [{"op": "delete", "id": 1}]}`,
+		`{"operations": [{"op": "archive", "id": 1}]}`,
+		`{"operations": [{"op": "create", "id": 1, "task": {"headline": "H", "description": "D"}}]}`,
+		`{"operations": [{"op": "create", "task": {"headline": ""}}]}`,
+		`{"operations": [{"op": "update", "id": 1}]}`,
+		`{"operations": [{"op": "update", "id": 1, "task": ["x"]}]}`,
+		`{"operations": [{"op": "delete"}]}`,
+		`{"operations": [{"op": "delete", "id": 1, "task": {}}]}`,
+	} {
+		var req BatchReq
+		if err := json.Unmarshal([]byte(body), &req); err != nil {
+			t.Fatalf("decode %s: %v", body, err)
+		}
+		if _, err := req.ToOps(); !errors.Is(err, domain.ErrInvalidRequest) {
+			t.Errorf("%s: want ErrInvalidRequest, got %v", body, err)
+		}
+	}
+}
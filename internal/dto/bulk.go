@@ -0,0 +1,102 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// BulkReq — операция над всеми задачами, подходящими под фильтр из
+// параметров запроса. Op, Task, Cascade и Force — как у операции пакета,
+// только create не допускается. С DryRun сервер лишь находит задачи.
+type BulkReq struct {
+	Op      string          `json:"op"`
+	Mode    string          `json:"mode"`
+	DryRun  bool            `json:"dry_run"`
+	Task    json.RawMessage `json:"task"`
+	Cascade bool            `json:"cascade"`
+	Force   bool            `json:"force"`
+}
+
+func (r BulkReq) Atomic() bool {
+	return r.Mode != BatchBestEffort
+}
+
+// ToRequest проверяет запрос и добавляет к нему фильтр из values — те же
+// параметры, что у GET /todos, кроме sort, limit и cursor. Запрос без
+// условий отбора и с незнакомыми параметрами отклоняется, чтобы опечатка
+// в фильтре не изменила все задачи.
+func (r BulkReq) ToRequest(values url.Values) (domain.BulkRequest, error) {
+	var req domain.BulkRequest
+	if err := validateMode(r.Mode); err != nil {
+		return req, err
+	}
+	if r.Op == domain.BatchCreate {
+		return req, fmt.Errorf("%w: op must be one of %s, %s, %s", domain.ErrInvalidRequest,
+			domain.BatchUpdate, domain.BatchComplete, domain.BatchDelete)
+	}
+	op, err := parseOp(r.Op, r.Task, r.Cascade, r.Force)
+	if err != nil {
+		return req, err
+	}
+
+	for name := range values {
+		switch {
+		case name == "sort" || name == "limit" || name == "cursor":
+			return req, fmt.Errorf("%w: %s is not allowed in bulk requests", domain.ErrInvalidRequest, name)
+		case !taskQueryParams[name]:
+			return req, fmt.Errorf("%w: unknown filter parameter %q", domain.ErrInvalidRequest, name)
+		}
+	}
+	query, err := ParseTaskQuery(values)
+	if err != nil {
+		return req, err
+	}
+	if !hasConditions(query.Filter) {
+		return req, fmt.Errorf("%w: filter is required", domain.ErrInvalidRequest)
+	}
+
+	return domain.BulkRequest{
+		Filter: query.Filter,
+		Op:     op,
+		Atomic: r.Atomic(),
+		DryRun: r.DryRun,
+	}, nil
+}
+
+// hasConditions сообщает, отбирает ли фильтр задачи хоть по чему-то,
+// кроме исключения архива по умолчанию.
+func hasConditions(f domain.TaskFilter) bool {
+	return f.Done != nil || len(f.Statuses) > 0 ||
+		f.CreatedAfter != nil || f.CreatedBefore != nil ||
+		f.CompletedAfter != nil || f.CompletedBefore != nil ||
+		f.HeadlineContains != "" || len(f.Priorities) > 0 ||
+		f.DueBefore != nil || f.Overdue != nil || len(f.Tags) > 0 ||
+		f.Blocked != nil || (f.Archived != nil && *f.Archived)
+}
+
+type BulkRes struct {
+	Op      string           `json:"op"`
+	Mode    string           `json:"mode"`
+	DryRun  bool             `json:"dry_run"`
+	Count   int              `json:"count"`
+	IDs     []int            `json:"ids"`
+	Results []BatchResultRes `json:"results,omitempty"`
+}
+
+func NewBulkRes(req domain.BulkRequest, res *domain.BulkResult, results []BatchResultRes) BulkRes {
+	ids := res.IDs
+	if ids == nil {
+		ids = []int{}
+	}
+	return BulkRes{
+		Op:      req.Op.Kind,
+		Mode:    batchMode(req.Atomic),
+		DryRun:  req.DryRun,
+		Count:   len(ids),
+		IDs:     ids,
+		Results: results,
+	}
+}
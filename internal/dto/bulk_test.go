@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func TestBulkToRequest(t *testing.T) {
+	values := url.Values{"tag": {"backend"}, "status": {"done"}}
+	req, err := BulkReq{Op: domain.BatchDelete, DryRun: true, Cascade: true}.ToRequest(values)
+	if err != nil {
+		t.Fatalf("to request: %v", err)
+	}
+	if !req.Atomic || !req.DryRun || !req.Op.Delete.Cascade {
+		t.Errorf("request: %+v", req)
+	}
+	if len(req.Filter.Tags) != 1 || len(req.Filter.Statuses) != 1 || req.Filter.Archived == nil {
+		t.Errorf("filter: %+v", req.Filter)
+	}
+	if req, err := (BulkReq{Op: domain.BatchDelete}).ToRequest(url.Values{"archived": {"true"}}); err != nil || !*req.Filter.Archived {
+		t.Errorf("archived only: %+v, %v", req.Filter, err)
+	}
+
+	for _, tc := range []struct {
+		req    BulkReq
+		values url.Values
+	}{
+		{BulkReq{Op: domain.BatchComplete}, url.Values{}},
+		{BulkReq{Op: domain.BatchComplete}, url.Values{"tag": {"a"}, "limit": {"10"}}},
+		{BulkReq{Op: domain.BatchComplete}, url.Values{"done": {"maybe"}}},
+		// Опечатка в имени параметра не должна превращаться в пустой фильтр
+		{BulkReq{Op: domain.BatchDelete}, url.Values{"tags": {"release"}}},
+		{BulkReq{Op: domain.BatchDelete}, url.Values{"tag": {"a"}, "stauts": {"done"}}},
+		// Параметры без условий отбора
+		{BulkReq{Op: domain.BatchDelete}, url.Values{"done": {""}}},
+		{BulkReq{Op: domain.BatchDelete}, url.Values{"archived": {"all"}}},
+		{BulkReq{Op: domain.BatchDelete}, url.Values{"archived": {"false"}}},
+		{BulkReq{Op: domain.BatchDelete}, url.Values{"headline": {""}, "tag": {}}},
+		{BulkReq{Op: domain.BatchCreate, Task: json.RawMessage(`{"headline": "H", "description": "D"}`)}, url.Values{"tag": {"a"}}},
+		{BulkReq{Op: domain.BatchUpdate}, url.Values{"tag": {"a"}}},
+		{BulkReq{Op: domain.BatchDelete, Mode: "partial"}, url.Values{"tag": {"a"}}},
+	} {
+		if _, err := tc.req.ToRequest(tc.values); !errors.Is(err, domain.ErrInvalidRequest) {
+			t.Errorf("%+v %v: want ErrInvalidRequest, got %v", tc.req, tc.values, err)
+		}
+	}
+}
@@ -2,15 +2,24 @@ package dto
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/recurrence"
 )
 
 // Request DTO
 type CreateTaskReq struct {
-	Headline    string `json:"headline"`
-	Description string `json:"description"`
+	Headline    string   `json:"headline"`
+	Description string   `json:"description"`
+	Priority    string   `json:"priority"`
+	DueAt       string   `json:"due_at"`
+	DueTimezone string   `json:"due_timezone"`
+	Tags        []string `json:"tags"`
+	ParentID    *int     `json:"parent_id"`
+	BlockedBy   []int    `json:"blocked_by"`
+	Recurrence  string   `json:"recurrence"`
 }
 
 func (t CreateTaskReq) ValidateForCreate() error {
@@ -20,33 +29,314 @@ func (t CreateTaskReq) ValidateForCreate() error {
 	if t.Description == "" {
 		return domain.ErrInvalidRequest
 	}
-	return nil
+	if err := validateSchedule(t.Priority, t.DueAt, t.DueTimezone); err != nil {
+		return err
+	}
+	if t.ParentID != nil && *t.ParentID <= 0 {
+		return fmt.Errorf("%w: invalid parent_id", domain.ErrInvalidRequest)
+	}
+	if err := validateBlockers(t.BlockedBy); err != nil {
+		return err
+	}
+	if err := validateRecurrence(t.Recurrence, t.DueAt); err != nil {
+		return err
+	}
+	_, err := domain.NormalizeTags(t.Tags)
+	return err
+}
+
+func (t CreateTaskReq) ToInput() domain.TaskInput {
+	return toInput(t.Headline, t.Description, t.Priority, t.DueAt, t.DueTimezone, t.Tags, t.ParentID, t.BlockedBy, t.Recurrence)
 }
 
 type UpdateTaskReq struct {
-	Headline    string `json:"headline"`
-	Description string `json:"description"`
+	Headline    string   `json:"headline"`
+	Description string   `json:"description"`
+	Priority    string   `json:"priority"`
+	DueAt       string   `json:"due_at"`
+	DueTimezone string   `json:"due_timezone"`
+	Tags        []string `json:"tags"`
+	ParentID    *int     `json:"parent_id"`
+	BlockedBy   []int    `json:"blocked_by"`
+	Recurrence  string   `json:"recurrence"`
 }
 
 func (t UpdateTaskReq) ValidateForUpdate() error {
 	if t.Headline == "" {
 		return domain.ErrInvalidRequest
 	}
+	if err := validateSchedule(t.Priority, t.DueAt, t.DueTimezone); err != nil {
+		return err
+	}
+	if t.ParentID != nil && *t.ParentID <= 0 {
+		return fmt.Errorf("%w: invalid parent_id", domain.ErrInvalidRequest)
+	}
+	if err := validateBlockers(t.BlockedBy); err != nil {
+		return err
+	}
+	if err := validateRecurrence(t.Recurrence, t.DueAt); err != nil {
+		return err
+	}
+	_, err := domain.NormalizeTags(t.Tags)
+	return err
+}
+
+func (t UpdateTaskReq) ToInput() domain.TaskInput {
+	return toInput(t.Headline, t.Description, t.Priority, t.DueAt, t.DueTimezone, t.Tags, t.ParentID, t.BlockedBy, t.Recurrence)
+}
+
+func validateSchedule(priority string, dueAt string, timezone string) error {
+	if _, err := domain.ParsePriority(priority); err != nil {
+		return err
+	}
+	if timezone != "" && dueAt == "" {
+		return fmt.Errorf("%w: due_timezone requires due_at", domain.ErrInvalidRequest)
+	}
+	_, err := parseDueAt(dueAt, timezone)
+	return err
+}
+
+func validateBlockers(ids []int) error {
+	for _, id := range ids {
+		if id <= 0 {
+			return fmt.Errorf("%w: invalid blocked_by", domain.ErrInvalidRequest)
+		}
+	}
 	return nil
 }
 
+// validateRecurrence проверяет правило повторения. Повторения считаются от
+// срока, поэтому без due_at правило не имеет смысла.
+func validateRecurrence(rule string, dueAt string) error {
+	if rule == "" {
+		return nil
+	}
+	if _, err := recurrence.Parse(rule); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidRequest, err)
+	}
+	if dueAt == "" {
+		return fmt.Errorf("%w: recurrence requires due_at", domain.ErrInvalidRequest)
+	}
+	return nil
+}
+
+func toInput(headline string, description string, priority string, dueAt string, timezone string, tags []string, parentID *int, blockedBy []int, rule string) domain.TaskInput {
+	p, _ := domain.ParsePriority(priority)
+	due, _ := parseDueAt(dueAt, timezone)
+	normalized, _ := domain.NormalizeTags(tags)
+	if r, err := recurrence.Parse(rule); err == nil {
+		rule = r.String()
+	}
+	return domain.TaskInput{
+		Headline:    headline,
+		Description: description,
+		Priority:    p,
+		DueAt:       due,
+		DueTimezone: timezone,
+		Tags:        normalized,
+		ParentID:    parentID,
+		BlockedBy:   domain.NormalizeBlockers(blockedBy),
+		Recurrence:  rule,
+	}
+}
+
+// parseDueAt разбирает срок задачи. Срок со смещением (RFC 3339) задаёт
+// точный момент. Если задана due_timezone, можно указать и местное время без
+// смещения ("2025-03-01T18:00:00") или только дату ("2025-03-01") — тогда
+// сроком считается конец этого дня в указанной зоне.
+func parseDueAt(dueAt string, timezone string) (*time.Time, error) {
+	if dueAt == "" {
+		return nil, nil
+	}
+
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown due_timezone %q", domain.ErrInvalidRequest, timezone)
+		}
+	}
+
+	if t, err := time.Parse(time.RFC3339, dueAt); err == nil {
+		t = t.In(loc)
+		return &t, nil
+	}
+	if timezone == "" {
+		return nil, fmt.Errorf("%w: due_at must be an RFC 3339 timestamp or due_timezone must be set", domain.ErrInvalidRequest)
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", dueAt, loc); err == nil {
+		return &t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, dueAt, loc); err == nil {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+		return &t, nil
+	}
+	return nil, fmt.Errorf("%w: invalid due_at %q", domain.ErrInvalidRequest, dueAt)
+}
+
 // Response DTO
 type TaskRes struct {
 	ID          int        `json:"id"`
+	Version     int        `json:"version"`
+	OwnerID     int        `json:"owner_id,omitempty"`
 	Headline    string     `json:"headline"`
 	Description string     `json:"description"`
-	Done        bool       `json:"done"`
-	CreatedAt   time.Time  `json:"created_at"`
-	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Priority    string     `json:"priority"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	DueTimezone string     `json:"due_timezone,omitempty"`
+	// DueIn — секунды до срока, отрицательные для просроченной задачи.
+	DueIn    *int64   `json:"due_in,omitempty"`
+	Overdue  bool     `json:"overdue"`
+	Tags     []string `json:"tags"`
+	ParentID *int     `json:"parent_id,omitempty"`
+	// Progress есть только у задач с подзадачами.
+	Progress  *ProgressRes `json:"progress,omitempty"`
+	BlockedBy []int        `json:"blocked_by"`
+	// Blocked — задача не выполнена и ждёт задач из OpenBlockers.
+	Blocked      bool   `json:"blocked"`
+	OpenBlockers []int  `json:"open_blockers,omitempty"`
+	Recurrence   string `json:"recurrence,omitempty"`
+	Occurrence   int    `json:"occurrence,omitempty"`
+	SeriesID     int    `json:"series_id,omitempty"`
+	// NextOccurrenceID — повторение, созданное при завершении задачи.
+	NextOccurrenceID int        `json:"next_occurrence_id,omitempty"`
+	Status           string     `json:"status"`
+	StatusChangedAt  *time.Time `json:"status_changed_at,omitempty"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	CancelledAt      *time.Time `json:"cancelled_at,omitempty"`
+	Done             bool       `json:"done"`
+	CreatedAt        time.Time  `json:"created_at"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	ArchivedAt       *time.Time `json:"archived_at,omitempty"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+}
+
+func NewTaskRes(task *domain.Task) TaskRes {
+	return newTaskRes(task, time.Now())
+}
+
+func newTaskRes(task *domain.Task, now time.Time) TaskRes {
+	res := TaskRes{
+		ID:               task.ID,
+		Version:          task.Version,
+		OwnerID:          task.OwnerID,
+		Headline:         task.Headline,
+		Description:      task.Description,
+		Priority:         string(task.Priority.OrDefault()),
+		DueTimezone:      task.DueTimezone,
+		Overdue:          task.IsOverdue(now),
+		Tags:             task.Tags,
+		ParentID:         task.ParentID,
+		BlockedBy:        task.BlockedBy,
+		Blocked:          task.IsBlocked(),
+		OpenBlockers:     task.OpenBlockers,
+		Recurrence:       task.Recurrence,
+		Occurrence:       task.Occurrence,
+		SeriesID:         task.SeriesID,
+		NextOccurrenceID: task.NextOccurrenceID,
+		Status:           string(task.CurrentStatus()),
+		StatusChangedAt:  task.StatusChangedAt,
+		StartedAt:        task.StartedAt,
+		CancelledAt:      task.CancelledAt,
+		Done:             task.Done,
+		CreatedAt:        task.CreatedAt,
+		CompletedAt:      task.CompletedAt,
+		ArchivedAt:       task.ArchivedAt,
+		DeletedAt:        task.DeletedAt,
+	}
+	if res.Tags == nil {
+		res.Tags = []string{}
+	}
+	if res.BlockedBy == nil {
+		res.BlockedBy = []int{}
+	}
+	if task.Subtasks != nil {
+		res.Progress = &ProgressRes{
+			Done:    task.Subtasks.Done,
+			Total:   task.Subtasks.Total,
+			Percent: task.Subtasks.Percent(),
+		}
+	}
+	if task.DueAt != nil {
+		// Срок показываем в зоне задачи, чтобы клиент видел местное время
+		dueAt := task.DueAt.In(task.DueLocation())
+		res.DueAt = &dueAt
+		dueIn := int64(task.DueIn(now).Seconds())
+		res.DueIn = &dueIn
+	}
+	return res
+}
+
+// ProgressRes — сколько подзадач на всех уровнях вложенности выполнено.
+type ProgressRes struct {
+	Done    int `json:"done"`
+	Total   int `json:"total"`
+	Percent int `json:"percent"`
+}
+
+// OccurrencesRes — ближайшие повторения задачи.
+type OccurrencesRes struct {
+	TaskID      int             `json:"task_id"`
+	Occurrences []OccurrenceRes `json:"occurrences"`
+}
+
+type OccurrenceRes struct {
+	Occurrence int       `json:"occurrence"`
+	DueAt      time.Time `json:"due_at"`
+}
+
+func NewOccurrencesRes(taskID int, occurrences []domain.Occurrence) OccurrencesRes {
+	res := OccurrencesRes{
+		TaskID:      taskID,
+		Occurrences: make([]OccurrenceRes, 0, len(occurrences)),
+	}
+	for _, o := range occurrences {
+		res.Occurrences = append(res.Occurrences, OccurrenceRes{Occurrence: o.Number, DueAt: o.DueAt})
+	}
+	return res
 }
 
 type TaskListRes struct {
-	Tasks []TaskRes `json:"tasks"`
+	Tasks      []TaskRes `json:"tasks"`
+	Total      int       `json:"total"`
+	Limit      int       `json:"limit"`
+	HasMore    bool      `json:"has_more"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+func NewTaskListRes(page *domain.TaskPage) TaskListRes {
+	res := TaskListRes{
+		Tasks:      make([]TaskRes, 0, len(page.Tasks)),
+		Total:      page.Total,
+		Limit:      page.Limit,
+		HasMore:    page.NextCursor != "",
+		NextCursor: page.NextCursor,
+	}
+	for _, task := range page.Tasks {
+		res.Tasks = append(res.Tasks, NewTaskRes(task))
+	}
+	return res
+}
+
+type SearchResultRes struct {
+	TaskRes
+	Score float64 `json:"score"`
+}
+
+type SearchRes struct {
+	Query   string            `json:"query"`
+	Results []SearchResultRes `json:"results"`
+}
+
+func NewSearchRes(query string, results []domain.SearchResult) SearchRes {
+	res := SearchRes{
+		Query:   query,
+		Results: make([]SearchResultRes, 0, len(results)),
+	}
+	for _, r := range results {
+		res.Results = append(res.Results, SearchResultRes{TaskRes: NewTaskRes(r.Task), Score: r.Score})
+	}
+	return res
 }
 
 type ErrorDTO struct {
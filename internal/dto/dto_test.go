@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -134,3 +135,84 @@ func TestErrorDTO_ToString(t *testing.T) {
 		t.Error("no message")
 	}
 }
+
+func TestCreateTaskReq_ValidateForCreate_Schedule(t *testing.T) {
+	valid := []CreateTaskReq{
+		{Headline: "H", Description: "D", Priority: "urgent"},
+		{Headline: "H", Description: "D", DueAt: "2025-03-01T18:00:00+03:00"},
+		{Headline: "H", Description: "D", DueAt: "2025-03-01T18:00:00", DueTimezone: "Europe/Moscow"},
+		{Headline: "H", Description: "D", DueAt: "2025-03-01", DueTimezone: "Asia/Yekaterinburg"},
+		{Headline: "H", Description: "D", DueAt: "2025-03-01", DueTimezone: "Europe/Moscow", Recurrence: "RRULE:freq=weekly;byday=mo"},
+	}
+	for _, req := range valid {
+		if err := req.ValidateForCreate(); err != nil {
+			t.Errorf("%+v: error: %v", req, err)
+		}
+	}
+
+	invalid := []CreateTaskReq{
+		{Headline: "H", Description: "D", Priority: "asap"},
+		{Headline: "H", Description: "D", DueAt: "tomorrow"},
+		{Headline: "H", Description: "D", DueAt: "2025-03-01T18:00:00"},
+		{Headline: "H", Description: "D", DueAt: "2025-03-01", DueTimezone: "Mars/Olympus"},
+		{Headline: "H", Description: "D", DueTimezone: "Europe/Moscow"},
+		{Headline: "H", Description: "D", ParentID: new(int)},
+		{Headline: "H", Description: "D", Tags: []string{"-blocked"}},
+		{Headline: "H", Description: "D", BlockedBy: []int{1, -2}},
+		{Headline: "H", Description: "D", Recurrence: "FREQ=DAILY"},
+		{Headline: "H", Description: "D", DueAt: "2025-03-01T18:00:00Z", Recurrence: "FREQ=DAILY;COUNT=0"},
+	}
+	for _, req := range invalid {
+		if err := req.ValidateForCreate(); !errors.Is(err, domain.ErrInvalidRequest) {
+			t.Errorf("%+v: want ErrInvalidRequest, got %v", req, err)
+		}
+	}
+}
+
+func TestCreateTaskReq_ToInput_LocalDue(t *testing.T) {
+	req := CreateTaskReq{Headline: "H", Description: "D", DueAt: "2025-03-01", DueTimezone: "Europe/Moscow"}
+
+	input := req.ToInput()
+	if input.Priority != domain.PriorityNormal {
+		t.Errorf("priority: %s", input.Priority)
+	}
+	if input.DueAt == nil {
+		t.Fatal("due_at nil")
+	}
+	// Конец 1 марта по Москве — 20:59:59 UTC
+	want := time.Date(2025, 3, 1, 20, 59, 59, 0, time.UTC)
+	if !input.DueAt.Equal(want) {
+		t.Errorf("due_at: %v != %v", input.DueAt, want)
+	}
+}
+
+func TestCreateTaskReq_ToInput_CanonicalRecurrence(t *testing.T) {
+	req := CreateTaskReq{Headline: "H", Description: "D", DueAt: "2025-03-01T18:00:00Z", Recurrence: "rrule:byday=mo,we;freq=weekly"}
+	if got := req.ToInput().Recurrence; got != "FREQ=WEEKLY;BYDAY=MO,WE" {
+		t.Errorf("recurrence: %s", got)
+	}
+}
+
+func TestNewTaskRes_DerivedDueFields(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	dueAt := now.Add(-time.Hour)
+	task := domain.NewTask(1, "H", "D")
+	task.DueAt = &dueAt
+	task.DueTimezone = "Europe/Moscow"
+
+	res := newTaskRes(task, now)
+	if !res.Overdue {
+		t.Error("overdue != true")
+	}
+	if res.DueIn == nil || *res.DueIn != -3600 {
+		t.Errorf("due_in: %v", res.DueIn)
+	}
+	if res.DueAt.Location().String() != "Europe/Moscow" {
+		t.Errorf("location: %s", res.DueAt.Location())
+	}
+
+	task.Done = true
+	if newTaskRes(task, now).Overdue {
+		t.Error("done task overdue")
+	}
+}
@@ -0,0 +1,362 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// Документы патчей разбираются в значения encoding/json (map[string]any,
+// []any, string, json.Number, bool, nil); числа остаются json.Number, чтобы
+// не терять точность ID.
+
+func decodeValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+// mergePatch применяет патч к документу по RFC 7396: null удаляет поле,
+// объекты сливаются рекурсивно, остальные значения заменяются целиком.
+func mergePatch(doc any, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	d, ok := doc.(map[string]any)
+	if !ok {
+		d = make(map[string]any)
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(d, key)
+			continue
+		}
+		d[key] = mergePatch(d[key], value)
+	}
+	return d
+}
+
+// patchOp — операция JSON Patch (RFC 6902).
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+
+	path  []string
+	from  []string
+	value any
+}
+
+// parsePatchOps разбирает и проверяет документ JSON Patch. Ошибки в
+// структуре операций возвращаются как domain.ErrInvalidRequest.
+func parsePatchOps(body []byte) ([]patchOp, error) {
+	var ops []patchOp
+	if err := json.Unmarshal(body, &ops); err != nil {
+		return nil, err
+	}
+	for i := range ops {
+		op := &ops[i]
+		var err error
+		if op.path, err = parsePointer(op.Path); err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", domain.ErrInvalidRequest, i, err)
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, fmt.Errorf("%w: operation %d: %s requires value", domain.ErrInvalidRequest, i, op.Op)
+			}
+			if op.value, err = decodeValue(op.Value); err != nil {
+				return nil, fmt.Errorf("%w: operation %d: %v", domain.ErrInvalidRequest, i, err)
+			}
+		case "remove":
+		case "move", "copy":
+			if op.from, err = parsePointer(op.From); err != nil {
+				return nil, fmt.Errorf("%w: operation %d: %v", domain.ErrInvalidRequest, i, err)
+			}
+		default:
+			return nil, fmt.Errorf("%w: operation %d: unknown op %q", domain.ErrInvalidRequest, i, op.Op)
+		}
+	}
+	return ops, nil
+}
+
+// parsePointer разбирает JSON Pointer (RFC 6901). Пустая строка указывает
+// на весь документ.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid pointer %q", pointer)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func formatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// applyPatchOps применяет операции по очереди. Если операцию нельзя
+// применить к документу (нет пути, не прошёл test), возвращается
+// domain.ErrPatchConflict.
+func applyPatchOps(doc any, ops []patchOp) (any, error) {
+	for i, op := range ops {
+		var err error
+		switch op.Op {
+		case "add":
+			doc, err = addValue(doc, op.path, op.value)
+		case "remove":
+			doc, _, err = removeValue(doc, op.path)
+		case "replace":
+			if len(op.path) == 0 {
+				doc = op.value
+				break
+			}
+			if doc, _, err = removeValue(doc, op.path); err == nil {
+				doc, err = addValue(doc, op.path, op.value)
+			}
+		case "move":
+			if isPrefix(op.from, op.path) && len(op.from) < len(op.path) {
+				err = fmt.Errorf("cannot move %s into itself", formatPointer(op.from))
+				break
+			}
+			var value any
+			if doc, value, err = removeValue(doc, op.from); err == nil {
+				doc, err = addValue(doc, op.path, value)
+			}
+		case "copy":
+			var value any
+			if value, err = getValue(doc, op.from); err == nil {
+				doc, err = addValue(doc, op.path, copyValue(value))
+			}
+		case "test":
+			var value any
+			if value, err = getValue(doc, op.path); err == nil && !equalValues(value, op.value) {
+				err = fmt.Errorf("test failed at %s", formatPointer(op.path))
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", domain.ErrPatchConflict, i, err)
+		}
+	}
+	return doc, nil
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func getValue(doc any, path []string) (any, error) {
+	for i, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", formatPointer(path[:i+1]))
+			}
+			doc = value
+		case []any:
+			idx, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", formatPointer(path[:i+1]), err)
+			}
+			doc = node[idx]
+		default:
+			return nil, fmt.Errorf("%s not found", formatPointer(path[:i+1]))
+		}
+	}
+	return doc, nil
+}
+
+// modify находит контейнер, в котором лежит последний элемент path, и
+// заменяет его результатом fn. Массивы при изменении длины пересоздаются,
+// поэтому новый контейнер записывается обратно в родителя.
+func modify(doc any, path []string, fn func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	child, err := getValue(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = modify(child, path[1:], fn); err != nil {
+		return nil, err
+	}
+	switch node := doc.(type) {
+	case map[string]any:
+		node[path[0]] = child
+	case []any:
+		idx, _ := arrayIndex(path[0], len(node)-1)
+		node[idx] = child
+	}
+	return doc, nil
+}
+
+func addValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(container any, token string) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			if token == "-" {
+				return append(node, value), nil
+			}
+			idx, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", formatPointer(path), err)
+			}
+			node = append(node, nil)
+			copy(node[idx+1:], node[idx:])
+			node[idx] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("%s: parent is not a container", formatPointer(path))
+		}
+	})
+}
+
+func removeValue(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+	var removed any
+	doc, err := modify(doc, path, func(container any, token string) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", formatPointer(path))
+			}
+			removed = value
+			delete(node, token)
+			return node, nil
+		case []any:
+			idx, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", formatPointer(path), err)
+			}
+			removed = node[idx]
+			return append(node[:idx:idx], node[idx+1:]...), nil
+		default:
+			return nil, fmt.Errorf("%s not found", formatPointer(path))
+		}
+	})
+	return doc, removed, err
+}
+
+// arrayIndex разбирает индекс массива без ведущих нулей и проверяет, что он
+// не больше last.
+func arrayIndex(token string, last int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if idx > last {
+		return 0, fmt.Errorf("array index %d out of range", idx)
+	}
+	return idx, nil
+}
+
+func copyValue(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(node))
+		for key, value := range node {
+			c[key] = copyValue(value)
+		}
+		return c
+	case []any:
+		c := make([]any, len(node))
+		for i, value := range node {
+			c[i] = copyValue(value)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// equalValues сравнивает JSON-значения; числа сравниваются по значению,
+// а не по записи.
+func equalValues(a any, b any) bool {
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !equalValues(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalValues(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if x == y {
+			return true
+		}
+		fx, errX := x.Float64()
+		fy, errY := y.Float64()
+		return errX == nil && errY == nil && fx == fy
+	default:
+		return a == b
+	}
+}
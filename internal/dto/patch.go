@@ -0,0 +1,102 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// TaskPatch — частичное изменение содержимого задачи. Патч применяется к
+// документу с полями тела PUT /todos/{id}, а результат проверяется по тем
+// же правилам, что и PUT.
+type TaskPatch struct {
+	apply func(doc any) (any, error)
+}
+
+// ParseMergePatch разбирает JSON Merge Patch (RFC 7396).
+func ParseMergePatch(body []byte) (*TaskPatch, error) {
+	patch, err := decodeValue(body)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := patch.(map[string]any); !ok {
+		return nil, fmt.Errorf("%w: merge patch must be a JSON object", domain.ErrInvalidRequest)
+	}
+	return &TaskPatch{apply: func(doc any) (any, error) {
+		return mergePatch(doc, patch), nil
+	}}, nil
+}
+
+// ParseJSONPatch разбирает JSON Patch (RFC 6902). Ошибки в операциях
+// возвращаются как domain.ErrInvalidRequest.
+func ParseJSONPatch(body []byte) (*TaskPatch, error) {
+	ops, err := parsePatchOps(body)
+	if err != nil {
+		return nil, err
+	}
+	return &TaskPatch{apply: func(doc any) (any, error) {
+		return applyPatchOps(doc, ops)
+	}}, nil
+}
+
+// Apply применяет патч к задаче и возвращает её новое содержимое. Если
+// операцию нельзя применить, возвращается domain.ErrPatchConflict, если
+// результат не проходит проверку — domain.ErrInvalidRequest.
+func (p *TaskPatch) Apply(task *domain.Task) (domain.TaskInput, error) {
+	doc, err := taskDocument(task)
+	if err != nil {
+		return domain.TaskInput{}, err
+	}
+	if doc, err = p.apply(doc); err != nil {
+		return domain.TaskInput{}, err
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return domain.TaskInput{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var req UpdateTaskReq
+	if err := dec.Decode(&req); err != nil {
+		return domain.TaskInput{}, fmt.Errorf("%w: patched task: %v", domain.ErrInvalidRequest, err)
+	}
+	if err := req.ValidateForUpdate(); err != nil {
+		return domain.TaskInput{}, err
+	}
+	return req.ToInput(), nil
+}
+
+// taskDocument возвращает содержимое задачи в виде тела PUT-запроса.
+func taskDocument(task *domain.Task) (any, error) {
+	req := UpdateTaskReq{
+		Headline:    task.Headline,
+		Description: task.Description,
+		Priority:    string(task.Priority.OrDefault()),
+		DueTimezone: task.DueTimezone,
+		Tags:        task.Tags,
+		ParentID:    task.ParentID,
+		BlockedBy:   task.BlockedBy,
+		Recurrence:  task.Recurrence,
+	}
+	if task.DueAt != nil {
+		req.DueAt = task.DueAt.In(task.DueLocation()).Format(time.RFC3339)
+	}
+	// Пустые списки оставляем массивами, чтобы в них можно было добавлять
+	// элементы через /tags/-
+	if req.Tags == nil {
+		req.Tags = []string{}
+	}
+	if req.BlockedBy == nil {
+		req.BlockedBy = []int{}
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValue(data)
+}
@@ -0,0 +1,133 @@
+package dto
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func patchTestTask() *domain.Task {
+	due := time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)
+	task := domain.NewTask(1, "Отчёт", "Квартальный")
+	task.Priority = domain.PriorityHigh
+	task.DueAt = &due
+	task.Tags = []string{"backend"}
+	return task
+}
+
+func TestMergePatch(t *testing.T) {
+	patch, err := ParseMergePatch([]byte(`{"description": "Годовой", "due_at": null, "tags": ["ops"]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	input, err := patch.Apply(patchTestTask())
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if input.Headline != "Отчёт" || input.Description != "Годовой" {
+		t.Errorf("content: %q %q", input.Headline, input.Description)
+	}
+	if input.Priority != domain.PriorityHigh {
+		t.Errorf("priority = %s, want high", input.Priority)
+	}
+	if input.DueAt != nil {
+		t.Errorf("due_at = %v, want nil", input.DueAt)
+	}
+	if !slices.Equal(input.Tags, []string{"ops"}) {
+		t.Errorf("tags = %v", input.Tags)
+	}
+}
+
+func TestMergePatchValidation(t *testing.T) {
+	for _, body := range []string{
+		`{"headline": null}`,
+		`{"priority": "someday"}`,
+		`{"status": "done"}`,
+		`{"tags": "ops"}`,
+	} {
+		patch, err := ParseMergePatch([]byte(body))
+		if err != nil {
+			t.Fatalf("parse %s: %v", body, err)
+		}
+		if _, err := patch.Apply(patchTestTask()); !errors.Is(err, domain.ErrInvalidRequest) {
+			t.Errorf("%s: want ErrInvalidRequest, got %v", body, err)
+		}
+	}
+
+	if _, err := ParseMergePatch([]byte(`["headline"]`)); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("array patch: want ErrInvalidRequest, got %v", err)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	patch, err := ParseJSONPatch([]byte(`[
+		{"op": "test", "path": "/headline", "value": "Отчёт"},
+		{"op": "add", "path": "/tags/-", "value": "urgent"},
+		{"op": "add", "path": "/tags/0", "value": "api"},
+		{"op": "copy", "from": "/headline", "path": "/description"},
+		{"op": "replace", "path": "/priority", "value": "low"},
+		{"op": "move", "from": "/tags/2", "path": "/tags/1"}
+	]`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	input, err := patch.Apply(patchTestTask())
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if input.Description != "Отчёт" || input.Priority != domain.PriorityLow {
+		t.Errorf("content: %q %s", input.Description, input.Priority)
+	}
+	if !slices.Equal(input.Tags, []string{"api", "backend", "urgent"}) {
+		t.Errorf("tags = %v", input.Tags)
+	}
+	if input.DueAt == nil || !input.DueAt.Equal(time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("due_at = %v", input.DueAt)
+	}
+}
+
+func TestJSONPatchConflict(t *testing.T) {
+	for _, body := range []string{
+		`[{"op": "test", "path": "/headline", "value": "Другой"}]`,
+		`[{"op": "remove", "path": "/tags/5"}]`,
+		`[{"op": "replace", "path": "/missing", "value": 1}]`,
+		`[{"op": "move", "from": "/tags", "path": "/tags/0"}]`,
+	} {
+		patch, err := ParseJSONPatch([]byte(body))
+		if err != nil {
+			t.Fatalf("parse %s: %v", body, err)
+		}
+		if _, err := patch.Apply(patchTestTask()); !errors.Is(err, domain.ErrPatchConflict) {
+			t.Errorf("%s: want ErrPatchConflict, got %v", body, err)
+		}
+	}
+}
+
+func TestJSONPatchInvalid(t *testing.T) {
+	for _, body := range []string{
+		`[{"op": "rename", "path": "/headline"}]`,
+		`[{"op": "add", "path": "/headline"}]`,
+		`[{"op": "remove", "path": "headline"}]`,
+		`[{"op": "remove", "path": "/a~2b"}]`,
+	} {
+		if _, err := ParseJSONPatch([]byte(body)); !errors.Is(err, domain.ErrInvalidRequest) {
+			t.Errorf("%s: want ErrInvalidRequest, got %v", body, err)
+		}
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	tokens, err := parsePointer("/a~1b/c~0d/0")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !slices.Equal(tokens, []string{"a/b", "c~d", "0"}) {
+		t.Errorf("tokens = %q", tokens)
+	}
+	if got := formatPointer(tokens); got != "/a~1b/c~0d/0" {
+		t.Errorf("format = %q", got)
+	}
+}
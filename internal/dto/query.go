@@ -0,0 +1,152 @@
+package dto
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+const maxListLimit = 500
+
+// taskQueryParams — параметры, которые понимает ParseTaskQuery.
+var taskQueryParams = map[string]bool{
+	"done": true, "status": true, "headline": true, "priority": true,
+	"created_after": true, "created_before": true, "completed_after": true, "completed_before": true,
+	"due_before": true, "overdue": true, "blocked": true, "archived": true, "tag": true,
+	"sort": true, "limit": true, "cursor": true,
+}
+
+// ParseTaskQuery разбирает параметры GET /todos:
+//
+//	done=true|false
+//	status — один или несколько статусов через запятую
+//	created_after, created_before, completed_after, completed_before (RFC 3339)
+//	headline — подстрока заголовка без учёта регистра
+//	priority — один или несколько приоритетов через запятую
+//	due_before (RFC 3339), overdue=true|false
+//	blocked=true|false — задачи, ждущие невыполненных блокирующих задач
+//	archived=false|true|all — задачи вне архива (по умолчанию), в архиве или все
+//	tag — условие по тегам, можно повторять: "a,b" — любой из тегов, "-a" — без тега
+//	sort — поля через запятую, "-" перед полем означает убывание
+//	limit, cursor — размер страницы и курсор из next_cursor
+func ParseTaskQuery(values url.Values) (domain.TaskQuery, error) {
+	var query domain.TaskQuery
+	var err error
+
+	if v := values.Get("done"); v != "" {
+		done, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, invalidParam("done")
+		}
+		query.Filter.Done = &done
+	}
+
+	timeParams := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_after", &query.Filter.CreatedAfter},
+		{"created_before", &query.Filter.CreatedBefore},
+		{"completed_after", &query.Filter.CompletedAfter},
+		{"completed_before", &query.Filter.CompletedBefore},
+		{"due_before", &query.Filter.DueBefore},
+	}
+	for _, p := range timeParams {
+		if *p.dst, err = parseTimeParam(values, p.name); err != nil {
+			return query, err
+		}
+	}
+
+	query.Filter.HeadlineContains = values.Get("headline")
+
+	if v := values.Get("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, invalidParam("overdue")
+		}
+		query.Filter.Overdue = &overdue
+	}
+
+	archived := false
+	query.Filter.Archived = &archived
+	switch v := values.Get("archived"); v {
+	case "":
+	case "all":
+		query.Filter.Archived = nil
+	default:
+		if archived, err = strconv.ParseBool(v); err != nil {
+			return query, invalidParam("archived")
+		}
+	}
+
+	if v := values.Get("blocked"); v != "" {
+		blocked, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, invalidParam("blocked")
+		}
+		query.Filter.Blocked = &blocked
+	}
+
+	if v := values.Get("status"); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			status, err := domain.ParseStatus(strings.TrimSpace(part))
+			if err != nil {
+				return query, err
+			}
+			query.Filter.Statuses = append(query.Filter.Statuses, status)
+		}
+	}
+
+	if v := values.Get("priority"); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			priority, err := domain.ParsePriority(strings.TrimSpace(part))
+			if err != nil {
+				return query, err
+			}
+			query.Filter.Priorities = append(query.Filter.Priorities, priority)
+		}
+	}
+
+	for _, v := range values["tag"] {
+		clause, err := domain.ParseTagClause(v)
+		if err != nil {
+			return query, err
+		}
+		query.Filter.Tags = append(query.Filter.Tags, clause)
+	}
+
+	if query.Sort, err = domain.ParseSort(values.Get("sort")); err != nil {
+		return query, err
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxListLimit {
+			return query, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidRequest, maxListLimit)
+		}
+		query.Limit = limit
+	}
+
+	query.Cursor = values.Get("cursor")
+	return query, nil
+}
+
+func parseTimeParam(values url.Values, name string) (*time.Time, error) {
+	v := values.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an RFC 3339 timestamp", domain.ErrInvalidRequest, name)
+	}
+	return &t, nil
+}
+
+func invalidParam(name string) error {
+	return fmt.Errorf("%w: invalid %s", domain.ErrInvalidRequest, name)
+}
@@ -0,0 +1,127 @@
+package dto
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func TestParseTaskQuerySuccess(t *testing.T) {
+	values := url.Values{
+		"done":            {"false"},
+		"created_after":   {"2025-01-01T00:00:00Z"},
+		"completed_after": {"2025-01-02T00:00:00+03:00"},
+		"headline":        {"отчёт"},
+		"sort":            {"-created_at,headline"},
+		"limit":           {"20"},
+		"cursor":          {"abc"},
+	}
+
+	query, err := ParseTaskQuery(values)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if query.Filter.Done == nil || *query.Filter.Done {
+		t.Error("done filter")
+	}
+	if query.Filter.CreatedAfter == nil || query.Filter.CompletedAfter == nil {
+		t.Error("time filters")
+	}
+	if query.Filter.HeadlineContains != "отчёт" {
+		t.Errorf("headline: %s", query.Filter.HeadlineContains)
+	}
+	if len(query.Sort) != 2 || !query.Sort[0].Desc || query.Sort[1].Field != domain.SortByHeadline {
+		t.Errorf("sort: %v", query.Sort)
+	}
+	if query.Limit != 20 || query.Cursor != "abc" {
+		t.Errorf("paging: %d %s", query.Limit, query.Cursor)
+	}
+}
+
+func TestParseTaskQueryTags(t *testing.T) {
+	query, err := ParseTaskQuery(url.Values{"tag": {"Backend,frontend", "-blocked"}})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	tags := query.Filter.Tags
+	if len(tags) != 2 {
+		t.Fatalf("len != 2: %d", len(tags))
+	}
+	if tags[0].Negate || len(tags[0].AnyOf) != 2 || tags[0].AnyOf[0] != "backend" {
+		t.Errorf("first clause: %+v", tags[0])
+	}
+	if !tags[1].Negate || len(tags[1].AnyOf) != 1 || tags[1].AnyOf[0] != "blocked" {
+		t.Errorf("second clause: %+v", tags[1])
+	}
+}
+
+func TestParseTaskQueryStatus(t *testing.T) {
+	query, err := ParseTaskQuery(url.Values{"status": {"todo, in_progress"}})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	want := []domain.Status{domain.StatusTodo, domain.StatusInProgress}
+	if !slices.Equal(query.Filter.Statuses, want) {
+		t.Errorf("statuses: %v", query.Filter.Statuses)
+	}
+}
+
+func TestParseTaskQueryArchived(t *testing.T) {
+	query, err := ParseTaskQuery(url.Values{"archived": {"true"}})
+	if err != nil || query.Filter.Archived == nil || !*query.Filter.Archived {
+		t.Errorf("archived=true: %v %v", query.Filter.Archived, err)
+	}
+	query, err = ParseTaskQuery(url.Values{"archived": {"all"}})
+	if err != nil || query.Filter.Archived != nil {
+		t.Errorf("archived=all: %v %v", query.Filter.Archived, err)
+	}
+}
+
+func TestParseTaskQueryDefaults(t *testing.T) {
+	query, err := ParseTaskQuery(url.Values{})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if query.Filter.Done != nil {
+		t.Error("done set")
+	}
+	if query.Filter.Archived == nil || *query.Filter.Archived {
+		t.Error("archived tasks are listed by default")
+	}
+	if len(query.Sort) != 1 || query.Sort[0].Field != domain.SortByCreatedAt {
+		t.Errorf("sort: %v", query.Sort)
+	}
+	if query.Limit != 0 {
+		t.Errorf("limit: %d", query.Limit)
+	}
+}
+
+func TestParseTaskQueryInvalid(t *testing.T) {
+	cases := []url.Values{
+		{"done": {"maybe"}},
+		{"created_before": {"yesterday"}},
+		{"sort": {"urgency"}},
+		{"priority": {"high,asap"}},
+		{"overdue": {"yes"}},
+		{"due_before": {"soon"}},
+		{"blocked": {"perhaps"}},
+		{"status": {"todo,finished"}},
+		{"archived": {"some"}},
+		{"tag": {"-"}},
+		{"tag": {"backend,"}},
+		{"tag": {"bad tag"}},
+		{"sort": {"id,-id"}},
+		{"limit": {"0"}},
+		{"limit": {"501"}},
+		{"limit": {"ten"}},
+	}
+	for _, values := range cases {
+		_, err := ParseTaskQuery(values)
+		if !errors.Is(err, domain.ErrInvalidRequest) {
+			t.Errorf("%v: want ErrInvalidRequest, got %v", values, err)
+		}
+	}
+}
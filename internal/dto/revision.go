@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+type RevisionRes struct {
+	Number   int                  `json:"number"`
+	Actor    string               `json:"actor"`
+	Action   string               `json:"action"`
+	RevertOf int                  `json:"revert_of,omitempty"`
+	At       time.Time            `json:"at"`
+	Changes  []domain.FieldChange `json:"changes"`
+}
+
+func NewRevisionRes(rev *domain.Revision) RevisionRes {
+	res := RevisionRes{
+		Number:   rev.Number,
+		Actor:    rev.Actor,
+		Action:   rev.Action,
+		RevertOf: rev.RevertOf,
+		At:       rev.At,
+		Changes:  rev.Changes,
+	}
+	if res.Changes == nil {
+		res.Changes = []domain.FieldChange{}
+	}
+	return res
+}
+
+type HistoryRes struct {
+	TaskID    int           `json:"task_id"`
+	Revisions []RevisionRes `json:"revisions"`
+}
+
+func NewHistoryRes(taskID int, revisions []*domain.Revision) HistoryRes {
+	res := HistoryRes{TaskID: taskID, Revisions: make([]RevisionRes, 0, len(revisions))}
+	for _, rev := range revisions {
+		res.Revisions = append(res.Revisions, NewRevisionRes(rev))
+	}
+	return res
+}
+
+// TaskRevisionRes — задача в том виде, в каком она была после ревизии.
+// Вычисляемые поля вроде overdue считаются на момент ревизии.
+type TaskRevisionRes struct {
+	Revision RevisionRes `json:"revision"`
+	Task     TaskRes     `json:"task"`
+}
+
+func NewTaskRevisionRes(rev *domain.Revision) TaskRevisionRes {
+	return TaskRevisionRes{
+		Revision: NewRevisionRes(rev),
+		Task:     newTaskRes(rev.Snapshot, rev.At),
+	}
+}
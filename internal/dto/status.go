@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// TransitionReq переводит задачу в другой статус. Cascade и Force имеют
+// тот же смысл, что и при завершении задачи.
+type TransitionReq struct {
+	Status  string `json:"status"`
+	Cascade bool   `json:"cascade"`
+	Force   bool   `json:"force"`
+}
+
+func (r TransitionReq) Validate() error {
+	if r.Status == "" {
+		return fmt.Errorf("%w: status is required", domain.ErrInvalidRequest)
+	}
+	_, err := domain.ParseStatus(r.Status)
+	return err
+}
+
+func (r TransitionReq) Options() domain.CompleteOptions {
+	return domain.CompleteOptions{Cascade: r.Cascade, Force: r.Force}
+}
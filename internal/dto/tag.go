@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+type TagListRes struct {
+	Tags []domain.TagCount `json:"tags"`
+}
+
+func NewTagListRes(tags []domain.TagCount) TagListRes {
+	if tags == nil {
+		tags = []domain.TagCount{}
+	}
+	return TagListRes{Tags: tags}
+}
+
+// RenameTagReq переименовывает тег. Если тег с новым именем уже есть,
+// теги сливаются.
+type RenameTagReq struct {
+	Name string `json:"name"`
+}
+
+func (r RenameTagReq) Validate() error {
+	_, err := domain.NormalizeTag(r.Name)
+	return err
+}
+
+// MergeTagsReq заменяет теги Sources на Target во всех задачах.
+type MergeTagsReq struct {
+	Sources []string `json:"sources"`
+	Target  string   `json:"target"`
+}
+
+func (r MergeTagsReq) Validate() error {
+	if len(r.Sources) == 0 {
+		return fmt.Errorf("%w: sources must not be empty", domain.ErrInvalidRequest)
+	}
+	for _, tag := range r.Sources {
+		if _, err := domain.NormalizeTag(tag); err != nil {
+			return err
+		}
+	}
+	_, err := domain.NormalizeTag(r.Target)
+	return err
+}
+
+type TagChangeRes struct {
+	Tag          string `json:"tag"`
+	UpdatedTasks int    `json:"updated_tasks"`
+}
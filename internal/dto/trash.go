@@ -0,0 +1,19 @@
+package dto
+
+import "github.com/S1FFFkA/todo-list/internal/domain"
+
+type TrashRes struct {
+	Tasks []TaskRes `json:"tasks"`
+}
+
+func NewTrashRes(tasks []*domain.Task) TrashRes {
+	res := TrashRes{Tasks: make([]TaskRes, 0, len(tasks))}
+	for _, task := range tasks {
+		res.Tasks = append(res.Tasks, NewTaskRes(task))
+	}
+	return res
+}
+
+type PurgeRes struct {
+	Purged int `json:"purged"`
+}
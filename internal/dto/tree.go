@@ -0,0 +1,49 @@
+package dto
+
+import "github.com/S1FFFkA/todo-list/internal/domain"
+
+type TaskChildrenRes struct {
+	ParentID int       `json:"parent_id"`
+	Tasks    []TaskRes `json:"tasks"`
+}
+
+func NewTaskChildrenRes(parentID int, children []*domain.Task) TaskChildrenRes {
+	res := TaskChildrenRes{
+		ParentID: parentID,
+		Tasks:    make([]TaskRes, 0, len(children)),
+	}
+	for _, task := range children {
+		res.Tasks = append(res.Tasks, NewTaskRes(task))
+	}
+	return res
+}
+
+type TaskNodeRes struct {
+	TaskRes
+	Children []TaskNodeRes `json:"children"`
+}
+
+func NewTaskNodeRes(node *domain.TaskNode) TaskNodeRes {
+	res := TaskNodeRes{
+		TaskRes:  NewTaskRes(node.Task),
+		Children: make([]TaskNodeRes, 0, len(node.Children)),
+	}
+	for _, child := range node.Children {
+		res.Children = append(res.Children, NewTaskNodeRes(child))
+	}
+	return res
+}
+
+// TaskOrderRes — невыполненные задачи в порядке выполнения: каждая задача
+// идёт после всех своих блокирующих задач.
+type TaskOrderRes struct {
+	Tasks []TaskRes `json:"tasks"`
+}
+
+func NewTaskOrderRes(tasks []*domain.Task) TaskOrderRes {
+	res := TaskOrderRes{Tasks: make([]TaskRes, 0, len(tasks))}
+	for _, task := range tasks {
+		res.Tasks = append(res.Tasks, NewTaskRes(task))
+	}
+	return res
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "github.com/S1FFFkA/todo-list/internal/domain"
+
+type UndoRes struct {
+	Operation string  `json:"operation"`
+	TaskID    int     `json:"task_id"`
+	Task      TaskRes `json:"task"`
+}
+
+func NewUndoRes(res *domain.UndoResult) UndoRes {
+	return UndoRes{
+		Operation: res.Operation,
+		TaskID:    res.TaskID,
+		Task:      NewTaskRes(res.Task),
+	}
+}
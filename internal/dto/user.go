@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// CreateUserReq — запрос на регистрацию. Password необязателен: без него
+// войти можно только по ключу API.
+type CreateUserReq struct {
+	Name     string `json:"name"`
+	Password string `json:"password,omitempty"`
+}
+
+func (r CreateUserReq) Validate() error {
+	if _, err := domain.NormalizeUserName(r.Name); err != nil {
+		return err
+	}
+	if r.Password != "" {
+		return domain.ValidatePassword(r.Password)
+	}
+	return nil
+}
+
+// UserRes описывает пользователя. APIKey — первый ключ пользователя, он
+// есть только в ответе на регистрацию.
+type UserRes struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	APIKey    *APIKeyRes `json:"api_key,omitempty"`
+}
+
+func NewUserRes(user *domain.User) UserRes {
+	return UserRes{
+		ID:        user.ID,
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func NewCreatedUserRes(user *domain.User, key *domain.APIKey, secret string) UserRes {
+	res := NewUserRes(user)
+	keyRes := NewCreatedAPIKeyRes(key, secret)
+	res.APIKey = &keyRes
+	return res
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+func (h *TaskHandler) ArchiveTask(w http.ResponseWriter, r *http.Request) {
+	h.changeArchive(w, r, "archiving task", h.taskService.ArchiveTask)
+}
+
+func (h *TaskHandler) UnarchiveTask(w http.ResponseWriter, r *http.Request) {
+	h.changeArchive(w, r, "unarchiving task", h.taskService.UnarchiveTask)
+}
+
+func (h *TaskHandler) changeArchive(w http.ResponseWriter, r *http.Request, msg string, change func(ctx context.Context, id int) (*domain.Task, error)) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := h.extractID(r)
+	if err != nil {
+		logger.Logger.Warn("invalid task ID", "error", err.Error())
+		h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info(msg, "task_id", id)
+
+	task, err := change(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			logger.Logger.Warn("task not found", "task_id", id)
+			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+		case errors.Is(err, domain.ErrNotClosed):
+			logger.Logger.Warn("task is not closed", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+		default:
+			logger.Logger.Error("internal server error", "error", err.Error())
+			h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.sendJSON(w, dto.NewTaskRes(task), http.StatusOK)
+}
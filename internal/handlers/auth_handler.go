@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+type AuthService interface {
+	Login(ctx context.Context, name string, password string) (*domain.Session, error)
+	Refresh(ctx context.Context, refreshToken string) (*domain.Session, error)
+	Logout(ctx context.Context, refreshToken string) error
+	ChangePassword(ctx context.Context, current string, password string) error
+}
+
+type AuthHandler struct {
+	authService AuthService
+}
+
+func NewAuthHandler(authService AuthService) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.LoginReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		logger.Logger.Warn("validation error", "error", err.Error())
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.authService.Login(r.Context(), req.Name, req.Password)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	logger.Logger.Info("user logged in", "user_id", session.User.ID)
+	sendTokens(w, session)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.RefreshReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		logger.Logger.Warn("validation error", "error", err.Error())
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrTokenReused) {
+			logger.Logger.Warn("refresh token reuse detected, session revoked", "error", err.Error())
+		}
+		h.sendServiceError(w, err)
+		return
+	}
+
+	sendTokens(w, session)
+}
+
+// Logout принимает пустое тело: тогда отзывается только токен доступа
+// запроса.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.LogoutReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Logger.Info("user logging out", "user_id", domain.UserID(r.Context()))
+
+	if err := h.authService.Logout(r.Context(), req.RefreshToken); err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.ChangePasswordReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		logger.Logger.Warn("validation error", "error", err.Error())
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("changing password", "user_id", domain.UserID(r.Context()))
+
+	if err := h.authService.ChangePassword(r.Context(), req.CurrentPassword, req.NewPassword); err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) sendServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUnauthorized):
+		logger.Logger.Warn("unauthorized", "error", err.Error())
+		SendUnauthorized(w, err.Error())
+	case errors.Is(err, domain.ErrInvalidRequest):
+		logger.Logger.Warn("validation error", "error", err.Error())
+		sendError(w, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Logger.Error("internal server error", "error", err.Error())
+		sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+	}
+}
+
+// sendTokens отвечает парой токенов. Ответ с токенами нельзя кешировать.
+func sendTokens(w http.ResponseWriter, session *domain.Session) {
+	w.Header().Set("Cache-Control", "no-store")
+	sendJSON(w, dto.NewTokenRes(session, time.Now()), http.StatusOK)
+}
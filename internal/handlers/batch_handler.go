@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+func (h *TaskHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.BatchReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		h.sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ops, err := req.ToOps()
+	if err != nil {
+		logger.Logger.Warn("validation error", "error", err.Error())
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("executing batch", "operations", len(ops), "atomic", req.Atomic())
+
+	results, err := h.taskService.Batch(r.Context(), ops, req.Atomic())
+	if err != nil {
+		logger.Logger.Error("internal server error", "error", err.Error())
+		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	kinds := make([]string, len(ops))
+	for i, op := range ops {
+		kinds[i] = op.Kind
+	}
+	items, statusCode := batchResults(kinds, results, req.Atomic())
+	h.sendJSON(w, dto.NewBatchRes(req.Atomic(), items), statusCode)
+}
+
+// batchResults переводит итоги пакета в ответ. Атомарный пакет с ошибкой
+// отвечает кодом упавшей операции, в остальных случаях код ответа — 200.
+func batchResults(kinds []string, results []domain.BatchResult, atomic bool) ([]dto.BatchResultRes, int) {
+	items := make([]dto.BatchResultRes, len(results))
+	statusCode := http.StatusOK
+	for i, result := range results {
+		status, message := batchStatus(kinds[i], result.Err)
+		if result.Err != nil && !errors.Is(result.Err, domain.ErrBatchAborted) {
+			logger.Logger.Warn("batch operation failed", "index", i, "op", kinds[i], "task_id", result.TaskID, "error", result.Err.Error())
+			if atomic {
+				statusCode = status
+			}
+		}
+		items[i] = dto.NewBatchResultRes(i, kinds[i], result, status, message)
+	}
+	return items, statusCode
+}
+
+// batchStatus возвращает код и сообщение, с которыми завершился бы
+// отдельный запрос с операцией пакета.
+func batchStatus(kind string, err error) (int, string) {
+	switch {
+	case err == nil:
+		switch kind {
+		case domain.BatchCreate:
+			return http.StatusCreated, ""
+		case domain.BatchDelete:
+			return http.StatusNoContent, ""
+		}
+		return http.StatusOK, ""
+	case errors.Is(err, domain.ErrBatchAborted):
+		return http.StatusFailedDependency, err.Error()
+	case errors.Is(err, domain.ErrInvalidRequest):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, domain.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, err.Error()
+	case errors.Is(err, domain.ErrCycle), errors.Is(err, domain.ErrDependencyCycle),
+		errors.Is(err, domain.ErrBlocked), errors.Is(err, domain.ErrInvalidTransition),
+		errors.Is(err, domain.ErrHasSubtasks), errors.Is(err, domain.ErrParentDeleted),
+		errors.Is(err, domain.ErrPatchConflict):
+		return http.StatusConflict, err.Error()
+	default:
+		logger.Logger.Error("internal server error", "error", err.Error())
+		return http.StatusInternalServerError, domain.ErrInternalError.Error()
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+func (h *TaskHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body dto.BulkReq
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		h.sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	req, err := body.ToRequest(r.URL.Query())
+	if err != nil {
+		logger.Logger.Warn("validation error", "error", err.Error())
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("executing bulk operation", "op", req.Op.Kind, "query", r.URL.RawQuery, "dry_run", req.DryRun)
+
+	res, err := h.taskService.Bulk(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRequest) {
+			logger.Logger.Warn("validation error", "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.Logger.Error("internal server error", "error", err.Error())
+		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var items []dto.BatchResultRes
+	statusCode := http.StatusOK
+	if res.Results != nil {
+		kinds := make([]string, len(res.Results))
+		for i := range kinds {
+			kinds[i] = req.Op.Kind
+		}
+		items, statusCode = batchResults(kinds, res.Results, req.Atomic)
+	}
+
+	h.sendJSON(w, dto.NewBulkRes(req, res, items), statusCode)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+)
+
+// taskETag возвращает метку представления задачи: версию и хеш ответа.
+// Версия нужна для If-Match, а хеш меняется вместе с вычисляемыми полями —
+// прогрессом подзадач, open_blockers, overdue и due_in, — которые меняются
+// без новой версии.
+func taskETag(res dto.TaskRes) string {
+	b, err := json.Marshal(res)
+	if err != nil {
+		return `"` + strconv.Itoa(res.Version) + `"`
+	}
+	sum := sha256.Sum256(b)
+	return `"` + strconv.Itoa(res.Version) + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// setETag отдаёт метку представления задачи в заголовке ETag.
+func (h *TaskHandler) setETag(w http.ResponseWriter, res dto.TaskRes) string {
+	etag := taskETag(res)
+	w.Header().Set("ETag", etag)
+	return etag
+}
+
+// withIfMatch переносит версии из заголовка If-Match в контекст запроса,
+// чтобы сервис проверил их под своей блокировкой. Сравнивается только
+// версия: хеш отражает вычисляемые поля, которые запрос клиента не
+// перезаписывает, поэтому "<версия>-<любой хеш>" проходит проверку. "*"
+// подходит к любой версии, а слабые метки (W/"...") при сравнении для
+// If-Match не совпадают ни с чем.
+func (h *TaskHandler) withIfMatch(r *http.Request) *http.Request {
+	tags, wildcard := h.entityTags(r, "If-Match")
+	if tags == nil || wildcard {
+		return r
+	}
+	versions := []int{}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if version, ok := parseETag(tag); ok {
+			versions = append(versions, version)
+		}
+	}
+	return r.WithContext(domain.WithIfMatch(r.Context(), versions))
+}
+
+// notModified сообщает, что по заголовку If-None-Match у клиента уже есть
+// текущее представление задачи с меткой etag. Метки сравниваются целиком,
+// без учёта W/: одной версии мало, вычисляемые поля могли измениться.
+func (h *TaskHandler) notModified(r *http.Request, etag string) bool {
+	tags, wildcard := h.entityTags(r, "If-None-Match")
+	if wildcard {
+		return true
+	}
+	for _, tag := range tags {
+		if strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// entityTags разбирает список меток из заголовка name; wildcard — в заголовке "*".
+// Для запроса без заголовка возвращается nil.
+func (h *TaskHandler) entityTags(r *http.Request, name string) (tags []string, wildcard bool) {
+	for _, value := range r.Header.Values(name) {
+		for tag := range strings.SplitSeq(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" {
+				wildcard = true
+			}
+			tags = append(tags, tag)
+		}
+	}
+	return tags, wildcard
+}
+
+// parseETag возвращает версию из метки "<версия>-<хеш>".
+func parseETag(tag string) (int, bool) {
+	unquoted, ok := strings.CutPrefix(tag, `"`)
+	if !ok {
+		return 0, false
+	}
+	unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	if !ok {
+		return 0, false
+	}
+	unquoted, hash, ok := strings.Cut(unquoted, "-")
+	if !ok || hash == "" {
+		return 0, false
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
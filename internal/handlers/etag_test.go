@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository/memory"
+	"github.com/S1FFFkA/todo-list/internal/service"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+func TestETagTracksComputedFields(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	taskService, err := service.NewTaskService(ctx, memory.NewTaskRepository())
+	if err != nil {
+		t.Fatalf("service: %v", err)
+	}
+	h := NewTaskHandler(taskService)
+
+	parent, err := taskService.CreateTask(ctx, domain.TaskInput{Headline: "Parent", Description: "D"})
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	child, err := taskService.CreateTask(ctx, domain.TaskInput{Headline: "Child", Description: "D", ParentID: &parent.ID})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/todos/"+strconv.Itoa(parent.ID), nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		h.GetTask(w, r)
+		return w
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("get: %d, etag %q", first.Code, etag)
+	}
+	if version, ok := parseETag(etag); !ok || version != parent.Version {
+		t.Errorf("etag %q: version %d, %v", etag, version, ok)
+	}
+	if w := get(etag); w.Code != http.StatusNotModified {
+		t.Errorf("unchanged: want 304, got %d", w.Code)
+	}
+
+	// Прогресс родителя меняется, а его версия — нет
+	if _, err := taskService.UpdateTask(ctx, child.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete child: %v", err)
+	}
+	w := get(etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("after progress change: %d, etag %q", w.Code, w.Header().Get("ETag"))
+	}
+	if version, _ := parseETag(w.Header().Get("ETag")); version != parent.Version {
+		t.Errorf("version changed: %d", version)
+	}
+}
+
+// If-Match проверяет только версию из метки, хеш не сравнивается.
+func TestIfMatchChecksVersion(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	taskService, err := service.NewTaskService(ctx, memory.NewTaskRepository())
+	if err != nil {
+		t.Fatalf("service: %v", err)
+	}
+	h := NewTaskHandler(taskService)
+	task, err := taskService.CreateTask(ctx, domain.TaskInput{Headline: "Task", Description: "D"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	complete := func(ifMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/todos/"+strconv.Itoa(task.ID)+"/complete", nil)
+		r.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		h.CompleteTask(w, r)
+		return w
+	}
+	stale := `"` + strconv.Itoa(task.Version+1) + `-0123456789abcdef"`
+	if w := complete(stale); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("other version: want 412, got %d", w.Code)
+	}
+	for _, tag := range []string{`"` + strconv.Itoa(task.Version) + `"`, `W/"` + strconv.Itoa(task.Version) + `-0123456789abcdef"`} {
+		if w := complete(tag); w.Code != http.StatusPreconditionFailed {
+			t.Errorf("%s: want 412, got %d", tag, w.Code)
+		}
+	}
+	otherHash := `"` + strconv.Itoa(task.Version) + `-0123456789abcdef"`
+	if w := complete(otherHash); w.Code != http.StatusOK {
+		t.Errorf("same version, other hash: want 200, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestParseETag(t *testing.T) {
+	for tag, want := range map[string]int{`"3-0123456789abcdef"`: 3, `"12-x"`: 12} {
+		if version, ok := parseETag(tag); !ok || version != want {
+			t.Errorf("%s: %d, %v", tag, version, ok)
+		}
+	}
+	for _, tag := range []string{`3`, `"3"`, `"3-"`, `"x-1"`, `"3`} {
+		if _, ok := parseETag(tag); ok {
+			t.Errorf("%s: want invalid", tag)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+func (h *TaskHandler) TaskHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := h.extractID(r)
+	if err != nil {
+		logger.Logger.Warn("invalid task ID", "error", err.Error())
+		h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("getting task history", "task_id", id)
+
+	revisions, err := h.taskService.TaskHistory(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			logger.Logger.Warn("task not found", "task_id", id)
+			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		logger.Logger.Error("internal server error", "error", err.Error())
+		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJSON(w, dto.NewHistoryRes(id, revisions), http.StatusOK)
+}
+
+func (h *TaskHandler) GetTaskRevision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, number, err := h.extractRevision(r)
+	if err != nil {
+		logger.Logger.Warn("invalid revision path", "error", err.Error())
+		h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("getting task revision", "task_id", id, "revision", number)
+
+	rev, err := h.taskService.TaskAtRevision(r.Context(), id, number)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			logger.Logger.Warn("revision not found", "task_id", id, "revision", number)
+			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		logger.Logger.Error("internal server error", "error", err.Error())
+		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJSON(w, dto.NewTaskRevisionRes(rev), http.StatusOK)
+}
+
+func (h *TaskHandler) RevertTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, number, err := h.extractRevision(r)
+	if err != nil {
+		logger.Logger.Warn("invalid revision path", "error", err.Error())
+		h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("reverting task", "task_id", id, "revision", number)
+
+	task, err := h.taskService.RevertTask(r.Context(), id, number)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			logger.Logger.Warn("task or revision not found", "task_id", id, "revision", number)
+			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+		case errors.Is(err, domain.ErrInvalidRequest):
+			logger.Logger.Warn("validation error", "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrCycle), errors.Is(err, domain.ErrDependencyCycle):
+			logger.Logger.Warn("revert would create a cycle", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+		default:
+			logger.Logger.Error("internal server error", "error", err.Error())
+			h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.sendJSON(w, dto.NewTaskRes(task), http.StatusOK)
+}
+
+// extractRevision разбирает путь /todos/{id}/revisions/{n}[/revert].
+func (h *TaskHandler) extractRevision(r *http.Request) (int, int, error) {
+	id, err := h.extractID(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	_, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/todos/"), "/revisions/")
+	numberStr, _, _ := strings.Cut(rest, "/")
+	number, err := strconv.Atoi(numberStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, number, nil
+}
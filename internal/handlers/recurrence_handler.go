@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+const (
+	defaultOccurrenceCount = 5
+	maxOccurrenceCount     = 100
+)
+
+func (h *TaskHandler) PreviewOccurrences(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := h.extractID(r)
+	if err != nil {
+		logger.Logger.Warn("invalid task ID", "error", err.Error())
+		h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	count := defaultOccurrenceCount
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxOccurrenceCount {
+			logger.Logger.Warn("invalid occurrence count", "count", v)
+			h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+			return
+		}
+		count = n
+	}
+
+	logger.Logger.Info("previewing occurrences", "task_id", id, "count", count)
+
+	occurrences, err := h.taskService.PreviewOccurrences(r.Context(), id, count)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			logger.Logger.Warn("task not found", "task_id", id)
+			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+		case errors.Is(err, domain.ErrInvalidRequest):
+			logger.Logger.Warn("task is not recurring", "task_id", id)
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+		default:
+			logger.Logger.Error("internal server error", "error", err.Error())
+			h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.sendJSON(w, dto.NewOccurrencesRes(id, occurrences), http.StatusOK)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+func (h *TaskHandler) TransitionTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := h.extractID(r)
+	if err != nil {
+		logger.Logger.Warn("invalid task ID", "error", err.Error())
+		h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req dto.TransitionReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		h.sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		logger.Logger.Warn("validation error", "error", err.Error())
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status := domain.Status(req.Status)
+	logger.Logger.Info("changing task status", "task_id", id, "status", status, "cascade", req.Cascade, "force", req.Force)
+
+	r = h.withIfMatch(r)
+	task, err := h.taskService.TransitionTask(r.Context(), id, status, req.Options())
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			logger.Logger.Warn("task not found", "task_id", id)
+			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+		case errors.Is(err, domain.ErrVersionMismatch):
+			logger.Logger.Warn("task version mismatch", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusPreconditionFailed)
+		case errors.Is(err, domain.ErrInvalidTransition):
+			logger.Logger.Warn("invalid status transition", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, domain.ErrBlocked):
+			logger.Logger.Warn("task is blocked", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error()+"; use force to complete anyway", http.StatusConflict)
+		case errors.Is(err, domain.ErrInvalidRequest):
+			logger.Logger.Warn("validation error", "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+		default:
+			logger.Logger.Error("internal server error", "error", err.Error())
+			h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if status == domain.StatusDone && len(task.OpenBlockers) > 0 {
+		logger.Logger.Warn("task completed with open blockers", "task_id", id, "open_blockers", task.OpenBlockers)
+		w.Header().Set("Warning", fmt.Sprintf(`299 - "task completed with %d open blockers"`, len(task.OpenBlockers)))
+	}
+
+	res := dto.NewTaskRes(task)
+	h.setETag(w, res)
+	h.sendJSON(w, res, http.StatusOK)
+}
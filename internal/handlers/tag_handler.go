@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+func (h *TaskHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	logger.Logger.Info("listing tags")
+
+	if r.Method != http.MethodGet {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	tags, err := h.taskService.ListTags(r.Context())
+	if err != nil {
+		logger.Logger.Error("internal server error", "error", err.Error())
+		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJSON(w, dto.NewTagListRes(tags), http.StatusOK)
+}
+
+func (h *TaskHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/tags/")
+
+	var req dto.RenameTagReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		h.sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		logger.Logger.Warn("validation error", "error", err.Error())
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("renaming tag", "tag", name, "new_name", req.Name)
+
+	updated, err := h.taskService.RenameTag(r.Context(), name, req.Name)
+	if err != nil {
+		h.sendTagError(w, err, name)
+		return
+	}
+
+	tag, _ := domain.NormalizeTag(req.Name)
+	h.sendJSON(w, dto.TagChangeRes{Tag: tag, UpdatedTasks: updated}, http.StatusOK)
+}
+
+func (h *TaskHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.MergeTagsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		h.sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		logger.Logger.Warn("validation error", "error", err.Error())
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("merging tags", "sources", req.Sources, "target", req.Target)
+
+	updated, err := h.taskService.MergeTags(r.Context(), req.Sources, req.Target)
+	if err != nil {
+		h.sendTagError(w, err, strings.Join(req.Sources, ","))
+		return
+	}
+
+	tag, _ := domain.NormalizeTag(req.Target)
+	h.sendJSON(w, dto.TagChangeRes{Tag: tag, UpdatedTasks: updated}, http.StatusOK)
+}
+
+func (h *TaskHandler) sendTagError(w http.ResponseWriter, err error, tag string) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		logger.Logger.Warn("tag not found", "tag", tag)
+		h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidRequest):
+		logger.Logger.Warn("validation error", "error", err.Error())
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Logger.Error("internal server error", "error", err.Error())
+		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+	}
+}
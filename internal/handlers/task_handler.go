@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
@@ -9,15 +14,51 @@ import (
 
 	"github.com/S1FFFkA/todo-list/internal/domain"
 	"github.com/S1FFFkA/todo-list/internal/dto"
-	"github.com/S1FFFkA/todo-list/internal/service"
 	"github.com/S1FFFkA/todo-list/pkg/logger"
 )
 
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+type TaskService interface {
+	CreateTask(ctx context.Context, input domain.TaskInput) (*domain.Task, error)
+	ListTasks(ctx context.Context, query domain.TaskQuery) (*domain.TaskPage, error)
+	SearchTasks(ctx context.Context, q string, limit int) ([]domain.SearchResult, error)
+	GetTask(ctx context.Context, id int) (*domain.Task, error)
+	UpdateTask(ctx context.Context, id int, opts domain.CompleteOptions) (*domain.Task, error)
+	TransitionTask(ctx context.Context, id int, to domain.Status, opts domain.CompleteOptions) (*domain.Task, error)
+	UpdateContent(ctx context.Context, id int, input domain.TaskInput) (*domain.Task, error)
+	PatchContent(ctx context.Context, id int, patch func(task *domain.Task) (domain.TaskInput, error)) (*domain.Task, error)
+	DeleteTask(ctx context.Context, id int, opts domain.DeleteOptions) error
+	ArchiveTask(ctx context.Context, id int) (*domain.Task, error)
+	UnarchiveTask(ctx context.Context, id int) (*domain.Task, error)
+	ListTrash(ctx context.Context) ([]*domain.Task, error)
+	RestoreTask(ctx context.Context, id int) (*domain.Task, error)
+	PurgeTask(ctx context.Context, id int) error
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
+	TaskHistory(ctx context.Context, id int) ([]*domain.Revision, error)
+	TaskAtRevision(ctx context.Context, id int, number int) (*domain.Revision, error)
+	RevertTask(ctx context.Context, id int, number int) (*domain.Task, error)
+	Undo(ctx context.Context) (*domain.UndoResult, error)
+	Redo(ctx context.Context) (*domain.UndoResult, error)
+	Batch(ctx context.Context, ops []domain.BatchOp, atomic bool) ([]domain.BatchResult, error)
+	Bulk(ctx context.Context, req domain.BulkRequest) (*domain.BulkResult, error)
+	ListChildren(ctx context.Context, id int) ([]*domain.Task, error)
+	GetSubtree(ctx context.Context, id int) (*domain.TaskNode, error)
+	TopologicalOrder(ctx context.Context) ([]*domain.Task, error)
+	PreviewOccurrences(ctx context.Context, id int, n int) ([]domain.Occurrence, error)
+	ListTags(ctx context.Context) ([]domain.TagCount, error)
+	RenameTag(ctx context.Context, from string, to string) (int, error)
+	MergeTags(ctx context.Context, sources []string, target string) (int, error)
+}
+
 type TaskHandler struct {
-	taskService *service.TaskService
+	taskService TaskService
 }
 
-func NewTaskHandler(taskService *service.TaskService) *TaskHandler {
+func NewTaskHandler(taskService TaskService) *TaskHandler {
 	return &TaskHandler{
 		taskService: taskService,
 	}
@@ -45,19 +86,19 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task := h.taskService.CreateTask(req.Headline, req.Description)
-
-	b, err := json.MarshalIndent(task, "", "    ")
+	task, err := h.taskService.CreateTask(r.Context(), req.ToInput())
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRequest) {
+			logger.Logger.Warn("validation error", "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		logger.Logger.Error("internal server error", "error", err.Error())
 		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	if _, err := w.Write(b); err != nil {
-		logger.Logger.Error("failed to write response", "error", err.Error())
-	}
+	h.sendJSON(w, dto.NewTaskRes(task), http.StatusCreated)
 }
 
 func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
@@ -68,18 +109,61 @@ func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tasks := h.taskService.GetAllTasks()
+	query, err := dto.ParseTaskQuery(r.URL.Query())
+	if err != nil {
+		logger.Logger.Warn("invalid list query", "error", err.Error())
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	b, err := json.MarshalIndent(tasks, "", "    ")
+	page, err := h.taskService.ListTasks(r.Context(), query)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			logger.Logger.Warn("invalid cursor", "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		logger.Logger.Error("internal server error", "error", err.Error())
 		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if _, err := w.Write(b); err != nil {
-		logger.Logger.Error("failed to write response", "error", err.Error())
+	h.sendJSON(w, dto.NewTaskListRes(page), http.StatusOK)
+}
+
+func (h *TaskHandler) SearchTasks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
 	}
+
+	q := r.URL.Query().Get("q")
+	logger.Logger.Info("searching tasks", "query", q)
+
+	limit := defaultSearchLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxSearchLimit {
+			logger.Logger.Warn("invalid search limit", "limit", v)
+			h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	results, err := h.taskService.SearchTasks(r.Context(), q, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRequest) {
+			logger.Logger.Warn("invalid search query", "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.Logger.Error("internal server error", "error", err.Error())
+		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJSON(w, dto.NewSearchRes(q, results), http.StatusOK)
 }
 
 func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
@@ -97,7 +181,7 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 
 	logger.Logger.Info("getting task", "task_id", id)
 
-	task, err := h.taskService.GetTask(id)
+	task, err := h.taskService.GetTask(r.Context(), id)
 	if err != nil {
 		if err == domain.ErrNotFound {
 			logger.Logger.Warn("task not found", "task_id", id)
@@ -109,13 +193,12 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	b, err := json.MarshalIndent(task, "", "    ")
-	if err != nil {
-		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+	res := dto.NewTaskRes(task)
+	if etag := h.setETag(w, res); h.notModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
-
-	w.Write(b)
+	h.sendJSON(w, res, http.StatusOK)
 }
 
 func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
@@ -146,32 +229,125 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.taskService.UpdateContent(id, req.Headline, req.Description)
+	r = h.withIfMatch(r)
+	task, err := h.taskService.UpdateContent(r.Context(), id, req.ToInput())
 	if err != nil {
 		if err == domain.ErrNotFound {
 			logger.Logger.Warn("task not found", "task_id", id)
 			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, domain.ErrVersionMismatch) {
+			logger.Logger.Warn("task version mismatch", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidRequest) {
+			logger.Logger.Warn("validation error", "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domain.ErrCycle) || errors.Is(err, domain.ErrDependencyCycle) {
+			logger.Logger.Warn("cycle detected", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+			return
+		}
 		logger.Logger.Error("internal server error", "error", err.Error())
 		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	b, err := json.MarshalIndent(task, "", "    ")
+	res := dto.NewTaskRes(task)
+	h.setETag(w, res)
+	h.sendJSON(w, res, http.StatusOK)
+}
+
+// Типы тела PATCH /todos/{id}.
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := h.extractID(r)
 	if err != nil {
-		logger.Logger.Error("internal server error", "error", err.Error())
+		logger.Logger.Warn("invalid task ID", "error", err.Error())
+		h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	logger.Logger.Info("patching task", "task_id", id, "content_type", mediaType)
+
+	var parse func(body []byte) (*dto.TaskPatch, error)
+	switch mediaType {
+	case mergePatchType:
+		parse = dto.ParseMergePatch
+	case jsonPatchType:
+		parse = dto.ParseJSONPatch
+	default:
+		logger.Logger.Warn("unsupported patch type", "task_id", id, "content_type", mediaType)
+		w.Header().Set("Accept-Patch", mergePatchType+", "+jsonPatchType)
+		h.sendError(w, fmt.Sprintf("%s: use %s or %s", domain.ErrUnsupportedMedia, mergePatchType, jsonPatchType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Logger.Error("failed to read request body", "error", err.Error())
 		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
 		return
 	}
+	patch, err := parse(body)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRequest) {
+			logger.Logger.Warn("invalid patch", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		h.sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	if _, err := w.Write(b); err != nil {
-		logger.Logger.Error("failed to write response", "error", err.Error())
+	r = h.withIfMatch(r)
+	task, err := h.taskService.PatchContent(r.Context(), id, patch.Apply)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			logger.Logger.Warn("task not found", "task_id", id)
+			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+		case errors.Is(err, domain.ErrVersionMismatch):
+			logger.Logger.Warn("task version mismatch", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusPreconditionFailed)
+		case errors.Is(err, domain.ErrInvalidRequest):
+			logger.Logger.Warn("validation error", "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrPatchConflict):
+			logger.Logger.Warn("patch cannot be applied", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, domain.ErrCycle) || errors.Is(err, domain.ErrDependencyCycle):
+			logger.Logger.Warn("cycle detected", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+		default:
+			logger.Logger.Error("internal server error", "error", err.Error())
+			h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		}
+		return
 	}
+
+	res := dto.NewTaskRes(task)
+	h.setETag(w, res)
+	h.sendJSON(w, res, http.StatusOK)
 }
 
 func (h *TaskHandler) CompleteTask(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPatch {
+	if r.Method != http.MethodPost {
 		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
 		return
 	}
@@ -183,30 +359,58 @@ func (h *TaskHandler) CompleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger.Logger.Info("completing task", "task_id", id)
+	cascade, err := h.boolParam(r, "cascade")
+	if err != nil {
+		logger.Logger.Warn("invalid cascade parameter", "error", err.Error())
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	force, err := h.boolParam(r, "force")
+	if err != nil {
+		logger.Logger.Warn("invalid force parameter", "error", err.Error())
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("completing task", "task_id", id, "cascade", cascade, "force", force)
 
-	task, err := h.taskService.UpdateTask(id)
+	r = h.withIfMatch(r)
+	task, err := h.taskService.UpdateTask(r.Context(), id, domain.CompleteOptions{Cascade: cascade, Force: force})
 	if err != nil {
-		if err == domain.ErrNotFound {
+		if errors.Is(err, domain.ErrNotFound) {
 			logger.Logger.Warn("task not found", "task_id", id)
 			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, domain.ErrVersionMismatch) {
+			logger.Logger.Warn("task version mismatch", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidTransition) {
+			logger.Logger.Warn("invalid status transition", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, domain.ErrBlocked) {
+			logger.Logger.Warn("task is blocked", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error()+"; use force=true to complete anyway", http.StatusConflict)
+			return
+		}
 		logger.Logger.Error("internal server error", "error", err.Error())
 		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	b, err := json.MarshalIndent(task, "", "    ")
-	if err != nil {
-		logger.Logger.Error("internal server error", "error", err.Error())
-		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
-		return
+	if len(task.OpenBlockers) > 0 {
+		// Завершили с force: предупреждаем, что блокирующие задачи ещё открыты
+		logger.Logger.Warn("task completed with open blockers", "task_id", id, "open_blockers", task.OpenBlockers)
+		w.Header().Set("Warning", fmt.Sprintf(`299 - "task completed with %d open blockers"`, len(task.OpenBlockers)))
 	}
 
-	if _, err := w.Write(b); err != nil {
-		logger.Logger.Error("failed to write response", "error", err.Error())
-	}
+	res := dto.NewTaskRes(task)
+	h.setETag(w, res)
+	h.sendJSON(w, res, http.StatusOK)
 }
 
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
@@ -222,15 +426,33 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger.Logger.Info("deleting task", "task_id", id)
+	cascade, err := h.boolParam(r, "cascade")
+	if err != nil {
+		logger.Logger.Warn("invalid cascade parameter", "error", err.Error())
+		h.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	err = h.taskService.DeleteTask(id)
+	logger.Logger.Info("deleting task", "task_id", id, "cascade", cascade)
+
+	r = h.withIfMatch(r)
+	err = h.taskService.DeleteTask(r.Context(), id, domain.DeleteOptions{Cascade: cascade})
 	if err != nil {
 		if err == domain.ErrNotFound {
 			logger.Logger.Warn("task not found", "task_id", id)
 			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, domain.ErrVersionMismatch) {
+			logger.Logger.Warn("task version mismatch", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, domain.ErrHasSubtasks) {
+			logger.Logger.Warn("task has subtasks", "task_id", id)
+			h.sendError(w, "task has subtasks, use cascade=true to delete them", http.StatusConflict)
+			return
+		}
 		logger.Logger.Error("internal server error", "error", err.Error())
 		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
 		return
@@ -239,12 +461,52 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// extractID разбирает ID из путей вида /todos/{id} и /todos/{id}/children.
 func (h *TaskHandler) extractID(r *http.Request) (int, error) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/todos/")
+	return h.extractPathID(r, "/todos/")
+}
+
+// extractPathID разбирает ID, который идёт в пути сразу после prefix.
+func (h *TaskHandler) extractPathID(r *http.Request, prefix string) (int, error) {
+	idStr, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, prefix), "/")
 	return strconv.Atoi(idStr)
 }
 
+func (h *TaskHandler) boolParam(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid %s", domain.ErrInvalidRequest, name)
+	}
+	return b, nil
+}
+
+func (h *TaskHandler) sendJSON(w http.ResponseWriter, v any, statusCode int) {
+	sendJSON(w, v, statusCode)
+}
+
 func (h *TaskHandler) sendError(w http.ResponseWriter, message string, statusCode int) {
+	sendError(w, message, statusCode)
+}
+
+func sendJSON(w http.ResponseWriter, v any, statusCode int) {
+	b, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		logger.Logger.Error("internal server error", "error", err.Error())
+		sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(b); err != nil {
+		logger.Logger.Error("failed to write response", "error", err.Error())
+	}
+}
+
+func sendError(w http.ResponseWriter, message string, statusCode int) {
 	errDTO := dto.ErrorDTO{
 		Message: message,
 		Time:    time.Now(),
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository/memory"
+	"github.com/S1FFFkA/todo-list/internal/service"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+func TestCompleteCancelledTask(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	taskService, err := service.NewTaskService(ctx, memory.NewTaskRepository())
+	if err != nil {
+		t.Fatalf("service: %v", err)
+	}
+	h := NewTaskHandler(taskService)
+
+	task, err := taskService.CreateTask(ctx, domain.TaskInput{Headline: "Task", Description: "D"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := taskService.TransitionTask(ctx, task.ID, domain.StatusCancelled, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	complete := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.CompleteTask(w, httptest.NewRequest(http.MethodPost, "/todos/"+id+"/complete", nil))
+		return w
+	}
+	if w := complete(strconv.Itoa(task.ID)); w.Code != http.StatusConflict {
+		t.Errorf("cancelled task: want 409, got %d: %s", w.Code, w.Body)
+	}
+	if w := complete(strconv.Itoa(task.ID + 1)); w.Code != http.StatusNotFound {
+		t.Errorf("unknown task: want 404, got %d", w.Code)
+	}
+}
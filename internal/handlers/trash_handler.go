@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+func (h *TaskHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	logger.Logger.Info("listing trash")
+
+	if r.Method != http.MethodGet {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	tasks, err := h.taskService.ListTrash(r.Context())
+	if err != nil {
+		logger.Logger.Error("internal server error", "error", err.Error())
+		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJSON(w, dto.NewTrashRes(tasks), http.StatusOK)
+}
+
+func (h *TaskHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := h.extractPathID(r, "/trash/")
+	if err != nil {
+		logger.Logger.Warn("invalid task ID", "error", err.Error())
+		h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("restoring task", "task_id", id)
+
+	task, err := h.taskService.RestoreTask(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			logger.Logger.Warn("task not found in trash", "task_id", id)
+			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+		case errors.Is(err, domain.ErrParentDeleted):
+			logger.Logger.Warn("parent task is in trash", "task_id", id, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+		default:
+			logger.Logger.Error("internal server error", "error", err.Error())
+			h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.sendJSON(w, dto.NewTaskRes(task), http.StatusOK)
+}
+
+func (h *TaskHandler) PurgeTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := h.extractPathID(r, "/trash/")
+	if err != nil {
+		logger.Logger.Warn("invalid task ID", "error", err.Error())
+		h.sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("purging task", "task_id", id)
+
+	if err := h.taskService.PurgeTask(r.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			logger.Logger.Warn("task not found in trash", "task_id", id)
+			h.sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		logger.Logger.Error("internal server error", "error", err.Error())
+		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	logger.Logger.Info("emptying trash")
+
+	if r.Method != http.MethodDelete {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	n, err := h.taskService.PurgeTrash(r.Context(), time.Time{})
+	if err != nil {
+		logger.Logger.Error("internal server error", "error", err.Error())
+		h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.sendJSON(w, dto.PurgeRes{Purged: n}, http.StatusOK)
+}
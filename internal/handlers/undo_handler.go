@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+func (h *TaskHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	h.undoOrRedo(w, r, "undoing last operation", h.taskService.Undo)
+}
+
+func (h *TaskHandler) Redo(w http.ResponseWriter, r *http.Request) {
+	h.undoOrRedo(w, r, "redoing last operation", h.taskService.Redo)
+}
+
+func (h *TaskHandler) undoOrRedo(w http.ResponseWriter, r *http.Request, msg string, apply func(ctx context.Context) (*domain.UndoResult, error)) {
+	if r.Method != http.MethodPost {
+		h.sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	actor := domain.ActorFrom(r.Context())
+	logger.Logger.Info(msg, "actor", actor)
+
+	res, err := apply(r.Context())
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNothingToUndo), errors.Is(err, domain.ErrNothingToRedo):
+			logger.Logger.Warn("nothing to apply", "actor", actor, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, domain.ErrAnonymousClient):
+			logger.Logger.Warn("undo without client identity", "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrUndoConflict):
+			logger.Logger.Warn("task changed after the operation", "actor", actor, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, domain.ErrHasSubtasks), errors.Is(err, domain.ErrParentDeleted),
+			errors.Is(err, domain.ErrBlocked), errors.Is(err, domain.ErrCycle), errors.Is(err, domain.ErrDependencyCycle):
+			logger.Logger.Warn("operation cannot be applied", "actor", actor, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, domain.ErrInvalidRequest):
+			logger.Logger.Warn("validation error", "actor", actor, "error", err.Error())
+			h.sendError(w, err.Error(), http.StatusBadRequest)
+		default:
+			logger.Logger.Error("internal server error", "error", err.Error())
+			h.sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.sendJSON(w, dto.NewUndoRes(res), http.StatusOK)
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/dto"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+type UserService interface {
+	CreateUser(ctx context.Context, name string, password string) (*domain.User, error)
+	CreateAPIKey(ctx context.Context, name string) (*domain.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+}
+
+// defaultAPIKeyName — название ключа, который выдаётся при регистрации.
+const defaultAPIKeyName = "default"
+
+type UserHandler struct {
+	userService UserService
+}
+
+func NewUserHandler(userService UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.CreateUserReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		logger.Logger.Warn("validation error", "error", err.Error())
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("creating user", "name", req.Name)
+
+	user, err := h.userService.CreateUser(r.Context(), req.Name, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAlreadyExists):
+			logger.Logger.Warn("user already exists", "name", req.Name)
+			sendError(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, domain.ErrInvalidRequest):
+			logger.Logger.Warn("validation error", "error", err.Error())
+			sendError(w, err.Error(), http.StatusBadRequest)
+		default:
+			logger.Logger.Error("internal server error", "error", err.Error())
+			sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Первый ключ выдаём сразу, иначе новому пользователю нечем
+	// авторизоваться
+	key, secret, err := h.userService.CreateAPIKey(domain.WithUser(r.Context(), user), defaultAPIKeyName)
+	if err != nil {
+		logger.Logger.Error("internal server error", "user_id", user.ID, "error", err.Error())
+		sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sendJSON(w, dto.NewCreatedUserRes(user, key, secret), http.StatusCreated)
+}
+
+func (h *UserHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.CreateAPIKeyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Logger.Error("failed to decode JSON", "error", err.Error())
+		sendError(w, domain.ErrFailedToDecodeJSON.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		logger.Logger.Warn("validation error", "error", err.Error())
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("creating API key", "user_id", domain.UserID(r.Context()))
+
+	key, secret, err := h.userService.CreateAPIKey(r.Context(), req.Name)
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	sendJSON(w, dto.NewCreatedAPIKeyRes(key, secret), http.StatusCreated)
+}
+
+func (h *UserHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	keys, err := h.userService.ListAPIKeys(r.Context())
+	if err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	sendJSON(w, dto.NewAPIKeyListRes(keys), http.StatusOK)
+}
+
+func (h *UserHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api-keys/"))
+	if err != nil {
+		logger.Logger.Warn("invalid API key ID", "error", err.Error())
+		sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Logger.Info("revoking API key", "key_id", id, "user_id", domain.UserID(r.Context()))
+
+	if err := h.userService.RevokeAPIKey(r.Context(), id); err != nil {
+		h.sendServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) sendServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUnauthorized):
+		logger.Logger.Warn("unauthorized", "error", err.Error())
+		SendUnauthorized(w, err.Error())
+	case errors.Is(err, domain.ErrNotFound):
+		logger.Logger.Warn("API key not found", "error", err.Error())
+		sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidRequest):
+		logger.Logger.Warn("validation error", "error", err.Error())
+		sendError(w, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Logger.Error("internal server error", "error", err.Error())
+		sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+	}
+}
+
+// SendUnauthorized отвечает 401 с заголовком WWW-Authenticate, как того
+// требует схема Bearer.
+func SendUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="todo-list"`)
+	sendError(w, message, http.StatusUnauthorized)
+}
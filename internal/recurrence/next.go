@@ -0,0 +1,177 @@
+package recurrence
+
+import (
+	"slices"
+	"time"
+)
+
+// maxSteps ограничивает перебор кандидатов, чтобы правило, у которого нет
+// ни одного подходящего дня (например, BYMONTHDAY=31 с INTERVAL=12 в феврале),
+// не зациклило сервис.
+const maxSteps = 1000
+
+// Next возвращает повторение, следующее за prev. occurrence — номер prev в
+// серии, начиная с 1; он нужен для COUNT. ok == false, если серия закончилась.
+func (r Rule) Next(prev time.Time, occurrence int) (time.Time, bool) {
+	if r.Count > 0 && occurrence >= r.Count {
+		return time.Time{}, false
+	}
+	next, ok := r.next(prev)
+	if !ok || r.afterUntil(next) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Preview возвращает до n повторений после prev.
+func (r Rule) Preview(prev time.Time, occurrence int, n int) []time.Time {
+	result := make([]time.Time, 0, n)
+	for len(result) < n {
+		next, ok := r.Next(prev, occurrence)
+		if !ok {
+			break
+		}
+		result = append(result, next)
+		prev = next
+		occurrence++
+	}
+	return result
+}
+
+func (r Rule) afterUntil(t time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+	if r.UntilDate {
+		y, m, d := t.Date()
+		uy, um, ud := r.Until.Date()
+		return civil(y, m, d).After(civil(uy, um, ud))
+	}
+	return t.After(r.Until)
+}
+
+func (r Rule) next(prev time.Time) (time.Time, bool) {
+	y, m, d := prev.Date()
+	hh, mm, ss := prev.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hh, mm, ss, prev.Nanosecond(), prev.Location())
+	}
+	interval := max(r.Interval, 1)
+
+	switch r.Freq {
+	case Daily:
+		for i := 1; i <= maxSteps; i++ {
+			c := at(y, m, d+i*interval)
+			if r.matchesWeekday(c.Weekday()) {
+				return c, true
+			}
+		}
+
+	case Weekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Day: prev.Weekday()}}
+		}
+		start := weekStart(civil(y, m, d))
+		for i := 1; i <= 7*interval+7; i++ {
+			cy, cm, cd := civil(y, m, d+i).Date()
+			weeks := int(weekStart(civil(cy, cm, cd)).Sub(start).Hours()/24) / 7
+			weekday := civil(cy, cm, cd).Weekday()
+			if weeks%interval == 0 && slices.ContainsFunc(days, func(w WeekdayNum) bool { return w.Day == weekday }) {
+				return at(cy, cm, cd), true
+			}
+		}
+
+	case Monthly:
+		for k := 0; k <= maxSteps; k++ {
+			first := civil(y, m+time.Month(k*interval), 1)
+			for _, day := range r.monthDays(first.Year(), first.Month(), d) {
+				if c := at(first.Year(), first.Month(), day); c.After(prev) {
+					return c, true
+				}
+			}
+		}
+
+	case Yearly:
+		for k := 1; k <= maxSteps; k++ {
+			year := y + k*interval
+			// 29 февраля повторяется только в високосные годы
+			if d <= daysIn(year, m) {
+				return at(year, m, d), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r Rule) matchesWeekday(day time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(r.ByDay, func(w WeekdayNum) bool { return w.Day == day })
+}
+
+// monthDays возвращает подходящие дни месяца по возрастанию. Если заданы и
+// BYMONTHDAY, и BYDAY, день должен подходить под оба условия. Без них
+// повторение приходится на тот же день месяца, что и предыдущее; месяцы, где
+// такого дня нет, пропускаются.
+func (r Rule) monthDays(year int, month time.Month, anchorDay int) []int {
+	n := daysIn(year, month)
+
+	var byMonthDay, byDay []int
+	for _, md := range r.ByMonthDay {
+		if md < 0 {
+			md = n + 1 + md
+		}
+		if md >= 1 && md <= n {
+			byMonthDay = append(byMonthDay, md)
+		}
+	}
+	for _, wd := range r.ByDay {
+		var matches []int
+		for day := 1; day <= n; day++ {
+			if civil(year, month, day).Weekday() == wd.Day {
+				matches = append(matches, day)
+			}
+		}
+		switch {
+		case wd.N == 0:
+			byDay = append(byDay, matches...)
+		case wd.N > 0 && wd.N <= len(matches):
+			byDay = append(byDay, matches[wd.N-1])
+		case wd.N < 0 && -wd.N <= len(matches):
+			byDay = append(byDay, matches[len(matches)+wd.N])
+		}
+	}
+
+	var days []int
+	switch {
+	case len(r.ByMonthDay) > 0 && len(r.ByDay) > 0:
+		for _, day := range byMonthDay {
+			if slices.Contains(byDay, day) {
+				days = append(days, day)
+			}
+		}
+	case len(r.ByMonthDay) > 0:
+		days = byMonthDay
+	case len(r.ByDay) > 0:
+		days = byDay
+	case anchorDay <= n:
+		days = []int{anchorDay}
+	}
+	slices.Sort(days)
+	return slices.Compact(days)
+}
+
+func civil(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// weekStart возвращает понедельник недели, в которую попадает день.
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func daysIn(year int, month time.Month) int {
+	return civil(year, month+1, 0).Day()
+}
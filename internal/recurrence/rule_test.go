@@ -0,0 +1,147 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, s string) Rule {
+	t.Helper()
+	rule, err := Parse(s)
+	if err != nil {
+		t.Fatalf("parse %q: %v", s, err)
+	}
+	return rule
+}
+
+func dates(times []time.Time) []string {
+	result := make([]string, 0, len(times))
+	for _, t := range times {
+		result = append(result, t.Format("2006-01-02 15:04 Mon"))
+	}
+	return result
+}
+
+func TestParseAndString(t *testing.T) {
+	rule := mustParse(t, "rrule:freq=monthly;byday=1mo,-1FR;interval=2;count=5")
+	if got := rule.String(); got != "FREQ=MONTHLY;INTERVAL=2;BYDAY=1MO,-1FR;COUNT=5" {
+		t.Errorf("string: %s", got)
+	}
+
+	invalid := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20250101",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;BYSETPOS=1",
+	}
+	for _, s := range invalid {
+		if _, err := Parse(s); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("%q: want ErrInvalidRule, got %v", s, err)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+
+	cases := []struct {
+		rule  string
+		start time.Time
+		want  []string
+	}{
+		{
+			"FREQ=DAILY;INTERVAL=2",
+			time.Date(2025, 1, 30, 9, 0, 0, 0, moscow),
+			[]string{"2025-02-01 09:00 Sat", "2025-02-03 09:00 Mon", "2025-02-05 09:00 Wed"},
+		},
+		{
+			// Будни: пятница -> понедельник
+			"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			time.Date(2025, 3, 7, 9, 0, 0, 0, moscow),
+			[]string{"2025-03-10 09:00 Mon", "2025-03-11 09:00 Tue", "2025-03-12 09:00 Wed"},
+		},
+		{
+			"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			time.Date(2025, 3, 3, 10, 0, 0, 0, moscow),
+			[]string{"2025-03-06 10:00 Thu", "2025-03-17 10:00 Mon", "2025-03-20 10:00 Thu"},
+		},
+		{
+			// Время сохраняется при переходе на летнее время
+			"FREQ=WEEKLY",
+			time.Date(2025, 3, 24, 9, 0, 0, 0, berlin),
+			[]string{"2025-03-31 09:00 Mon", "2025-04-07 09:00 Mon", "2025-04-14 09:00 Mon"},
+		},
+		{
+			// В месяцах без 31-го числа повторения нет
+			"FREQ=MONTHLY",
+			time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC),
+			[]string{"2025-03-31 12:00 Mon", "2025-05-31 12:00 Sat", "2025-07-31 12:00 Thu"},
+		},
+		{
+			"FREQ=MONTHLY;BYMONTHDAY=-1",
+			time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC),
+			[]string{"2025-02-28 12:00 Fri", "2025-03-31 12:00 Mon", "2025-04-30 12:00 Wed"},
+		},
+		{
+			"FREQ=MONTHLY;BYDAY=1MO",
+			time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC),
+			[]string{"2025-02-03 09:00 Mon", "2025-03-03 09:00 Mon", "2025-04-07 09:00 Mon"},
+		},
+		{
+			"FREQ=YEARLY",
+			time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			[]string{"2028-02-29 00:00 Tue", "2032-02-29 00:00 Sun", "2036-02-29 00:00 Fri"},
+		},
+	}
+	for _, c := range cases {
+		got := dates(mustParse(t, c.rule).Preview(c.start, 1, 3))
+		if len(got) != len(c.want) {
+			t.Errorf("%s: %v", c.rule, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: %v, want %v", c.rule, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestCountAndUntil(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	rule := mustParse(t, "FREQ=DAILY;COUNT=3")
+	if got := rule.Preview(start, 1, 10); len(got) != 2 {
+		t.Errorf("count from first: %v", dates(got))
+	}
+	if _, ok := rule.Next(start, 3); ok {
+		t.Error("next after last occurrence")
+	}
+
+	rule = mustParse(t, "FREQ=DAILY;UNTIL=20250303")
+	if got := rule.Preview(start, 1, 10); len(got) != 2 {
+		t.Errorf("until date: %v", dates(got))
+	}
+
+	rule = mustParse(t, "FREQ=DAILY;UNTIL=20250303T080000Z")
+	if got := rule.Preview(start, 1, 10); len(got) != 1 {
+		t.Errorf("until time: %v", dates(got))
+	}
+}
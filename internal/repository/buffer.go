@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+var (
+	_ TaskRepository = (*Buffer)(nil)
+	_ Transactor     = (*Buffer)(nil)
+	_ EventRecorder  = (*Buffer)(nil)
+)
+
+// Buffer копит изменения в памяти поверх хранилища и передаёт их туда только
+// в Commit. Чтение видит и хранилище, и накопленные изменения. Нужен
+// хранилищам без транзакций, чтобы серия изменений, прерванная ошибкой, не
+// попала в хранилище вовсе.
+//
+// Сам Commit записывает изменения по одному. Хранилище, которое умеет
+// записать их разом, передаёт в CommitTo свою запись.
+type Buffer struct {
+	base TaskRepository
+	// tasks — созданные и изменённые задачи; nil — задача удалена.
+	tasks map[int]*domain.Task
+	// revisions — новые ревизии; purged — задачи, удалённые вместе с
+	// историей из base.
+	revisions map[int][]*domain.Revision
+	purged    map[int]bool
+	// changes — изменения в порядке выполнения, Commit повторяет их в base.
+	changes []func(ctx context.Context, repo TaskRepository) error
+}
+
+func NewBuffer(base TaskRepository) *Buffer {
+	return &Buffer{
+		base:      base,
+		tasks:     make(map[int]*domain.Task),
+		revisions: make(map[int][]*domain.Revision),
+		purged:    make(map[int]bool),
+	}
+}
+
+// WithinTx выполняет fn поверх того же буфера: вложенные транзакции
+// становятся частью внешней.
+func (b *Buffer) WithinTx(ctx context.Context, fn func(tx TaskRepository) error) error {
+	return fn(b)
+}
+
+// Commit записывает накопленные изменения в хранилище. Если запись
+// прервётся, часть изменений останется в хранилище.
+func (b *Buffer) Commit(ctx context.Context) error {
+	return b.CommitTo(ctx, b.base)
+}
+
+// CommitTo повторяет накопленные изменения в repo — хранилище или его
+// транзакции.
+func (b *Buffer) CommitTo(ctx context.Context, repo TaskRepository) error {
+	for _, change := range b.changes {
+		if err := change(ctx, repo); err != nil {
+			return err
+		}
+	}
+	b.changes = nil
+	return nil
+}
+
+func (b *Buffer) Create(ctx context.Context, task *domain.Task) error {
+	if _, err := b.Get(ctx, task.ID); err == nil {
+		return domain.ErrAlreadyExists
+	}
+	created := task.Clone()
+	b.tasks[task.ID] = created
+	b.changes = append(b.changes, func(ctx context.Context, repo TaskRepository) error {
+		return repo.Create(ctx, created)
+	})
+	return nil
+}
+
+func (b *Buffer) Get(ctx context.Context, id int) (*domain.Task, error) {
+	if task, ok := b.tasks[id]; ok {
+		if task == nil {
+			return nil, domain.ErrNotFound
+		}
+		return task.Clone(), nil
+	}
+	return b.base.Get(ctx, id)
+}
+
+func (b *Buffer) List(ctx context.Context) ([]*domain.Task, error) {
+	base, err := b.base.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*domain.Task, 0, len(base)+len(b.tasks))
+	seen := make(map[int]bool, len(base))
+	for _, task := range base {
+		seen[task.ID] = true
+		if changed, ok := b.tasks[task.ID]; ok {
+			if changed != nil {
+				tasks = append(tasks, changed.Clone())
+			}
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	for id, task := range b.tasks {
+		if !seen[id] && task != nil {
+			tasks = append(tasks, task.Clone())
+		}
+	}
+	return tasks, nil
+}
+
+func (b *Buffer) Update(ctx context.Context, task *domain.Task) error {
+	if _, err := b.Get(ctx, task.ID); err != nil {
+		return err
+	}
+	updated := task.Clone()
+	b.tasks[task.ID] = updated
+	b.changes = append(b.changes, func(ctx context.Context, repo TaskRepository) error {
+		return repo.Update(ctx, updated)
+	})
+	return nil
+}
+
+func (b *Buffer) Delete(ctx context.Context, id int) error {
+	if _, err := b.Get(ctx, id); err != nil {
+		return err
+	}
+	b.tasks[id] = nil
+	delete(b.revisions, id)
+	b.purged[id] = true
+	b.changes = append(b.changes, func(ctx context.Context, repo TaskRepository) error {
+		return repo.Delete(ctx, id)
+	})
+	return nil
+}
+
+func (b *Buffer) AppendRevision(ctx context.Context, rev *domain.Revision) error {
+	if _, err := b.Get(ctx, rev.TaskID); err != nil {
+		return err
+	}
+	existing, err := b.ListRevisions(ctx, rev.TaskID)
+	if err != nil {
+		return err
+	}
+	rev.Number = 1
+	if n := len(existing); n > 0 {
+		rev.Number = existing[n-1].Number + 1
+	}
+	appended := rev.Clone()
+	b.revisions[rev.TaskID] = append(b.revisions[rev.TaskID], appended)
+	b.changes = append(b.changes, func(ctx context.Context, repo TaskRepository) error {
+		return repo.AppendRevision(ctx, appended.Clone())
+	})
+	return nil
+}
+
+// Record проверяет изменение так же, как Create, Update и AppendRevision, и
+// передаёт его хранилищу одним событием typ.
+func (b *Buffer) Record(ctx context.Context, typ domain.EventType, task *domain.Task, rev *domain.Revision) error {
+	n := len(b.changes)
+	if err := record(ctx, b, typ, task, rev); err != nil {
+		return err
+	}
+
+	saved := b.tasks[task.ID]
+	var recorded *domain.Revision
+	if rev != nil {
+		revisions := b.revisions[task.ID]
+		recorded = revisions[len(revisions)-1]
+	}
+	b.changes = append(b.changes[:n], func(ctx context.Context, repo TaskRepository) error {
+		var rev *domain.Revision
+		if recorded != nil {
+			rev = recorded.Clone()
+		}
+		return Record(ctx, repo, typ, saved.Clone(), rev)
+	})
+	return nil
+}
+
+func (b *Buffer) ListRevisions(ctx context.Context, taskID int) ([]*domain.Revision, error) {
+	var revisions []*domain.Revision
+	if !b.purged[taskID] {
+		base, err := b.base.ListRevisions(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		revisions = base
+	}
+	for _, rev := range b.revisions[taskID] {
+		revisions = append(revisions, rev.Clone())
+	}
+	if revisions == nil {
+		revisions = []*domain.Revision{}
+	}
+	return revisions, nil
+}
+
+// Atomically выполняет fn в транзакции, если хранилище их поддерживает, и
+// через Buffer в противном случае. Если fn вернул ошибку, в хранилище
+// ничего не попадает. Без транзакций сбой записи в самом Commit может
+// оставить в хранилище часть изменений.
+func Atomically(ctx context.Context, repo TaskRepository, fn func(tx TaskRepository) error) error {
+	if t, ok := repo.(Transactor); ok {
+		return t.WithinTx(ctx, fn)
+	}
+	b := NewBuffer(repo)
+	if err := fn(b); err != nil {
+		return err
+	}
+	return b.Commit(ctx)
+}
@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/memory"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func TestBuffer(t *testing.T) {
+	repositorytest.RunTaskRepository(t, func(t *testing.T) repository.TaskRepository {
+		return repository.NewBuffer(memory.NewTaskRepository())
+	})
+}
+
+func TestAtomicallyWithoutTransactions(t *testing.T) {
+	ctx := context.Background()
+	base := memory.NewTaskRepository()
+	if err := base.Create(ctx, domain.NewTask(1, "Base", "Description")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	failure := errors.New("failure")
+	err := repository.Atomically(ctx, base, func(tx repository.TaskRepository) error {
+		if err := tx.Create(ctx, domain.NewTask(2, "New", "Description")); err != nil {
+			return err
+		}
+		if err := tx.Delete(ctx, 1); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("want failure, got %v", err)
+	}
+	if tasks, _ := base.List(ctx); len(tasks) != 1 || tasks[0].ID != 1 {
+		t.Fatalf("rolled back changes reached the store: %v", tasks)
+	}
+
+	err = repository.Atomically(ctx, base, func(tx repository.TaskRepository) error {
+		if err := tx.Create(ctx, domain.NewTask(2, "New", "Description")); err != nil {
+			return err
+		}
+		if err := tx.AppendRevision(ctx, &domain.Revision{TaskID: 2, Action: domain.ActionCreate}); err != nil {
+			return err
+		}
+		return tx.Delete(ctx, 1)
+	})
+	if err != nil {
+		t.Fatalf("atomically: %v", err)
+	}
+	if _, err := base.Get(ctx, 1); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("task 1 was not deleted: %v", err)
+	}
+	revisions, err := base.ListRevisions(ctx, 2)
+	if err != nil || len(revisions) != 1 || revisions[0].Number != 1 {
+		t.Errorf("revisions = %v, %v", revisions, err)
+	}
+}
@@ -0,0 +1,110 @@
+package file
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.APIKeyRepository = (*APIKeyRepository)(nil)
+
+// APIKeyRepository хранит ключи API в памяти с журналом api_keys.wal и
+// снапшотами api_keys.snapshot в каталоге dir.
+type APIKeyRepository struct {
+	store *store[int, *domain.APIKey]
+	// mtx делает чтение ключа и запись изменённой копии одной операцией
+	mtx sync.Mutex
+}
+
+func OpenAPIKeyRepository(dir string, opts Options) (*APIKeyRepository, error) {
+	s, err := openStore(dir, "api_keys", opts,
+		func(k *domain.APIKey) int { return k.ID },
+		func(k *domain.APIKey) *domain.APIKey { return k.Clone() },
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &APIKeyRepository{store: s}, nil
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, err := r.GetByHash(ctx, key.Hash); err == nil {
+		return domain.ErrAlreadyExists
+	}
+	return r.store.write(key.ID, key, func(_ *domain.APIKey, exists bool) (string, error) {
+		if exists {
+			return "", domain.ErrAlreadyExists
+		}
+		return opCreate, nil
+	})
+}
+
+func (r *APIKeyRepository) Get(ctx context.Context, id int) (*domain.APIKey, error) {
+	key, ok := r.store.get(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	for _, key := range r.store.list() {
+		if key.Hash == hash {
+			return key, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID int) ([]*domain.APIKey, error) {
+	keys := slices.DeleteFunc(r.store.list(), func(k *domain.APIKey) bool { return k.UserID != userID })
+	slices.SortFunc(keys, func(a, b *domain.APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	return r.update(id, func(key *domain.APIKey) {
+		key.LastUsedAt = &at
+	})
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int, at time.Time) error {
+	return r.update(id, func(key *domain.APIKey) {
+		if key.RevokedAt == nil {
+			key.RevokedAt = &at
+		}
+	})
+}
+
+func (r *APIKeyRepository) update(id int, change func(key *domain.APIKey)) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	key, ok := r.store.get(id)
+	if !ok {
+		return domain.ErrNotFound
+	}
+	change(key)
+	return r.store.write(id, key, func(_ *domain.APIKey, exists bool) (string, error) {
+		if !exists {
+			return "", domain.ErrNotFound
+		}
+		return opUpdate, nil
+	})
+}
+
+func (r *APIKeyRepository) Close() error {
+	return r.store.close()
+}
@@ -0,0 +1,57 @@
+package file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	repositorytest.RunAPIKeyRepository(t, func(t *testing.T) (repository.UserRepository, repository.APIKeyRepository) {
+		dir := t.TempDir()
+		users, err := OpenUserRepository(dir, Options{})
+		if err != nil {
+			t.Fatalf("open users: %v", err)
+		}
+		t.Cleanup(func() { users.Close() })
+		keys, err := OpenAPIKeyRepository(dir, Options{})
+		if err != nil {
+			t.Fatalf("open keys: %v", err)
+		}
+		t.Cleanup(func() { keys.Close() })
+		return users, keys
+	})
+}
+
+func TestAPIKeyRepositoryPersistsAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	now := time.Now()
+
+	repo, err := OpenAPIKeyRepository(dir, Options{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := repo.Create(ctx, &domain.APIKey{ID: 1, UserID: 1, Hash: "h1", CreatedAt: now}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Revoke(ctx, 1, now); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	repo, err = OpenAPIKeyRepository(dir, Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer repo.Close()
+	if key, err := repo.GetByHash(ctx, "h1"); err != nil || !key.Revoked() {
+		t.Errorf("after restart: %+v, %v", key, err)
+	}
+}
@@ -0,0 +1,405 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var (
+	_ repository.TaskRepository = (*EventRepository)(nil)
+	_ repository.EventRecorder  = (*EventRepository)(nil)
+	_ repository.Transactor     = (*EventRepository)(nil)
+)
+
+const eventLogName = "events.log"
+
+// EventRepository хранит задачи как журнал доменных событий (events.log в
+// каталоге dir). Журнал только дописывается; текущее состояние — проекция,
+// которая при открытии строится проигрыванием всех событий. Каждое
+// изменение сначала попадает в журнал и только потом в проекцию.
+//
+// Событие хранит только изменённые поля задачи. Вид события называет
+// сервис через Record, там же в событие попадает ревизия; Update и
+// AppendRevision вида изменения не знают и пишут EventTaskUpdated и
+// EventRevisionRecorded.
+//
+// События транзакции (WithinTx) записываются одним кадром журнала — JSON-
+// массивом, поэтому после сбоя в журнале оказываются все они или ни одного.
+type EventRepository struct {
+	log  *wal
+	opts Options
+
+	mtx      sync.RWMutex
+	state    *domain.Projection
+	closed   bool
+	recovery Recovery
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+func OpenEventRepository(dir string, opts Options) (*EventRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, eventLogName)
+	w, err := openWAL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &EventRepository{
+		log:   w,
+		opts:  opts.withDefaults(),
+		state: domain.NewProjection(),
+		stop:  make(chan struct{}),
+	}
+	truncated, err := w.replay(func(payload []byte) error {
+		return decodeEvents(payload, func(e *domain.Event) error {
+			r.recovery.Replayed++
+			return r.state.Apply(e)
+		})
+	})
+	if err != nil {
+		w.f.Close()
+		return nil, fmt.Errorf("replay %s: %w", path, err)
+	}
+	r.recovery.TruncatedBytes = truncated
+
+	if r.opts.FsyncPolicy == FsyncInterval {
+		r.done.Go(r.syncLoop)
+	}
+	return r, nil
+}
+
+// ReadEvents проигрывает журнал событий из каталога dir, не открывая его на
+// запись, поэтому годится для разбора журнала работающего сервера.
+// Оборванный хвост журнала пропускается.
+func ReadEvents(dir string, fn func(e *domain.Event) error) error {
+	f, err := os.Open(filepath.Join(dir, eventLogName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = readFrames(f, func(payload []byte) error {
+		return decodeEvents(payload, fn)
+	})
+	return err
+}
+
+// decodeEvents разбирает кадр журнала: одно событие или массив событий
+// транзакции.
+func decodeEvents(payload []byte, fn func(e *domain.Event) error) error {
+	if !bytes.HasPrefix(payload, []byte("[")) {
+		var e domain.Event
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		return fn(&e)
+	}
+
+	var events []*domain.Event
+	if err := json.Unmarshal(payload, &events); err != nil {
+		return err
+	}
+	for _, e := range events {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *EventRepository) syncLoop() {
+	ticker := time.NewTicker(r.opts.FsyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.mtx.Lock()
+			var err error
+			if !r.closed {
+				err = r.log.sync()
+			}
+			r.mtx.Unlock()
+			if err != nil {
+				r.opts.OnError(err)
+			}
+		}
+	}
+}
+
+func (r *EventRepository) Recovery() Recovery {
+	return r.recovery
+}
+
+// Seq возвращает номер последнего события в журнале.
+func (r *EventRepository) Seq() uint64 {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.state.Seq
+}
+
+func (r *EventRepository) Create(ctx context.Context, task *domain.Task) error {
+	return r.Record(ctx, domain.EventTaskCreated, task, nil)
+}
+
+func (r *EventRepository) Get(ctx context.Context, id int) (*domain.Task, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	task, ok := r.state.Tasks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return task.Clone(), nil
+}
+
+func (r *EventRepository) List(ctx context.Context) ([]*domain.Task, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	tasks := make([]*domain.Task, 0, len(r.state.Tasks))
+	for _, task := range r.state.Tasks {
+		tasks = append(tasks, task.Clone())
+	}
+	return tasks, nil
+}
+
+func (r *EventRepository) Update(ctx context.Context, task *domain.Task) error {
+	return r.Record(ctx, domain.EventTaskUpdated, task, nil)
+}
+
+// Record записывает задачу и её ревизию одним событием typ и присваивает
+// ревизии следующий номер.
+func (r *EventRepository) Record(ctx context.Context, typ domain.EventType, task *domain.Task, rev *domain.Revision) error {
+	return r.write(ctx, func(tx *eventTx) error {
+		return tx.Record(ctx, typ, task, rev)
+	})
+}
+
+func (r *EventRepository) Delete(ctx context.Context, id int) error {
+	return r.write(ctx, func(tx *eventTx) error {
+		return tx.Delete(ctx, id)
+	})
+}
+
+func (r *EventRepository) AppendRevision(ctx context.Context, rev *domain.Revision) error {
+	return r.write(ctx, func(tx *eventTx) error {
+		return tx.AppendRevision(ctx, rev)
+	})
+}
+
+func (r *EventRepository) ListRevisions(ctx context.Context, taskID int) ([]*domain.Revision, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	revisions := make([]*domain.Revision, 0, len(r.state.Revisions[taskID]))
+	for _, rev := range r.state.Revisions[taskID] {
+		revisions = append(revisions, rev.Clone())
+	}
+	return revisions, nil
+}
+
+// WithinTx копит изменения fn в repository.Buffer и записывает их одним
+// кадром журнала. Если fn или запись вернули ошибку, ни журнал, ни
+// проекция не меняются.
+func (r *EventRepository) WithinTx(ctx context.Context, fn func(tx repository.TaskRepository) error) error {
+	b := repository.NewBuffer(r)
+	if err := fn(b); err != nil {
+		return err
+	}
+	return r.write(ctx, func(tx *eventTx) error {
+		return b.CommitTo(ctx, tx)
+	})
+}
+
+// write выполняет fn под блокировкой на запись и дописывает события fn в
+// журнал одним кадром.
+func (r *EventRepository) write(ctx context.Context, fn func(tx *eventTx) error) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.closed {
+		return os.ErrClosed
+	}
+	tx := &eventTx{r: r, seq: r.state.Seq}
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+	if err := tx.commit(); err != nil {
+		tx.rollback()
+		return err
+	}
+	return nil
+}
+
+// eventTx — запись в EventRepository под его блокировкой. События сразу
+// применяются к проекции, а в журнал попадают в commit; rollback
+// возвращает проекцию к состоянию до транзакции.
+type eventTx struct {
+	r      *EventRepository
+	seq    uint64
+	events []*domain.Event
+	undo   []eventUndo
+}
+
+// eventUndo — задача и её ревизии до события.
+type eventUndo struct {
+	taskID    int
+	task      *domain.Task
+	revisions []*domain.Revision
+}
+
+func (tx *eventTx) Create(ctx context.Context, task *domain.Task) error {
+	return tx.Record(ctx, domain.EventTaskCreated, task, nil)
+}
+
+func (tx *eventTx) Get(ctx context.Context, id int) (*domain.Task, error) {
+	task, ok := tx.r.state.Tasks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return task.Clone(), nil
+}
+
+func (tx *eventTx) List(ctx context.Context) ([]*domain.Task, error) {
+	tasks := make([]*domain.Task, 0, len(tx.r.state.Tasks))
+	for _, task := range tx.r.state.Tasks {
+		tasks = append(tasks, task.Clone())
+	}
+	return tasks, nil
+}
+
+func (tx *eventTx) Update(ctx context.Context, task *domain.Task) error {
+	return tx.Record(ctx, domain.EventTaskUpdated, task, nil)
+}
+
+func (tx *eventTx) Record(ctx context.Context, typ domain.EventType, task *domain.Task, rev *domain.Revision) error {
+	e := &domain.Event{Type: typ, TaskID: task.ID, Version: task.Version}
+	current, exists := tx.r.state.Tasks[task.ID]
+	switch {
+	case typ == domain.EventTaskCreated && exists:
+		return domain.ErrAlreadyExists
+	case typ == domain.EventTaskCreated:
+		e.Task = task.Clone()
+	case !exists:
+		return domain.ErrNotFound
+	default:
+		e.Change = domain.NewTaskChange(current, task)
+	}
+	if rev != nil {
+		rev.Number = len(tx.r.state.Revisions[task.ID]) + 1
+		e.Revision = rev.Clone()
+		e.Revision.Snapshot = nil
+	}
+	return tx.emit(ctx, e)
+}
+
+func (tx *eventTx) Delete(ctx context.Context, id int) error {
+	if _, ok := tx.r.state.Tasks[id]; !ok {
+		return domain.ErrNotFound
+	}
+	return tx.emit(ctx, &domain.Event{Type: domain.EventTaskPurged, TaskID: id})
+}
+
+func (tx *eventTx) AppendRevision(ctx context.Context, rev *domain.Revision) error {
+	if _, ok := tx.r.state.Tasks[rev.TaskID]; !ok {
+		return domain.ErrNotFound
+	}
+	rev.Number = len(tx.r.state.Revisions[rev.TaskID]) + 1
+	return tx.emit(ctx, &domain.Event{Type: domain.EventRevisionRecorded, TaskID: rev.TaskID, Revision: rev.Clone()})
+}
+
+func (tx *eventTx) ListRevisions(ctx context.Context, taskID int) ([]*domain.Revision, error) {
+	revisions := make([]*domain.Revision, 0, len(tx.r.state.Revisions[taskID]))
+	for _, rev := range tx.r.state.Revisions[taskID] {
+		revisions = append(revisions, rev.Clone())
+	}
+	return revisions, nil
+}
+
+// emit нумерует событие и применяет его к проекции.
+func (tx *eventTx) emit(ctx context.Context, e *domain.Event) error {
+	state := tx.r.state
+	e.Seq = state.Seq + 1
+	e.Actor = domain.ActorFrom(ctx)
+	e.At = time.Now()
+
+	tx.undo = append(tx.undo, eventUndo{
+		taskID:    e.TaskID,
+		task:      state.Tasks[e.TaskID],
+		revisions: state.Revisions[e.TaskID],
+	})
+	if err := state.Apply(e); err != nil {
+		return err
+	}
+	tx.events = append(tx.events, e)
+	return nil
+}
+
+// commit дописывает события транзакции в журнал: одно событие — объектом,
+// несколько — массивом в одном кадре.
+func (tx *eventTx) commit() error {
+	var payload []byte
+	var err error
+	switch len(tx.events) {
+	case 0:
+		return nil
+	case 1:
+		payload, err = json.Marshal(tx.events[0])
+	default:
+		payload, err = json.Marshal(tx.events)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.r.log.append(payload, tx.r.opts.FsyncPolicy)
+}
+
+func (tx *eventTx) rollback() {
+	state := tx.r.state
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		u := tx.undo[i]
+		if u.task != nil {
+			state.Tasks[u.taskID] = u.task
+		} else {
+			delete(state.Tasks, u.taskID)
+		}
+		if u.revisions != nil {
+			state.Revisions[u.taskID] = u.revisions
+		} else {
+			delete(state.Revisions, u.taskID)
+		}
+	}
+	state.Seq = tx.seq
+	tx.events, tx.undo = nil, nil
+}
+
+func (r *EventRepository) Close() error {
+	r.mtx.Lock()
+	if r.closed {
+		r.mtx.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mtx.Unlock()
+
+	close(r.stop)
+	r.done.Wait()
+	return r.log.close()
+}
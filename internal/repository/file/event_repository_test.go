@@ -0,0 +1,203 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func TestEventRepository(t *testing.T) {
+	repositorytest.RunTaskRepository(t, func(t *testing.T) repository.TaskRepository {
+		repo, err := OpenEventRepository(t.TempDir(), Options{})
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
+
+func TestEventRepositoryReplaysLog(t *testing.T) {
+	dir := t.TempDir()
+	ctx := domain.WithActor(context.Background(), "alice")
+
+	repo, err := OpenEventRepository(dir, Options{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	task := domain.NewTask(1, "Task", "Description")
+	task.Version = 1
+	if err := repo.Record(ctx, domain.EventTaskCreated, task, &domain.Revision{TaskID: 1, Action: domain.ActionCreate}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	task.Headline = "Renamed"
+	task.Version = 2
+	if err := repo.Record(ctx, domain.EventTaskContentUpdated, task, &domain.Revision{TaskID: 1, Action: domain.ActionUpdate}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	now := time.Now()
+	if err := task.Transition(domain.StatusDone, now); err != nil {
+		t.Fatal(err)
+	}
+	task.ArchivedAt = &now
+	task.Version = 3
+	if err := repo.Record(ctx, domain.EventTaskCompleted, task, nil); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	// Переоткрытие достаёт задачу из архива: событие одно, полей два
+	if err := task.Transition(domain.StatusInProgress, now); err != nil {
+		t.Fatal(err)
+	}
+	task.Version = 4
+	if err := repo.Record(ctx, domain.EventTaskStatusChanged, task, nil); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if err := repo.Create(ctx, domain.NewTask(2, "Task 2", "Description")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Delete(ctx, 2); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	// Эмулируем аварийную остановку
+	repo.log.f.Close()
+
+	reopened, err := OpenEventRepository(dir, Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+
+	if reopened.Recovery().Replayed != 6 || reopened.Seq() != 6 {
+		t.Errorf("replayed %d, seq %d", reopened.Recovery().Replayed, reopened.Seq())
+	}
+	got, err := reopened.Get(ctx, 1)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Headline != "Renamed" || got.CurrentStatus() != domain.StatusInProgress || got.IsArchived() || got.Version != 4 {
+		t.Errorf("projection: %+v", got)
+	}
+	revisions, err := reopened.ListRevisions(ctx, 1)
+	if err != nil || len(revisions) != 2 || revisions[1].Number != 2 || revisions[1].Snapshot.Headline != "Renamed" {
+		t.Errorf("revisions: %+v, %v", revisions, err)
+	}
+	if _, err := reopened.Get(ctx, 2); err != domain.ErrNotFound {
+		t.Errorf("purged task: %v", err)
+	}
+
+	var events []*domain.Event
+	err = ReadEvents(dir, func(e *domain.Event) error {
+		if e.Actor != "alice" {
+			t.Errorf("event %d actor: %s", e.Seq, e.Actor)
+		}
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read events: %v", err)
+	}
+	want := []domain.EventType{
+		domain.EventTaskCreated, domain.EventTaskContentUpdated, domain.EventTaskCompleted,
+		domain.EventTaskStatusChanged, domain.EventTaskCreated, domain.EventTaskPurged,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events: %v", events)
+	}
+	for i := range want {
+		if events[i].Type != want[i] {
+			t.Errorf("event %d: %s != %s", i+1, events[i].Type, want[i])
+		}
+	}
+	if c := events[1].Change; c == nil || c.Content == nil || c.Status != nil || events[1].Task != nil || events[1].Revision == nil {
+		t.Errorf("content event: %+v", events[1])
+	}
+	if c := events[3].Change; c == nil || c.Content != nil || c.Status == nil || c.Archived == nil || c.Archived.At != nil {
+		t.Errorf("reopen event: %+v", events[3])
+	}
+}
+
+func TestEventRepositoryTransaction(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	repo, err := OpenEventRepository(dir, Options{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := repo.Create(ctx, domain.NewTask(1, "Task", "Description")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	failure := errors.New("failure")
+	err = repo.WithinTx(ctx, func(tx repository.TaskRepository) error {
+		if err := tx.Create(ctx, domain.NewTask(2, "New", "Description")); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) || repo.Seq() != 1 {
+		t.Fatalf("failed tx: %v, seq %d", err, repo.Seq())
+	}
+
+	err = repo.WithinTx(ctx, func(tx repository.TaskRepository) error {
+		task := domain.NewTask(2, "New", "Description")
+		if err := repository.Record(ctx, tx, domain.EventTaskCreated, task, &domain.Revision{TaskID: 2, Action: domain.ActionCreate}); err != nil {
+			return err
+		}
+		task.Headline = "Renamed"
+		if err := repository.Record(ctx, tx, domain.EventTaskContentUpdated, task, &domain.Revision{TaskID: 2, Action: domain.ActionUpdate}); err != nil {
+			return err
+		}
+		return tx.Delete(ctx, 1)
+	})
+	if err != nil {
+		t.Fatalf("tx: %v", err)
+	}
+
+	// Сбой записи откатывает и проекцию
+	repo.log.f.Close()
+	err = repo.WithinTx(ctx, func(tx repository.TaskRepository) error {
+		return tx.Create(ctx, domain.NewTask(3, "Lost", "Description"))
+	})
+	if err == nil {
+		t.Fatal("write to closed log succeeded")
+	}
+	if _, err := repo.Get(ctx, 3); !errors.Is(err, domain.ErrNotFound) || repo.Seq() != 4 {
+		t.Errorf("rolled back task: %v, seq %d", err, repo.Seq())
+	}
+
+	f, err := os.Open(filepath.Join(dir, eventLogName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	frames := 0
+	if _, err := readFrames(f, func([]byte) error { frames++; return nil }); err != nil || frames != 2 {
+		t.Errorf("frames: %d, %v", frames, err)
+	}
+
+	reopened, err := OpenEventRepository(dir, Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	if reopened.Seq() != 4 {
+		t.Errorf("seq %d", reopened.Seq())
+	}
+	if _, err := reopened.Get(ctx, 1); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("deleted task: %v", err)
+	}
+	task, err := reopened.Get(ctx, 2)
+	if err != nil || task.Headline != "Renamed" {
+		t.Errorf("task: %+v, %v", task, err)
+	}
+	if revisions, _ := reopened.ListRevisions(ctx, 2); len(revisions) != 2 {
+		t.Errorf("revisions: %+v", revisions)
+	}
+}
@@ -0,0 +1,340 @@
+package file
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	opCreate   = "create"
+	opUpdate   = "update"
+	opComplete = "complete"
+	opDelete   = "delete"
+)
+
+type record[K comparable, V any] struct {
+	Seq   uint64 `json:"seq"`
+	Op    string `json:"op"`
+	Key   K      `json:"key"`
+	Value V      `json:"value,omitempty"`
+}
+
+type snapshot[V any] struct {
+	Seq   uint64    `json:"seq"`
+	Time  time.Time `json:"time"`
+	Items []V       `json:"items"`
+}
+
+// Recovery описывает, что было восстановлено при открытии хранилища.
+type Recovery struct {
+	SnapshotSeq    uint64
+	SnapshotItems  int
+	Replayed       int
+	TruncatedBytes int64
+}
+
+// store хранит коллекцию в памяти и журналирует каждое изменение в WAL до
+// того, как оно станет видимым. Снапшоты периодически сжимают журнал.
+type store[K comparable, V any] struct {
+	walPath      string
+	snapshotPath string
+	opts         Options
+	key          func(V) K
+	clone        func(V) V
+
+	mtx     sync.RWMutex
+	items   map[K]V
+	wal     *wal
+	seq     uint64
+	pending int
+	closed  bool
+
+	recovery Recovery
+	stop     chan struct{}
+	done     sync.WaitGroup
+}
+
+func openStore[K comparable, V any](dir string, name string, opts Options, key func(V) K, clone func(V) V) (*store[K, V], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &store[K, V]{
+		walPath:      filepath.Join(dir, name+".wal"),
+		snapshotPath: filepath.Join(dir, name+".snapshot"),
+		opts:         opts.withDefaults(),
+		key:          key,
+		clone:        clone,
+		items:        make(map[K]V),
+		stop:         make(chan struct{}),
+	}
+
+	if err := s.loadSnapshot(); err != nil {
+		return nil, fmt.Errorf("load snapshot %s: %w", s.snapshotPath, err)
+	}
+
+	w, err := openWAL(s.walPath)
+	if err != nil {
+		return nil, err
+	}
+	truncated, err := w.replay(s.applyPayload)
+	if err != nil {
+		w.f.Close()
+		return nil, fmt.Errorf("replay %s: %w", s.walPath, err)
+	}
+	s.wal = w
+	s.recovery.TruncatedBytes = truncated
+
+	s.startBackground()
+	return s, nil
+}
+
+func (s *store[K, V]) loadSnapshot() error {
+	b, err := os.ReadFile(s.snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snap snapshot[V]
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return err
+	}
+	for _, item := range snap.Items {
+		s.items[s.key(item)] = item
+	}
+	s.seq = snap.Seq
+	s.recovery.SnapshotSeq = snap.Seq
+	s.recovery.SnapshotItems = len(snap.Items)
+	return nil
+}
+
+func (s *store[K, V]) applyPayload(payload []byte) error {
+	var rec record[K, V]
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return err
+	}
+	// Записи, уже попавшие в снапшот, пропускаем: журнал мог не успеть
+	// очиститься после записи снапшота
+	if rec.Seq <= s.seq {
+		return nil
+	}
+	s.apply(rec)
+	s.seq = rec.Seq
+	s.pending++
+	s.recovery.Replayed++
+	return nil
+}
+
+func (s *store[K, V]) apply(rec record[K, V]) {
+	if rec.Op == opDelete {
+		delete(s.items, rec.Key)
+		return
+	}
+	s.items[rec.Key] = rec.Value
+}
+
+func (s *store[K, V]) startBackground() {
+	if s.opts.FsyncPolicy == FsyncInterval {
+		s.runEvery(s.opts.FsyncInterval, func() error {
+			s.mtx.Lock()
+			defer s.mtx.Unlock()
+			if s.closed {
+				return nil
+			}
+			return s.wal.sync()
+		})
+	}
+	if s.opts.SnapshotInterval > 0 {
+		s.runEvery(s.opts.SnapshotInterval, func() error {
+			s.mtx.Lock()
+			defer s.mtx.Unlock()
+			if s.closed || s.pending == 0 {
+				return nil
+			}
+			return s.snapshotLocked()
+		})
+	}
+}
+
+func (s *store[K, V]) runEvery(interval time.Duration, fn func() error) {
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if err := fn(); err != nil {
+					s.opts.OnError(err)
+				}
+			}
+		}
+	}()
+}
+
+func (s *store[K, V]) get(key K) (V, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	item, ok := s.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return s.clone(item), true
+}
+
+func (s *store[K, V]) list() []V {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	items := make([]V, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, s.clone(item))
+	}
+	return items
+}
+
+// write журналирует изменение и применяет его к состоянию в памяти.
+// check вызывается под блокировкой с текущим значением, решает, допустима
+// ли операция, и возвращает её тип для журнала (например, update или complete).
+func (s *store[K, V]) write(key K, value V, check func(current V, exists bool) (string, error)) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.closed {
+		return os.ErrClosed
+	}
+
+	current, exists := s.items[key]
+	op, err := check(current, exists)
+	if err != nil {
+		return err
+	}
+
+	rec := record[K, V]{Seq: s.seq + 1, Op: op, Key: key}
+	if op != opDelete {
+		rec.Value = s.clone(value)
+	}
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := s.wal.append(payload, s.opts.FsyncPolicy); err != nil {
+		return err
+	}
+
+	s.apply(rec)
+	s.seq = rec.Seq
+	s.pending++
+
+	if s.opts.SnapshotThreshold > 0 && s.pending >= s.opts.SnapshotThreshold {
+		if err := s.snapshotLocked(); err != nil {
+			// Запись уже в журнале, поэтому операция успешна; снапшот
+			// повторится позже
+			s.opts.OnError(err)
+		}
+	}
+	return nil
+}
+
+func (s *store[K, V]) snapshotLocked() error {
+	snap := snapshot[V]{
+		Seq:   s.seq,
+		Time:  time.Now(),
+		Items: make([]V, 0, len(s.items)),
+	}
+	for _, item := range s.items {
+		snap.Items = append(snap.Items, item)
+	}
+
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.snapshotPath, b); err != nil {
+		return err
+	}
+
+	// Снапшот на диске покрывает весь журнал, его можно очистить
+	if err := s.wal.reset(); err != nil {
+		return err
+	}
+	s.pending = 0
+	return nil
+}
+
+func (s *store[K, V]) snapshot() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.closed {
+		return os.ErrClosed
+	}
+	return s.snapshotLocked()
+}
+
+func (s *store[K, V]) close() error {
+	s.mtx.Lock()
+	if s.closed {
+		s.mtx.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mtx.Unlock()
+
+	close(s.stop)
+	s.done.Wait()
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var snapErr error
+	if s.pending > 0 {
+		snapErr = s.snapshotLocked()
+	}
+	return errors.Join(snapErr, s.wal.close())
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
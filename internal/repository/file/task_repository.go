@@ -0,0 +1,165 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.TaskRepository = (*TaskRepository)(nil)
+
+// TaskRepository хранит задачи в памяти, а на диске ведёт журнал операций
+// (tasks.wal) и сжатые снапшоты (tasks.snapshot) в каталоге dir. Ревизии
+// задач хранятся так же в revisions.wal и revisions.snapshot.
+type TaskRepository struct {
+	store     *store[int, *domain.Task]
+	revisions *store[revisionKey, *domain.Revision]
+
+	// revMtx упорядочивает запись ревизий, last — последний номер ревизии
+	// каждой задачи
+	revMtx sync.Mutex
+	last   map[int]int
+}
+
+type revisionKey struct {
+	TaskID int `json:"task_id"`
+	Number int `json:"number"`
+}
+
+func OpenTaskRepository(dir string, opts Options) (*TaskRepository, error) {
+	s, err := openStore(dir, "tasks", opts,
+		func(t *domain.Task) int { return t.ID },
+		func(t *domain.Task) *domain.Task { return t.Clone() },
+	)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := openStore(dir, "revisions", opts,
+		func(r *domain.Revision) revisionKey { return revisionKey{TaskID: r.TaskID, Number: r.Number} },
+		func(r *domain.Revision) *domain.Revision { return r.Clone() },
+	)
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+
+	last := make(map[int]int)
+	for _, rev := range revisions.list() {
+		last[rev.TaskID] = max(last[rev.TaskID], rev.Number)
+	}
+	return &TaskRepository{store: s, revisions: revisions, last: last}, nil
+}
+
+func (r *TaskRepository) Recovery() Recovery {
+	return r.store.recovery
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	return r.store.write(task.ID, task, func(_ *domain.Task, exists bool) (string, error) {
+		if exists {
+			return "", domain.ErrAlreadyExists
+		}
+		return opCreate, nil
+	})
+}
+
+func (r *TaskRepository) Get(ctx context.Context, id int) (*domain.Task, error) {
+	task, ok := r.store.get(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return task, nil
+}
+
+func (r *TaskRepository) List(ctx context.Context) ([]*domain.Task, error) {
+	return r.store.list(), nil
+}
+
+func (r *TaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	return r.store.write(task.ID, task, func(current *domain.Task, exists bool) (string, error) {
+		if !exists {
+			return "", domain.ErrNotFound
+		}
+		if !current.Done && task.Done {
+			return opComplete, nil
+		}
+		return opUpdate, nil
+	})
+}
+
+func (r *TaskRepository) Delete(ctx context.Context, id int) error {
+	err := r.store.write(id, nil, func(_ *domain.Task, exists bool) (string, error) {
+		if !exists {
+			return "", domain.ErrNotFound
+		}
+		return opDelete, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.revMtx.Lock()
+	defer r.revMtx.Unlock()
+
+	for number := 1; number <= r.last[id]; number++ {
+		err := r.revisions.write(revisionKey{TaskID: id, Number: number}, nil, func(_ *domain.Revision, exists bool) (string, error) {
+			if !exists {
+				return "", domain.ErrNotFound
+			}
+			return opDelete, nil
+		})
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+	}
+	delete(r.last, id)
+	return nil
+}
+
+func (r *TaskRepository) AppendRevision(ctx context.Context, rev *domain.Revision) error {
+	if _, ok := r.store.get(rev.TaskID); !ok {
+		return domain.ErrNotFound
+	}
+
+	r.revMtx.Lock()
+	defer r.revMtx.Unlock()
+
+	rev.Number = r.last[rev.TaskID] + 1
+	err := r.revisions.write(revisionKey{TaskID: rev.TaskID, Number: rev.Number}, rev, func(_ *domain.Revision, exists bool) (string, error) {
+		if exists {
+			return "", domain.ErrAlreadyExists
+		}
+		return opCreate, nil
+	})
+	if err != nil {
+		return err
+	}
+	r.last[rev.TaskID] = rev.Number
+	return nil
+}
+
+func (r *TaskRepository) ListRevisions(ctx context.Context, taskID int) ([]*domain.Revision, error) {
+	r.revMtx.Lock()
+	last := r.last[taskID]
+	r.revMtx.Unlock()
+
+	revisions := make([]*domain.Revision, 0, last)
+	for number := 1; number <= last; number++ {
+		if rev, ok := r.revisions.get(revisionKey{TaskID: taskID, Number: number}); ok {
+			revisions = append(revisions, rev)
+		}
+	}
+	return revisions, nil
+}
+
+// Snapshot принудительно записывает снапшоты и очищает журналы.
+func (r *TaskRepository) Snapshot() error {
+	return errors.Join(r.store.snapshot(), r.revisions.snapshot())
+}
+
+func (r *TaskRepository) Close() error {
+	return errors.Join(r.store.close(), r.revisions.close())
+}
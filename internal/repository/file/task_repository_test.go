@@ -0,0 +1,225 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func TestTaskRepository(t *testing.T) {
+	repositorytest.RunTaskRepository(t, func(t *testing.T) repository.TaskRepository {
+		repo := openTestRepository(t, t.TempDir(), Options{})
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
+
+func openTestRepository(t *testing.T, dir string, opts Options) *TaskRepository {
+	t.Helper()
+	repo, err := OpenTaskRepository(dir, opts)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return repo
+}
+
+func TestTaskRepositoryPersistsAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestRepository(t, dir, Options{})
+	if err := repo.Create(ctx, domain.NewTask(1, "Task 1", "Description 1")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Create(ctx, domain.NewTask(2, "Task 2", "Description 2")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	task, _ := repo.Get(ctx, 1)
+	completedAt := time.Now()
+	task.Done = true
+	task.CompletedAt = &completedAt
+	if err := repo.Update(ctx, task); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.Delete(ctx, 2); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// Эмулируем аварийную остановку: файл журнала закрывается без снапшота
+	repo.store.wal.f.Close()
+
+	reopened := openTestRepository(t, dir, Options{})
+	defer reopened.Close()
+
+	if got := reopened.Recovery().Replayed; got != 4 {
+		t.Errorf("replayed != 4: %d", got)
+	}
+	restored, err := reopened.Get(ctx, 1)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !restored.Done || restored.CompletedAt == nil {
+		t.Error("completion lost")
+	}
+	if _, err := reopened.Get(ctx, 2); err != domain.ErrNotFound {
+		t.Errorf("want ErrNotFound, got %v", err)
+	}
+}
+
+func TestTaskRepositorySnapshotCompactsWAL(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestRepository(t, dir, Options{SnapshotThreshold: 3})
+	for id := 1; id <= 4; id++ {
+		if err := repo.Create(ctx, domain.NewTask(id, "Task", "Description")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	repo.store.wal.f.Close()
+
+	reopened := openTestRepository(t, dir, Options{})
+	defer reopened.Close()
+
+	recovery := reopened.Recovery()
+	if recovery.SnapshotItems != 3 {
+		t.Errorf("snapshot items != 3: %d", recovery.SnapshotItems)
+	}
+	if recovery.Replayed != 1 {
+		t.Errorf("replayed != 1: %d", recovery.Replayed)
+	}
+	tasks, _ := reopened.List(ctx)
+	if len(tasks) != 4 {
+		t.Errorf("len != 4: %d", len(tasks))
+	}
+}
+
+func TestTaskRepositoryPersistsRevisions(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestRepository(t, dir, Options{})
+	task := domain.NewTask(1, "Task", "Description")
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for range 2 {
+		rev := &domain.Revision{TaskID: 1, Action: domain.ActionUpdate, At: time.Now(), Snapshot: task}
+		if err := repo.AppendRevision(ctx, rev); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	reopened := openTestRepository(t, dir, Options{})
+	defer reopened.Close()
+
+	rev := &domain.Revision{TaskID: 1, Action: domain.ActionUpdate, At: time.Now(), Snapshot: task}
+	if err := reopened.AppendRevision(ctx, rev); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if rev.Number != 3 {
+		t.Errorf("numbering restarted: %d", rev.Number)
+	}
+	revisions, _ := reopened.ListRevisions(ctx, 1)
+	if len(revisions) != 3 {
+		t.Errorf("len != 3: %d", len(revisions))
+	}
+}
+
+func TestTaskRepositoryTruncatesTornTail(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestRepository(t, dir, Options{})
+	if err := repo.Create(ctx, domain.NewTask(1, "Task", "Description")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	repo.store.wal.f.Close()
+
+	// Половина кадра: заголовок обещает 100 байт, а записано только 3
+	walPath := filepath.Join(dir, "tasks.wal")
+	f, err := os.OpenFile(walPath, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 100, 1, 2, 3, 4, '{', '"', 's'})
+	f.Close()
+
+	reopened := openTestRepository(t, dir, Options{})
+	if reopened.Recovery().TruncatedBytes != 11 {
+		t.Errorf("truncated != 11: %d", reopened.Recovery().TruncatedBytes)
+	}
+	if _, err := reopened.Get(ctx, 1); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if err := reopened.Create(ctx, domain.NewTask(2, "Task", "Description")); err != nil {
+		t.Fatalf("create after truncate: %v", err)
+	}
+	reopened.store.wal.f.Close()
+
+	again := openTestRepository(t, dir, Options{})
+	defer again.Close()
+	tasks, _ := again.List(ctx)
+	if len(tasks) != 2 {
+		t.Errorf("len != 2: %d", len(tasks))
+	}
+}
+
+func TestTaskRepositoryCloseWritesSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestRepository(t, dir, Options{FsyncPolicy: FsyncInterval, FsyncInterval: time.Millisecond})
+	if err := repo.Create(ctx, domain.NewTask(1, "Task", "Description")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := repo.Create(ctx, domain.NewTask(2, "Task", "Description")); err != os.ErrClosed {
+		t.Errorf("want ErrClosed, got %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "tasks.wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("wal not compacted: %d", info.Size())
+	}
+
+	reopened := openTestRepository(t, dir, Options{})
+	defer reopened.Close()
+	if reopened.Recovery().SnapshotItems != 1 {
+		t.Errorf("snapshot items != 1: %d", reopened.Recovery().SnapshotItems)
+	}
+}
+
+func TestParseFsyncPolicy(t *testing.T) {
+	cases := map[string]FsyncPolicy{
+		"always":   FsyncAlways,
+		"interval": FsyncInterval,
+		"never":    FsyncNever,
+	}
+	for s, want := range cases {
+		got, err := ParseFsyncPolicy(s)
+		if err != nil {
+			t.Errorf("%s: error: %v", s, err)
+		}
+		if got != want {
+			t.Errorf("%s: %v != %v", s, got, want)
+		}
+	}
+	if _, err := ParseFsyncPolicy("sometimes"); err == nil {
+		t.Error("no error")
+	}
+}
@@ -0,0 +1,177 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.TokenRepository = (*TokenRepository)(nil)
+
+// TokenRepository хранит токены обновления в refresh_tokens.wal и
+// отозванные токены доступа в revoked_tokens.wal со снапшотами в каталоге
+// dir.
+type TokenRepository struct {
+	refresh *store[int, *domain.RefreshToken]
+	revoked *store[string, *domain.RevokedToken]
+	// mtx делает чтение токена и запись изменённой копии одной операцией
+	mtx sync.Mutex
+}
+
+func OpenTokenRepository(dir string, opts Options) (*TokenRepository, error) {
+	refresh, err := openStore(dir, "refresh_tokens", opts,
+		func(t *domain.RefreshToken) int { return t.ID },
+		func(t *domain.RefreshToken) *domain.RefreshToken { return t.Clone() },
+	)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := openStore(dir, "revoked_tokens", opts,
+		func(t *domain.RevokedToken) string { return t.ID },
+		func(t *domain.RevokedToken) *domain.RevokedToken { return t.Clone() },
+	)
+	if err != nil {
+		refresh.close()
+		return nil, err
+	}
+	return &TokenRepository{refresh: refresh, revoked: revoked}, nil
+}
+
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, err := r.GetRefreshToken(ctx, token.Hash); err == nil {
+		return domain.ErrAlreadyExists
+	}
+	return r.refresh.write(token.ID, token, func(_ *domain.RefreshToken, exists bool) (string, error) {
+		if exists {
+			return "", domain.ErrAlreadyExists
+		}
+		return opCreate, nil
+	})
+}
+
+func (r *TokenRepository) GetRefreshToken(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	for _, token := range r.refresh.list() {
+		if token.Hash == hash {
+			return token, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *TokenRepository) UseRefreshToken(ctx context.Context, id int, at time.Time) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	token, ok := r.refresh.get(id)
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if token.UsedAt != nil {
+		return domain.ErrTokenReused
+	}
+	token.UsedAt = &at
+	return r.writeRefresh(token)
+}
+
+func (r *TokenRepository) RevokeRefreshFamily(ctx context.Context, familyID int, at time.Time) error {
+	return r.revokeRefresh(func(token *domain.RefreshToken) bool { return token.FamilyID == familyID }, at)
+}
+
+func (r *TokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID int, at time.Time) error {
+	return r.revokeRefresh(func(token *domain.RefreshToken) bool { return token.UserID == userID }, at)
+}
+
+func (r *TokenRepository) revokeRefresh(match func(token *domain.RefreshToken) bool, at time.Time) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, token := range r.refresh.list() {
+		if !match(token) || token.RevokedAt != nil {
+			continue
+		}
+		token.RevokedAt = &at
+		if err := r.writeRefresh(token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TokenRepository) writeRefresh(token *domain.RefreshToken) error {
+	return r.refresh.write(token.ID, token, func(_ *domain.RefreshToken, exists bool) (string, error) {
+		if !exists {
+			return "", domain.ErrNotFound
+		}
+		return opUpdate, nil
+	})
+}
+
+func (r *TokenRepository) RevokeAccessToken(ctx context.Context, token *domain.RevokedToken) error {
+	err := r.revoked.write(token.ID, token, func(_ *domain.RevokedToken, exists bool) (string, error) {
+		if exists {
+			return "", domain.ErrAlreadyExists
+		}
+		return opCreate, nil
+	})
+	if errors.Is(err, domain.ErrAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	_, revoked := r.revoked.get(id)
+	return revoked, nil
+}
+
+func (r *TokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	n := 0
+	for _, token := range r.refresh.list() {
+		if !token.ExpiresAt.Before(before) {
+			continue
+		}
+		if err := deleteItem(r.refresh, token.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+	for _, token := range r.revoked.list() {
+		if !token.ExpiresAt.Before(before) {
+			continue
+		}
+		if err := deleteItem(r.revoked, token.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// deleteItem удаляет запись, если она ещё есть.
+func deleteItem[K comparable, V any](s *store[K, V], key K) error {
+	var zero V
+	err := s.write(key, zero, func(_ V, exists bool) (string, error) {
+		if !exists {
+			return "", domain.ErrNotFound
+		}
+		return opDelete, nil
+	})
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (r *TokenRepository) Close() error {
+	return errors.Join(r.refresh.close(), r.revoked.close())
+}
@@ -0,0 +1,64 @@
+package file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func TestTokenRepository(t *testing.T) {
+	repositorytest.RunTokenRepository(t, func(t *testing.T) (repository.UserRepository, repository.TokenRepository) {
+		dir := t.TempDir()
+		users, err := OpenUserRepository(dir, Options{})
+		if err != nil {
+			t.Fatalf("open users: %v", err)
+		}
+		t.Cleanup(func() { users.Close() })
+		tokens, err := OpenTokenRepository(dir, Options{})
+		if err != nil {
+			t.Fatalf("open tokens: %v", err)
+		}
+		t.Cleanup(func() { tokens.Close() })
+		return users, tokens
+	})
+}
+
+func TestTokenRepositoryPersistsAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	now := time.Now()
+
+	repo, err := OpenTokenRepository(dir, Options{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	token := &domain.RefreshToken{ID: 1, UserID: 1, FamilyID: 1, Hash: "h1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	if err := repo.CreateRefreshToken(ctx, token); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.UseRefreshToken(ctx, 1, now); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+	if err := repo.RevokeAccessToken(ctx, &domain.RevokedToken{ID: "jti", UserID: 1, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	repo, err = OpenTokenRepository(dir, Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer repo.Close()
+	if err := repo.UseRefreshToken(ctx, 1, now); err == nil {
+		t.Error("used token is usable after restart")
+	}
+	if revoked, _ := repo.IsAccessTokenRevoked(ctx, "jti"); !revoked {
+		t.Error("revocation lost after restart")
+	}
+}
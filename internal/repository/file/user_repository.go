@@ -0,0 +1,91 @@
+package file
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.UserRepository = (*UserRepository)(nil)
+
+// UserRepository хранит пользователей в памяти с журналом users.wal и
+// снапшотами users.snapshot в каталоге dir.
+type UserRepository struct {
+	store *store[int, *domain.User]
+	// mtx делает проверку имени и запись, а также чтение пользователя и
+	// запись изменённой копии одной операцией
+	mtx sync.Mutex
+}
+
+func OpenUserRepository(dir string, opts Options) (*UserRepository, error) {
+	s, err := openStore(dir, "users", opts,
+		func(u *domain.User) int { return u.ID },
+		func(u *domain.User) *domain.User { return u.Clone() },
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &UserRepository{store: s}, nil
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, err := r.GetByName(ctx, user.Name); err == nil {
+		return domain.ErrAlreadyExists
+	}
+	return r.store.write(user.ID, user, func(_ *domain.User, exists bool) (string, error) {
+		if exists {
+			return "", domain.ErrAlreadyExists
+		}
+		return opCreate, nil
+	})
+}
+
+func (r *UserRepository) Get(ctx context.Context, id int) (*domain.User, error) {
+	user, ok := r.store.get(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetByName(ctx context.Context, name string) (*domain.User, error) {
+	for _, user := range r.store.list() {
+		if user.Name == name {
+			return user, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *UserRepository) List(ctx context.Context) ([]*domain.User, error) {
+	users := r.store.list()
+	slices.SortFunc(users, func(a, b *domain.User) int { return a.ID - b.ID })
+	return users, nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, hash string) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	user, ok := r.store.get(id)
+	if !ok {
+		return domain.ErrNotFound
+	}
+	user.PasswordHash = hash
+	return r.store.write(id, user, func(_ *domain.User, exists bool) (string, error) {
+		if !exists {
+			return "", domain.ErrNotFound
+		}
+		return opUpdate, nil
+	})
+}
+
+func (r *UserRepository) Close() error {
+	return r.store.close()
+}
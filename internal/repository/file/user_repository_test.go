@@ -0,0 +1,47 @@
+package file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func TestUserRepository(t *testing.T) {
+	repositorytest.RunUserRepository(t, func(t *testing.T) repository.UserRepository {
+		repo, err := OpenUserRepository(t.TempDir(), Options{})
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
+
+func TestUserRepositoryPersistsAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo, err := OpenUserRepository(dir, Options{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := repo.Create(ctx, &domain.User{ID: 1, Name: "alice", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	repo, err = OpenUserRepository(dir, Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer repo.Close()
+	if user, err := repo.GetByName(ctx, "alice"); err != nil || user.ID != 1 {
+		t.Errorf("after restart: %+v, %v", user, err)
+	}
+}
@@ -0,0 +1,219 @@
+package file
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Формат записи журнала: длина (4 байта) | CRC32-C (4 байта) | JSON.
+const frameHeaderSize = 8
+
+const maxFrameSize = 64 << 20
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type FsyncPolicy int
+
+const (
+	// FsyncAlways сбрасывает журнал на диск после каждой записи.
+	FsyncAlways FsyncPolicy = iota
+	// FsyncInterval сбрасывает журнал в фоне раз в Options.FsyncInterval.
+	FsyncInterval
+	// FsyncNever оставляет сброс на усмотрение ОС.
+	FsyncNever
+)
+
+func ParseFsyncPolicy(s string) (FsyncPolicy, error) {
+	switch strings.ToLower(s) {
+	case "always", "":
+		return FsyncAlways, nil
+	case "interval":
+		return FsyncInterval, nil
+	case "never", "none", "off":
+		return FsyncNever, nil
+	default:
+		return 0, fmt.Errorf("unknown fsync policy %q", s)
+	}
+}
+
+func (p FsyncPolicy) String() string {
+	switch p {
+	case FsyncAlways:
+		return "always"
+	case FsyncInterval:
+		return "interval"
+	case FsyncNever:
+		return "never"
+	default:
+		return "unknown"
+	}
+}
+
+type Options struct {
+	FsyncPolicy   FsyncPolicy
+	FsyncInterval time.Duration
+	// SnapshotInterval задаёт период фонового снапшота, 0 отключает его.
+	SnapshotInterval time.Duration
+	// SnapshotThreshold задаёт число записей журнала, после которого
+	// снапшот делается сразу, 0 отключает порог.
+	SnapshotThreshold int
+	// OnError вызывается при ошибках фоновых операций.
+	OnError func(err error)
+}
+
+func (o Options) withDefaults() Options {
+	if o.FsyncInterval <= 0 {
+		o.FsyncInterval = time.Second
+	}
+	if o.OnError == nil {
+		o.OnError = func(error) {}
+	}
+	return o
+}
+
+type wal struct {
+	f     *os.File
+	size  int64
+	dirty bool
+}
+
+func openWAL(path string) (*wal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &wal{f: f}, nil
+}
+
+// replay читает записи с начала журнала. Оборванный или повреждённый хвост
+// (например, после сбоя посреди записи) отрезается, число отброшенных байт
+// возвращается вызывающему.
+func (w *wal) replay(fn func(payload []byte) error) (int64, error) {
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	info, err := w.f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	offset, err := readFrames(w.f, fn)
+	if err != nil {
+		return 0, err
+	}
+
+	truncated := info.Size() - offset
+	if truncated > 0 {
+		if err := w.f.Truncate(offset); err != nil {
+			return 0, err
+		}
+		if err := w.f.Sync(); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := w.f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	w.size = offset
+	return truncated, nil
+}
+
+// readFrames читает целые кадры из r до конца или до первого оборванного
+// либо повреждённого кадра и возвращает смещение после последнего целого.
+func readFrames(r io.Reader, fn func(payload []byte) error) (int64, error) {
+	br := bufio.NewReader(r)
+	var offset int64
+	var header [frameHeaderSize]byte
+	for {
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return 0, err
+		}
+		size := binary.BigEndian.Uint32(header[0:4])
+		sum := binary.BigEndian.Uint32(header[4:8])
+		if size > maxFrameSize {
+			break
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return 0, err
+		}
+		if crc32.Checksum(payload, crcTable) != sum {
+			break
+		}
+		if err := fn(payload); err != nil {
+			return 0, err
+		}
+		offset += frameHeaderSize + int64(size)
+	}
+	return offset, nil
+}
+
+func (w *wal) append(payload []byte, policy FsyncPolicy) error {
+	if len(payload) > maxFrameSize {
+		return fmt.Errorf("record of %d bytes exceeds %d", len(payload), maxFrameSize)
+	}
+	frame := make([]byte, frameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.Checksum(payload, crcTable))
+	copy(frame[frameHeaderSize:], payload)
+
+	if _, err := w.f.Write(frame); err != nil {
+		// Откатываем частично записанный кадр, чтобы не оставить мусор
+		// посреди журнала
+		if terr := w.f.Truncate(w.size); terr == nil {
+			_, _ = w.f.Seek(w.size, io.SeekStart)
+		}
+		return err
+	}
+	w.size += int64(len(frame))
+
+	if policy == FsyncAlways {
+		return w.f.Sync()
+	}
+	w.dirty = true
+	return nil
+}
+
+func (w *wal) sync() error {
+	if !w.dirty {
+		return nil
+	}
+	if err := w.f.Sync(); err != nil {
+		return err
+	}
+	w.dirty = false
+	return nil
+}
+
+func (w *wal) reset() error {
+	if err := w.f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	w.size = 0
+	w.dirty = false
+	return w.f.Sync()
+}
+
+func (w *wal) close() error {
+	if err := w.sync(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
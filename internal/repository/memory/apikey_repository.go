@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.APIKeyRepository = (*APIKeyRepository)(nil)
+
+type APIKeyRepository struct {
+	keys   map[int]*domain.APIKey
+	byHash map[string]int
+	mtx    sync.RWMutex
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{
+		keys:   make(map[int]*domain.APIKey),
+		byHash: make(map[string]int),
+	}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, exists := r.keys[key.ID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	if _, exists := r.byHash[key.Hash]; exists {
+		return domain.ErrAlreadyExists
+	}
+
+	r.keys[key.ID] = key.Clone()
+	r.byHash[key.Hash] = key.ID
+	return nil
+}
+
+func (r *APIKeyRepository) Get(ctx context.Context, id int) (*domain.APIKey, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return key.Clone(), nil
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	id, ok := r.byHash[hash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return r.keys[id].Clone(), nil
+}
+
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID int) ([]*domain.APIKey, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	keys := make([]*domain.APIKey, 0)
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keys = append(keys, key.Clone())
+		}
+	}
+	sortAPIKeys(keys)
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	key.LastUsedAt = &at
+	return nil
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int, at time.Time) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	return nil
+}
+
+// sortAPIKeys упорядочивает ключи по времени создания.
+func sortAPIKeys(keys []*domain.APIKey) {
+	slices.SortFunc(keys, func(a, b *domain.APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+}
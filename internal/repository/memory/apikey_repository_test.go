@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	repositorytest.RunAPIKeyRepository(t, func(t *testing.T) (repository.UserRepository, repository.APIKeyRepository) {
+		return NewUserRepository(), NewAPIKeyRepository()
+	})
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.TaskRepository = (*TaskRepository)(nil)
+
+type TaskRepository struct {
+	tasks     map[int]*domain.Task
+	revisions map[int][]*domain.Revision
+	mtx       sync.RWMutex
+}
+
+func NewTaskRepository() *TaskRepository {
+	return &TaskRepository{
+		tasks:     make(map[int]*domain.Task),
+		revisions: make(map[int][]*domain.Revision),
+	}
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, exists := r.tasks[task.ID]; exists {
+		return domain.ErrAlreadyExists
+	}
+
+	r.tasks[task.ID] = task.Clone()
+	return nil
+}
+
+func (r *TaskRepository) Get(ctx context.Context, id int) (*domain.Task, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	return task.Clone(), nil
+}
+
+func (r *TaskRepository) List(ctx context.Context) ([]*domain.Task, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	tasks := make([]*domain.Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		tasks = append(tasks, task.Clone())
+	}
+
+	return tasks, nil
+}
+
+func (r *TaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.tasks[task.ID]; !ok {
+		return domain.ErrNotFound
+	}
+
+	r.tasks[task.ID] = task.Clone()
+	return nil
+}
+
+func (r *TaskRepository) Delete(ctx context.Context, id int) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.tasks[id]; !ok {
+		return domain.ErrNotFound
+	}
+
+	delete(r.tasks, id)
+	delete(r.revisions, id)
+	return nil
+}
+
+func (r *TaskRepository) AppendRevision(ctx context.Context, rev *domain.Revision) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.tasks[rev.TaskID]; !ok {
+		return domain.ErrNotFound
+	}
+
+	rev.Number = len(r.revisions[rev.TaskID]) + 1
+	r.revisions[rev.TaskID] = append(r.revisions[rev.TaskID], rev.Clone())
+	return nil
+}
+
+func (r *TaskRepository) ListRevisions(ctx context.Context, taskID int) ([]*domain.Revision, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	revisions := make([]*domain.Revision, 0, len(r.revisions[taskID]))
+	for _, rev := range r.revisions[taskID] {
+		revisions = append(revisions, rev.Clone())
+	}
+	return revisions, nil
+}
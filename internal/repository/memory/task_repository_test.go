@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func TestTaskRepository(t *testing.T) {
+	repositorytest.RunTaskRepository(t, func(t *testing.T) repository.TaskRepository {
+		return NewTaskRepository()
+	})
+}
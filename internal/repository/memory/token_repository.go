@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.TokenRepository = (*TokenRepository)(nil)
+
+type TokenRepository struct {
+	refresh map[int]*domain.RefreshToken
+	byHash  map[string]int
+	revoked map[string]*domain.RevokedToken
+	mtx     sync.RWMutex
+}
+
+func NewTokenRepository() *TokenRepository {
+	return &TokenRepository{
+		refresh: make(map[int]*domain.RefreshToken),
+		byHash:  make(map[string]int),
+		revoked: make(map[string]*domain.RevokedToken),
+	}
+}
+
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, exists := r.refresh[token.ID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	if _, exists := r.byHash[token.Hash]; exists {
+		return domain.ErrAlreadyExists
+	}
+
+	r.refresh[token.ID] = token.Clone()
+	r.byHash[token.Hash] = token.ID
+	return nil
+}
+
+func (r *TokenRepository) GetRefreshToken(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	id, ok := r.byHash[hash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return r.refresh[id].Clone(), nil
+}
+
+func (r *TokenRepository) UseRefreshToken(ctx context.Context, id int, at time.Time) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	token, ok := r.refresh[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if token.UsedAt != nil {
+		return domain.ErrTokenReused
+	}
+	token.UsedAt = &at
+	return nil
+}
+
+func (r *TokenRepository) RevokeRefreshFamily(ctx context.Context, familyID int, at time.Time) error {
+	r.revokeRefresh(func(token *domain.RefreshToken) bool { return token.FamilyID == familyID }, at)
+	return nil
+}
+
+func (r *TokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID int, at time.Time) error {
+	r.revokeRefresh(func(token *domain.RefreshToken) bool { return token.UserID == userID }, at)
+	return nil
+}
+
+func (r *TokenRepository) revokeRefresh(match func(token *domain.RefreshToken) bool, at time.Time) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, token := range r.refresh {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &at
+		}
+	}
+}
+
+func (r *TokenRepository) RevokeAccessToken(ctx context.Context, token *domain.RevokedToken) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, exists := r.revoked[token.ID]; !exists {
+		r.revoked[token.ID] = token.Clone()
+	}
+	return nil
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	_, revoked := r.revoked[id]
+	return revoked, nil
+}
+
+func (r *TokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	n := 0
+	for id, token := range r.refresh {
+		if token.ExpiresAt.Before(before) {
+			delete(r.refresh, id)
+			delete(r.byHash, token.Hash)
+			n++
+		}
+	}
+	for id, token := range r.revoked {
+		if token.ExpiresAt.Before(before) {
+			delete(r.revoked, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func TestTokenRepository(t *testing.T) {
+	repositorytest.RunTokenRepository(t, func(t *testing.T) (repository.UserRepository, repository.TokenRepository) {
+		return NewUserRepository(), NewTokenRepository()
+	})
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.UserRepository = (*UserRepository)(nil)
+
+type UserRepository struct {
+	users  map[int]*domain.User
+	byName map[string]int
+	mtx    sync.RWMutex
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users:  make(map[int]*domain.User),
+		byName: make(map[string]int),
+	}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, exists := r.users[user.ID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	if _, exists := r.byName[user.Name]; exists {
+		return domain.ErrAlreadyExists
+	}
+
+	r.users[user.ID] = user.Clone()
+	r.byName[user.Name] = user.ID
+	return nil
+}
+
+func (r *UserRepository) Get(ctx context.Context, id int) (*domain.User, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return user.Clone(), nil
+}
+
+func (r *UserRepository) GetByName(ctx context.Context, name string) (*domain.User, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	id, ok := r.byName[name]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return r.users[id].Clone(), nil
+}
+
+func (r *UserRepository) List(ctx context.Context) ([]*domain.User, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	users := make([]*domain.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user.Clone())
+	}
+	slices.SortFunc(users, func(a, b *domain.User) int { return a.ID - b.ID })
+	return users, nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, hash string) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	user.PasswordHash = hash
+	return nil
+}
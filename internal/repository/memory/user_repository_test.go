@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func TestUserRepository(t *testing.T) {
+	repositorytest.RunUserRepository(t, func(t *testing.T) repository.UserRepository {
+		return NewUserRepository()
+	})
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// TaskRepository хранит задачи. Реализации возвращают domain.ErrNotFound,
+// если задачи нет, и domain.ErrAlreadyExists при повторном Create с тем же ID.
+// Возвращаемые задачи являются копиями: их изменение не влияет на хранилище.
+//
+// Вместе с задачей хранится история её ревизий. AppendRevision присваивает
+// ревизии следующий номер для задачи, ListRevisions возвращает ревизии по
+// возрастанию номера. Delete удаляет задачу вместе с историей.
+type TaskRepository interface {
+	Create(ctx context.Context, task *domain.Task) error
+	Get(ctx context.Context, id int) (*domain.Task, error)
+	List(ctx context.Context) ([]*domain.Task, error)
+	Update(ctx context.Context, task *domain.Task) error
+	Delete(ctx context.Context, id int) error
+	AppendRevision(ctx context.Context, rev *domain.Revision) error
+	ListRevisions(ctx context.Context, taskID int) ([]*domain.Revision, error)
+}
+
+// Transactor реализуют хранилища, которые умеют применять несколько
+// изменений атомарно: если fn вернул ошибку, ни одно изменение не сохраняется.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(tx TaskRepository) error) error
+}
+
+// WithinTx выполняет fn в транзакции, если хранилище их поддерживает, и
+// напрямую в противном случае.
+func WithinTx(ctx context.Context, repo TaskRepository, fn func(tx TaskRepository) error) error {
+	if t, ok := repo.(Transactor); ok {
+		return t.WithinTx(ctx, fn)
+	}
+	return fn(repo)
+}
+
+// EventRecorder реализуют хранилища, которые ведут журнал событий. Вид
+// изменения им сообщает сервис, а Record записывает задачу вместе с её
+// ревизией rev одним событием typ: для EventTaskCreated задача создаётся,
+// для остальных видов — изменяется. rev равен nil, если изменение не
+// попадает в историю.
+type EventRecorder interface {
+	Record(ctx context.Context, typ domain.EventType, task *domain.Task, rev *domain.Revision) error
+}
+
+// Record сохраняет задачу и ревизию событием typ, если хранилище ведёт
+// журнал событий, и через Create или Update и AppendRevision в противном
+// случае.
+func Record(ctx context.Context, repo TaskRepository, typ domain.EventType, task *domain.Task, rev *domain.Revision) error {
+	if r, ok := repo.(EventRecorder); ok {
+		return r.Record(ctx, typ, task, rev)
+	}
+	return record(ctx, repo, typ, task, rev)
+}
+
+func record(ctx context.Context, repo TaskRepository, typ domain.EventType, task *domain.Task, rev *domain.Revision) error {
+	var err error
+	if typ == domain.EventTaskCreated {
+		err = repo.Create(ctx, task)
+	} else {
+		err = repo.Update(ctx, task)
+	}
+	if err != nil || rev == nil {
+		return err
+	}
+	return repo.AppendRevision(ctx, rev)
+}
+
+// UserRepository хранит учётные записи пользователей. Имена уникальны:
+// Create возвращает domain.ErrAlreadyExists, если занят ID или имя. Get и
+// GetByName возвращают domain.ErrNotFound для неизвестного пользователя,
+// List — пользователей по возрастанию ID. UpdatePassword меняет только
+// хеш пароля.
+type UserRepository interface {
+	Create(ctx context.Context, user *domain.User) error
+	Get(ctx context.Context, id int) (*domain.User, error)
+	GetByName(ctx context.Context, name string) (*domain.User, error)
+	List(ctx context.Context) ([]*domain.User, error)
+	UpdatePassword(ctx context.Context, id int, hash string) error
+}
+
+// APIKeyRepository хранит ключи API. Create возвращает
+// domain.ErrAlreadyExists, если занят ID или хеш ключа. Get и GetByHash
+// возвращают domain.ErrNotFound для неизвестного ключа, ListByUser — ключи
+// пользователя по времени создания. Touch и Revoke меняют только своё
+// поле, так что отметка об использовании не отменит отзыв ключа; Revoke
+// уже отозванного ключа его не меняет.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	Get(ctx context.Context, id int) (*domain.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	ListByUser(ctx context.Context, userID int) ([]*domain.APIKey, error)
+	Touch(ctx context.Context, id int, at time.Time) error
+	Revoke(ctx context.Context, id int, at time.Time) error
+}
+
+// TokenRepository хранит токены обновления и список отозванных токенов
+// доступа. GetRefreshToken возвращает domain.ErrNotFound для неизвестного
+// токена. UseRefreshToken отмечает токен использованным и возвращает
+// domain.ErrTokenReused, если отметка уже стоит, — так из двух
+// одновременных обновлений одним токеном пройдёт только одно. Отзыв не
+// меняет уже отозванные токены, повторный RevokeAccessToken ничего не
+// делает. DeleteExpired удаляет токены обоих видов, истёкшие до before, и
+// возвращает их число.
+type TokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	GetRefreshToken(ctx context.Context, hash string) (*domain.RefreshToken, error)
+	UseRefreshToken(ctx context.Context, id int, at time.Time) error
+	RevokeRefreshFamily(ctx context.Context, familyID int, at time.Time) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int, at time.Time) error
+	RevokeAccessToken(ctx context.Context, token *domain.RevokedToken) error
+	IsAccessTokenRevoked(ctx context.Context, id string) (bool, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
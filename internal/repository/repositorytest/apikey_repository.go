@@ -0,0 +1,137 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// RunAPIKeyRepository прогоняет набор тестов хранилища ключей API. newRepos
+// должен возвращать пустые хранилища пользователей и ключей для каждого
+// подтеста; ключи создаются для пользователей 1 и 2.
+func RunAPIKeyRepository(t *testing.T, newRepos func(t *testing.T) (repository.UserRepository, repository.APIKeyRepository)) {
+	setup := func(t *testing.T) repository.APIKeyRepository {
+		users, keys := newRepos(t)
+		for _, user := range []*domain.User{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}} {
+			user.CreatedAt = time.Now()
+			if err := users.Create(context.Background(), user); err != nil {
+				t.Fatalf("create user: %v", err)
+			}
+		}
+		return keys
+	}
+	newKey := func(id int, userID int, hash string, createdAt time.Time) *domain.APIKey {
+		return &domain.APIKey{ID: id, UserID: userID, Name: "key", Hash: hash, Hint: "todo_abcd", CreatedAt: createdAt}
+	}
+
+	t.Run("CreateGet", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+
+		key := newKey(1, 1, "h1", time.Now())
+		if err := repo.Create(ctx, key); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		got, err := repo.GetByHash(ctx, "h1")
+		if err != nil {
+			t.Fatalf("get by hash: %v", err)
+		}
+		if got.ID != 1 || got.UserID != 1 || got.Hint != "todo_abcd" || got.LastUsedAt != nil || got.Revoked() {
+			t.Errorf("key: %+v", got)
+		}
+		if got, err = repo.Get(ctx, 1); err != nil || got.Hash != "h1" {
+			t.Errorf("get: %+v, %v", got, err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+
+		if _, err := repo.Get(ctx, 1); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("get: want ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetByHash(ctx, "h1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("get by hash: want ErrNotFound, got %v", err)
+		}
+		if err := repo.Touch(ctx, 1, time.Now()); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("touch: want ErrNotFound, got %v", err)
+		}
+		if err := repo.Revoke(ctx, 1, time.Now()); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("revoke: want ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("CreateDuplicate", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+
+		if err := repo.Create(ctx, newKey(1, 1, "h1", time.Now())); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := repo.Create(ctx, newKey(1, 1, "h2", time.Now())); !errors.Is(err, domain.ErrAlreadyExists) {
+			t.Errorf("same id: want ErrAlreadyExists, got %v", err)
+		}
+		if err := repo.Create(ctx, newKey(2, 2, "h1", time.Now())); !errors.Is(err, domain.ErrAlreadyExists) {
+			t.Errorf("same hash: want ErrAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("ListByUser", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+		now := time.Now()
+
+		for _, key := range []*domain.APIKey{
+			newKey(1, 1, "h1", now.Add(time.Minute)),
+			newKey(2, 2, "h2", now),
+			newKey(3, 1, "h3", now),
+		} {
+			if err := repo.Create(ctx, key); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+		keys, err := repo.ListByUser(ctx, 1)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(keys) != 2 || keys[0].ID != 3 || keys[1].ID != 1 {
+			t.Errorf("list: %+v", keys)
+		}
+	})
+
+	t.Run("TouchRevoke", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+		now := time.Now()
+
+		if err := repo.Create(ctx, newKey(1, 1, "h1", now)); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := repo.Revoke(ctx, 1, now.Add(time.Minute)); err != nil {
+			t.Fatalf("revoke: %v", err)
+		}
+		if err := repo.Revoke(ctx, 1, now.Add(time.Hour)); err != nil {
+			t.Fatalf("revoke again: %v", err)
+		}
+		if err := repo.Touch(ctx, 1, now.Add(2*time.Minute)); err != nil {
+			t.Fatalf("touch: %v", err)
+		}
+
+		got, err := repo.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.RevokedAt == nil || got.RevokedAt.Sub(now.Add(time.Minute)).Abs() > time.Millisecond {
+			t.Errorf("revoked at: %v", got.RevokedAt)
+		}
+		if got.LastUsedAt == nil || got.LastUsedAt.Sub(now.Add(2*time.Minute)).Abs() > time.Millisecond {
+			t.Errorf("last used at: %v", got.LastUsedAt)
+		}
+	})
+}
@@ -0,0 +1,466 @@
+// Package repositorytest содержит общий набор тестов, которому должна
+// соответствовать любая реализация repository.TaskRepository.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// RunTaskRepository прогоняет набор тестов; newRepo должен возвращать пустое
+// хранилище для каждого подтеста.
+func RunTaskRepository(t *testing.T, newRepo func(t *testing.T) repository.TaskRepository) {
+	t.Run("CreateGet", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		task := domain.NewTask(1, "Заголовок", "Описание")
+		task.OwnerID = 7
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		got, err := repo.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Headline != task.Headline || got.Description != task.Description || got.OwnerID != 7 {
+			t.Errorf("content: %+v", got)
+		}
+		if got.Done || got.CompletedAt != nil {
+			t.Error("unexpected completion")
+		}
+		if got.CreatedAt.Sub(task.CreatedAt).Abs() > time.Millisecond {
+			t.Errorf("CreatedAt: %v != %v", got.CreatedAt, task.CreatedAt)
+		}
+
+		task.OwnerID = 9
+		if err := repo.Update(ctx, task); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if got, err := repo.Get(ctx, 1); err != nil || got.OwnerID != 9 {
+			t.Errorf("owner after update: %+v, %v", got, err)
+		}
+	})
+
+	t.Run("CreateDuplicate", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		if err := repo.Create(ctx, domain.NewTask(1, "Task", "Description")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		err := repo.Create(ctx, domain.NewTask(1, "Task", "Description"))
+		if !errors.Is(err, domain.ErrAlreadyExists) {
+			t.Errorf("want ErrAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		tasks, err := repo.List(ctx)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if tasks == nil || len(tasks) != 0 {
+			t.Fatalf("want empty list, got %v", tasks)
+		}
+
+		for id := 1; id <= 3; id++ {
+			if err := repo.Create(ctx, domain.NewTask(id, "Task", "Description")); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+		tasks, err = repo.List(ctx)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(tasks) != 3 {
+			t.Errorf("len != 3: %d", len(tasks))
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		if err := repo.Create(ctx, domain.NewTask(1, "Task", "Description")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		task, err := repo.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		completedAt := time.Now()
+		task.Headline = "New"
+		task.Description = ""
+		task.Done = true
+		task.CompletedAt = &completedAt
+		task.Version = 2
+		if err := repo.Update(ctx, task); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		got, err := repo.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Headline != "New" || got.Description != "" {
+			t.Errorf("content: %+v", got)
+		}
+		if !got.Done || got.CompletedAt == nil {
+			t.Error("completion lost")
+		}
+		if got.Version != 2 {
+			t.Errorf("version = %d, want 2", got.Version)
+		}
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		task := domain.NewTask(1, "Task", "Description")
+		if err := task.Transition(domain.StatusInProgress, time.Now()); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := task.Transition(domain.StatusCancelled, time.Now()); err != nil {
+			t.Fatalf("cancel: %v", err)
+		}
+		if err := repo.Update(ctx, task); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		got, err := repo.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.CurrentStatus() != domain.StatusCancelled || got.Done {
+			t.Errorf("status: %s done=%v", got.Status, got.Done)
+		}
+		if got.StartedAt == nil || got.CancelledAt == nil || got.StatusChangedAt == nil || got.CompletedAt != nil {
+			t.Errorf("timestamps: %+v", got)
+		}
+	})
+
+	t.Run("ArchivedAndDeletedAt", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		task := domain.NewTask(1, "Task", "Description")
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		deletedAt := time.Now()
+		task.ArchivedAt = &deletedAt
+		task.DeletedAt = &deletedAt
+		if err := repo.Update(ctx, task); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		got, err := repo.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.DeletedAt == nil || got.DeletedAt.Sub(deletedAt).Abs() > time.Millisecond {
+			t.Errorf("deleted_at: %v", got.DeletedAt)
+		}
+		if got.ArchivedAt == nil || got.ArchivedAt.Sub(deletedAt).Abs() > time.Millisecond {
+			t.Errorf("archived_at: %v", got.ArchivedAt)
+		}
+
+		got.DeletedAt = nil
+		if err := repo.Update(ctx, got); err != nil {
+			t.Fatalf("restore: %v", err)
+		}
+		if again, _ := repo.Get(ctx, 1); again.IsDeleted() {
+			t.Error("deleted_at was not cleared")
+		}
+	})
+
+	t.Run("PriorityAndDue", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		dueAt := time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)
+		task := domain.NewTask(1, "Task", "Description")
+		task.Priority = domain.PriorityUrgent
+		task.DueAt = &dueAt
+		task.DueTimezone = "Europe/Moscow"
+		task.Recurrence = "FREQ=WEEKLY;BYDAY=MO"
+		task.Occurrence = 3
+		task.SeriesID = 7
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		got, err := repo.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Priority != domain.PriorityUrgent {
+			t.Errorf("priority: %s", got.Priority)
+		}
+		if got.DueAt == nil || !got.DueAt.Equal(dueAt) {
+			t.Errorf("due_at: %v", got.DueAt)
+		}
+		if got.DueTimezone != "Europe/Moscow" {
+			t.Errorf("due_timezone: %s", got.DueTimezone)
+		}
+		if got.Recurrence != "FREQ=WEEKLY;BYDAY=MO" || got.Occurrence != 3 || got.SeriesID != 7 {
+			t.Errorf("recurrence: %s %d %d", got.Recurrence, got.Occurrence, got.SeriesID)
+		}
+
+		got.DueAt = nil
+		got.DueTimezone = ""
+		got.Priority = domain.PriorityLow
+		if err := repo.Update(ctx, got); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		got, _ = repo.Get(ctx, 1)
+		if got.DueAt != nil || got.DueTimezone != "" || got.Priority != domain.PriorityLow {
+			t.Errorf("after update: %+v", got)
+		}
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		task := domain.NewTask(1, "Task", "Description")
+		task.Tags = []string{"backend", "urgent"}
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := repo.Create(ctx, domain.NewTask(2, "Task 2", "Description")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		got, err := repo.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if !slices.Equal(got.Tags, []string{"backend", "urgent"}) {
+			t.Errorf("tags: %v", got.Tags)
+		}
+
+		got.Tags = []string{"frontend"}
+		if err := repo.Update(ctx, got); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		tasks, err := repo.List(ctx)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		for _, task := range tasks {
+			switch task.ID {
+			case 1:
+				if !slices.Equal(task.Tags, []string{"frontend"}) {
+					t.Errorf("tags after update: %v", task.Tags)
+				}
+			case 2:
+				if len(task.Tags) != 0 {
+					t.Errorf("task 2 tags: %v", task.Tags)
+				}
+			}
+		}
+
+		if err := repo.Delete(ctx, 1); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		// Теги удалённой задачи не должны достаться новой с тем же ID
+		if err := repo.Create(ctx, domain.NewTask(1, "Task", "Description")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		got, _ = repo.Get(ctx, 1)
+		if len(got.Tags) != 0 {
+			t.Errorf("tags survived delete: %v", got.Tags)
+		}
+	})
+
+	t.Run("ParentID", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		if err := repo.Create(ctx, domain.NewTask(1, "Parent", "Description")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		parentID := 1
+		child := domain.NewTask(2, "Child", "Description")
+		child.ParentID = &parentID
+		if err := repo.Create(ctx, child); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		got, err := repo.Get(ctx, 2)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.ParentID == nil || *got.ParentID != 1 {
+			t.Errorf("parent_id: %v", got.ParentID)
+		}
+
+		got.ParentID = nil
+		if err := repo.Update(ctx, got); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		got, _ = repo.Get(ctx, 2)
+		if got.ParentID != nil {
+			t.Errorf("parent_id after update: %v", *got.ParentID)
+		}
+	})
+
+	t.Run("BlockedBy", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		for id := 1; id <= 3; id++ {
+			if err := repo.Create(ctx, domain.NewTask(id, "Blocker", "Description")); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+		task := domain.NewTask(4, "Task", "Description")
+		task.BlockedBy = []int{1, 2}
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		got, err := repo.Get(ctx, 4)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if !slices.Equal(got.BlockedBy, []int{1, 2}) {
+			t.Errorf("blocked_by: %v", got.BlockedBy)
+		}
+
+		got.BlockedBy = []int{3}
+		if err := repo.Update(ctx, got); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		tasks, err := repo.List(ctx)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		for _, task := range tasks {
+			if task.ID == 4 && !slices.Equal(task.BlockedBy, []int{3}) {
+				t.Errorf("blocked_by after update: %v", task.BlockedBy)
+			}
+		}
+	})
+
+	t.Run("ReturnsCopies", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		task := domain.NewTask(1, "Task", "Description")
+		task.Tags = []string{"backend"}
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		task.Headline = "Changed"
+		task.Tags[0] = "changed"
+
+		got, _ := repo.Get(ctx, 1)
+		got.Done = true
+		got.Tags[0] = "changed"
+		again, _ := repo.Get(ctx, 1)
+		if again.Headline != "Task" || again.Done || again.Tags[0] != "backend" {
+			t.Errorf("storage mutated: %+v", again)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		if _, err := repo.Get(ctx, 42); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("get: want ErrNotFound, got %v", err)
+		}
+		if err := repo.Update(ctx, domain.NewTask(42, "Task", "Description")); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("update: want ErrNotFound, got %v", err)
+		}
+		if err := repo.Delete(ctx, 42); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("delete: want ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		if err := repo.Create(ctx, domain.NewTask(1, "Task", "Description")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := repo.Delete(ctx, 1); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := repo.Get(ctx, 1); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("want ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Revisions", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		task := domain.NewTask(1, "Task", "Description")
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		for _, action := range []string{domain.ActionCreate, domain.ActionUpdate} {
+			rev := &domain.Revision{
+				TaskID:   1,
+				Actor:    "alice",
+				Action:   action,
+				At:       time.Now(),
+				Changes:  domain.Diff(nil, task),
+				Snapshot: task,
+			}
+			if err := repo.AppendRevision(ctx, rev); err != nil {
+				t.Fatalf("append: %v", err)
+			}
+			if action == domain.ActionUpdate && rev.Number != 2 {
+				t.Errorf("number != 2: %d", rev.Number)
+			}
+		}
+
+		revisions, err := repo.ListRevisions(ctx, 1)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(revisions) != 2 || revisions[0].Number != 1 || revisions[1].Action != domain.ActionUpdate {
+			t.Fatalf("revisions: %+v", revisions)
+		}
+		rev := revisions[0]
+		if rev.Actor != "alice" || len(rev.Changes) != len(domain.Diff(nil, task)) {
+			t.Errorf("revision: %+v", rev)
+		}
+		if rev.Snapshot == nil || rev.Snapshot.Headline != "Task" {
+			t.Errorf("snapshot: %+v", rev.Snapshot)
+		}
+
+		err = repo.AppendRevision(ctx, &domain.Revision{TaskID: 42, Action: domain.ActionUpdate, At: time.Now(), Snapshot: task})
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("append to missing task: want ErrNotFound, got %v", err)
+		}
+
+		if err := repo.Delete(ctx, 1); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if revisions, _ := repo.ListRevisions(ctx, 1); len(revisions) != 0 {
+			t.Errorf("revisions survived delete: %d", len(revisions))
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// RunTokenRepository прогоняет набор тестов хранилища токенов. newRepos
+// должен возвращать пустые хранилища пользователей и токенов для каждого
+// подтеста; токены создаются для пользователей 1 и 2.
+func RunTokenRepository(t *testing.T, newRepos func(t *testing.T) (repository.UserRepository, repository.TokenRepository)) {
+	setup := func(t *testing.T) repository.TokenRepository {
+		users, tokens := newRepos(t)
+		for _, user := range []*domain.User{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}} {
+			user.CreatedAt = time.Now()
+			if err := users.Create(context.Background(), user); err != nil {
+				t.Fatalf("create user: %v", err)
+			}
+		}
+		return tokens
+	}
+	now := time.Now()
+	newToken := func(id int, userID int, familyID int, hash string) *domain.RefreshToken {
+		return &domain.RefreshToken{ID: id, UserID: userID, FamilyID: familyID, Hash: hash, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	}
+
+	t.Run("RefreshCreateGet", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+
+		if err := repo.CreateRefreshToken(ctx, newToken(1, 1, 10, "h1")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		got, err := repo.GetRefreshToken(ctx, "h1")
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.ID != 1 || got.UserID != 1 || got.FamilyID != 10 || !got.Valid(now) ||
+			got.ExpiresAt.Sub(now.Add(time.Hour)).Abs() > time.Millisecond {
+			t.Errorf("token: %+v", got)
+		}
+
+		if _, err := repo.GetRefreshToken(ctx, "h2"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("unknown: want ErrNotFound, got %v", err)
+		}
+		if err := repo.CreateRefreshToken(ctx, newToken(2, 1, 10, "h1")); !errors.Is(err, domain.ErrAlreadyExists) {
+			t.Errorf("same hash: want ErrAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("UseRefreshToken", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+
+		if err := repo.CreateRefreshToken(ctx, newToken(1, 1, 10, "h1")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := repo.UseRefreshToken(ctx, 1, now); err != nil {
+			t.Fatalf("use: %v", err)
+		}
+		if err := repo.UseRefreshToken(ctx, 1, now); !errors.Is(err, domain.ErrTokenReused) {
+			t.Errorf("reuse: want ErrTokenReused, got %v", err)
+		}
+		if err := repo.UseRefreshToken(ctx, 2, now); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("unknown: want ErrNotFound, got %v", err)
+		}
+		if got, _ := repo.GetRefreshToken(ctx, "h1"); got == nil || got.UsedAt == nil || got.Valid(now) {
+			t.Errorf("used token: %+v", got)
+		}
+	})
+
+	t.Run("RevokeRefresh", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+
+		for _, token := range []*domain.RefreshToken{
+			newToken(1, 1, 10, "h1"),
+			newToken(2, 1, 10, "h2"),
+			newToken(3, 1, 20, "h3"),
+			newToken(4, 2, 30, "h4"),
+		} {
+			if err := repo.CreateRefreshToken(ctx, token); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+		if err := repo.RevokeRefreshFamily(ctx, 10, now); err != nil {
+			t.Fatalf("revoke family: %v", err)
+		}
+		revoked := func(hash string) bool {
+			token, err := repo.GetRefreshToken(ctx, hash)
+			if err != nil {
+				t.Fatalf("get %s: %v", hash, err)
+			}
+			return token.RevokedAt != nil
+		}
+		if !revoked("h1") || !revoked("h2") || revoked("h3") || revoked("h4") {
+			t.Error("family revoke touched wrong tokens")
+		}
+
+		if err := repo.RevokeUserRefreshTokens(ctx, 1, now); err != nil {
+			t.Fatalf("revoke user: %v", err)
+		}
+		if !revoked("h3") || revoked("h4") {
+			t.Error("user revoke touched wrong tokens")
+		}
+	})
+
+	t.Run("RevokeAccessToken", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+
+		token := &domain.RevokedToken{ID: "jti", UserID: 1, ExpiresAt: now.Add(time.Minute)}
+		if err := repo.RevokeAccessToken(ctx, token); err != nil {
+			t.Fatalf("revoke: %v", err)
+		}
+		if err := repo.RevokeAccessToken(ctx, token); err != nil {
+			t.Fatalf("revoke again: %v", err)
+		}
+		if revoked, err := repo.IsAccessTokenRevoked(ctx, "jti"); !revoked || err != nil {
+			t.Errorf("revoked: %v, %v", revoked, err)
+		}
+		if revoked, err := repo.IsAccessTokenRevoked(ctx, "other"); revoked || err != nil {
+			t.Errorf("other: %v, %v", revoked, err)
+		}
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+
+		expired := newToken(1, 1, 10, "h1")
+		expired.ExpiresAt = now.Add(-time.Minute)
+		for _, token := range []*domain.RefreshToken{expired, newToken(2, 1, 10, "h2")} {
+			if err := repo.CreateRefreshToken(ctx, token); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+		for _, token := range []*domain.RevokedToken{
+			{ID: "old", UserID: 1, ExpiresAt: now.Add(-time.Minute)},
+			{ID: "new", UserID: 1, ExpiresAt: now.Add(time.Minute)},
+		} {
+			if err := repo.RevokeAccessToken(ctx, token); err != nil {
+				t.Fatalf("revoke: %v", err)
+			}
+		}
+
+		n, err := repo.DeleteExpired(ctx, now)
+		if err != nil || n != 2 {
+			t.Fatalf("delete expired: %d, %v", n, err)
+		}
+		if _, err := repo.GetRefreshToken(ctx, "h1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expired refresh token kept: %v", err)
+		}
+		if _, err := repo.GetRefreshToken(ctx, "h2"); err != nil {
+			t.Errorf("live refresh token deleted: %v", err)
+		}
+		if revoked, _ := repo.IsAccessTokenRevoked(ctx, "old"); revoked {
+			t.Error("expired revocation kept")
+		}
+		if revoked, _ := repo.IsAccessTokenRevoked(ctx, "new"); !revoked {
+			t.Error("live revocation deleted")
+		}
+	})
+}
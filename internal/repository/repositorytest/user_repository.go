@@ -0,0 +1,102 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// RunUserRepository прогоняет набор тестов хранилища пользователей; newRepo
+// должен возвращать пустое хранилище для каждого подтеста.
+func RunUserRepository(t *testing.T, newRepo func(t *testing.T) repository.UserRepository) {
+	newUser := func(id int, name string) *domain.User {
+		return &domain.User{ID: id, Name: name, CreatedAt: time.Now()}
+	}
+
+	t.Run("CreateGet", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		user := newUser(1, "alice")
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		got, err := repo.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Name != "alice" || got.CreatedAt.Sub(user.CreatedAt).Abs() > time.Millisecond {
+			t.Errorf("user: %+v", got)
+		}
+		if got, err = repo.GetByName(ctx, "alice"); err != nil || got.ID != 1 {
+			t.Errorf("get by name: %+v, %v", got, err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		if _, err := repo.Get(ctx, 1); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("get: want ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetByName(ctx, "alice"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("get by name: want ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("CreateDuplicate", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		if err := repo.Create(ctx, newUser(1, "alice")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := repo.Create(ctx, newUser(1, "bob")); !errors.Is(err, domain.ErrAlreadyExists) {
+			t.Errorf("same id: want ErrAlreadyExists, got %v", err)
+		}
+		if err := repo.Create(ctx, newUser(2, "alice")); !errors.Is(err, domain.ErrAlreadyExists) {
+			t.Errorf("same name: want ErrAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		for _, user := range []*domain.User{newUser(3, "carol"), newUser(1, "alice"), newUser(2, "bob")} {
+			if err := repo.Create(ctx, user); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+		users, err := repo.List(ctx)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(users) != 3 || users[0].Name != "alice" || users[2].Name != "carol" {
+			t.Errorf("list: %+v", users)
+		}
+	})
+	t.Run("UpdatePassword", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := context.Background()
+
+		if err := repo.Create(ctx, newUser(1, "alice")); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := repo.UpdatePassword(ctx, 1, "$argon2id$hash"); err != nil {
+			t.Fatalf("update password: %v", err)
+		}
+		if got, err := repo.GetByName(ctx, "alice"); err != nil || got.PasswordHash != "$argon2id$hash" || got.Name != "alice" {
+			t.Errorf("after update: %+v, %v", got, err)
+		}
+		if err := repo.UpdatePassword(ctx, 2, "x"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("unknown user: want ErrNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.APIKeyRepository = (*APIKeyRepository)(nil)
+
+type APIKeyRepository struct {
+	db      dbtx
+	dialect *Dialect
+}
+
+func NewAPIKeyRepository(db *sql.DB, dialect *Dialect) *APIKeyRepository {
+	return &APIKeyRepository{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+const apiKeyColumns = "id, user_id, name, hash, hint, created_at, last_used_at, revoked_at"
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind("INSERT INTO api_keys ("+apiKeyColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)"),
+		key.ID, key.UserID, key.Name, key.Hash, key.Hint, key.CreatedAt.UTC(),
+		nullTime(key.LastUsedAt), nullTime(key.RevokedAt),
+	)
+	if err != nil && r.dialect.isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *APIKeyRepository) Get(ctx context.Context, id int) (*domain.APIKey, error) {
+	return r.getBy(ctx, "id", id)
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	return r.getBy(ctx, "hash", hash)
+}
+
+func (r *APIKeyRepository) getBy(ctx context.Context, column string, value any) (*domain.APIKey, error) {
+	row := r.db.QueryRowContext(ctx,
+		r.dialect.Rebind("SELECT "+apiKeyColumns+" FROM api_keys WHERE "+column+" = ?"),
+		value,
+	)
+	key, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return key, err
+}
+
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID int) ([]*domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx,
+		r.dialect.Rebind("SELECT "+apiKeyColumns+" FROM api_keys WHERE user_id = ? ORDER BY created_at, id"),
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*domain.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	return execOne(ctx, r.db, r.dialect.Rebind("UPDATE api_keys SET last_used_at = ? WHERE id = ?"), at.UTC(), id)
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int, at time.Time) error {
+	return execOne(ctx, r.db, r.dialect.Rebind("UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?"), at.UTC(), id)
+}
+
+func scanAPIKey(s scanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var lastUsedAt, revokedAt sql.NullTime
+	err := s.Scan(&key.ID, &key.UserID, &key.Name, &key.Hash, &key.Hint, &key.CreatedAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	key.LastUsedAt = timePtr(lastUsedAt)
+	key.RevokedAt = timePtr(revokedAt)
+	return &key, nil
+}
@@ -0,0 +1,78 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Dialect описывает различия между поддерживаемыми СУБД. Запросы в пакете
+// пишутся с плейсхолдерами "?", а Rebind переводит их в синтаксис СУБД.
+type Dialect struct {
+	Name       string
+	DriverName string
+	Migrations fs.FS
+
+	rebind            func(query string) string
+	isUniqueViolation func(err error) bool
+	lockMigrations    func(ctx context.Context, conn *sql.Conn) (func(), error)
+}
+
+var Postgres = &Dialect{
+	Name:       "postgres",
+	DriverName: "postgres",
+	Migrations: mustSub(migrationsFS, "migrations/postgres"),
+
+	rebind: rebindDollar,
+	isUniqueViolation: func(err error) bool {
+		var pqErr *pq.Error
+		return errors.As(err, &pqErr) && pqErr.Code == "23505"
+	},
+	lockMigrations: func(ctx context.Context, conn *sql.Conn) (func(), error) {
+		// Несколько экземпляров сервиса могут стартовать одновременно,
+		// миграции применяет только один из них
+		const key = 7463219
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+			return nil, err
+		}
+		return func() {
+			_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+		}, nil
+	},
+}
+
+func (d *Dialect) Rebind(query string) string {
+	if d.rebind == nil {
+		return query
+	}
+	return d.rebind(query)
+}
+
+func rebindDollar(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,59 @@
+package sqlstore
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrationsSorted(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_second.sql": {Data: []byte("SELECT 2")},
+		"0001_first.sql":  {Data: []byte("SELECT 1")},
+		"README.md":       {Data: []byte("ignored")},
+	}
+
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("len != 2: %d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Errorf("order: %d, %d", migrations[0].Version, migrations[1].Version)
+	}
+	if migrations[0].Name != "0001_first" {
+		t.Errorf("name: %s", migrations[0].Name)
+	}
+}
+
+func TestLoadMigrationsInvalid(t *testing.T) {
+	cases := []fstest.MapFS{
+		{"create_tasks.sql": {Data: []byte("SELECT 1")}},
+		{"abc_create_tasks.sql": {Data: []byte("SELECT 1")}},
+		{"0001_a.sql": {Data: []byte("SELECT 1")}, "0001_b.sql": {Data: []byte("SELECT 1")}},
+	}
+	for _, fsys := range cases {
+		if _, err := loadMigrations(fsys); err == nil {
+			t.Errorf("no error for %v", fsys)
+		}
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(Postgres.Migrations)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+}
+
+func TestRebindDollar(t *testing.T) {
+	got := rebindDollar("UPDATE tasks SET headline = ?, done = ? WHERE id = ?")
+	want := "UPDATE tasks SET headline = $1, done = $2 WHERE id = $3"
+	if got != want {
+		t.Errorf("%s != %s", got, want)
+	}
+}
@@ -0,0 +1,12 @@
+CREATE TABLE tasks (
+    id           BIGINT PRIMARY KEY,
+    headline     TEXT NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    done         BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at   TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX tasks_done_idx ON tasks (done);
+CREATE INDEX tasks_created_at_idx ON tasks (created_at);
+CREATE INDEX tasks_completed_at_idx ON tasks (completed_at) WHERE completed_at IS NOT NULL;
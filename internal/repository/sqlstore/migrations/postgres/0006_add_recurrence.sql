@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN occurrence INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN series_id BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX tasks_series_id_idx ON tasks (series_id) WHERE series_id <> 0;
@@ -0,0 +1,8 @@
+ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT 'todo';
+ALTER TABLE tasks ADD COLUMN status_changed_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN started_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN cancelled_at TIMESTAMPTZ;
+
+UPDATE tasks SET status = 'done', status_changed_at = completed_at WHERE done;
+
+CREATE INDEX tasks_status_idx ON tasks (status);
@@ -0,0 +1,11 @@
+CREATE TABLE task_revisions (
+    task_id    BIGINT      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    number     INTEGER     NOT NULL,
+    actor      TEXT        NOT NULL,
+    action     TEXT        NOT NULL,
+    revert_of  INTEGER     NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    changes    JSONB       NOT NULL,
+    snapshot   JSONB       NOT NULL,
+    PRIMARY KEY (task_id, number)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE users (
+    id         BIGINT      PRIMARY KEY,
+    name       TEXT        NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE tasks ADD COLUMN owner_id BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX tasks_owner_id_idx ON tasks (owner_id);
@@ -0,0 +1,6 @@
+ALTER TABLE tasks ADD COLUMN priority TEXT NOT NULL DEFAULT 'normal';
+ALTER TABLE tasks ADD COLUMN due_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN due_timezone TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX tasks_priority_idx ON tasks (priority);
+CREATE INDEX tasks_due_at_idx ON tasks (due_at) WHERE due_at IS NOT NULL;
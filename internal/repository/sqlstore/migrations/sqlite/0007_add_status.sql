@@ -0,0 +1,8 @@
+ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT 'todo';
+ALTER TABLE tasks ADD COLUMN status_changed_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN started_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN cancelled_at TIMESTAMP;
+
+UPDATE tasks SET status = 'done', status_changed_at = completed_at WHERE done = 1;
+
+CREATE INDEX tasks_status_idx ON tasks (status);
@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN archived_at TIMESTAMP;
+
+CREATE INDEX tasks_archived_at_idx ON tasks (archived_at) WHERE archived_at IS NOT NULL;
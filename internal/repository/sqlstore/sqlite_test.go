@@ -0,0 +1,187 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/repository/repositorytest"
+)
+
+func openTestSQLite(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := OpenSQLite(ctx, path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := Migrate(ctx, db, SQLite); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestSQLiteTaskRepository(t *testing.T) {
+	repositorytest.RunTaskRepository(t, func(t *testing.T) repository.TaskRepository {
+		db := openTestSQLite(t, filepath.Join(t.TempDir(), "todo.db"))
+		return NewTaskRepository(db, SQLite)
+	})
+}
+
+func TestSQLiteUserRepository(t *testing.T) {
+	repositorytest.RunUserRepository(t, func(t *testing.T) repository.UserRepository {
+		db := openTestSQLite(t, filepath.Join(t.TempDir(), "todo.db"))
+		return NewUserRepository(db, SQLite)
+	})
+}
+
+func TestSQLiteTokenRepository(t *testing.T) {
+	repositorytest.RunTokenRepository(t, func(t *testing.T) (repository.UserRepository, repository.TokenRepository) {
+		db := openTestSQLite(t, filepath.Join(t.TempDir(), "todo.db"))
+		return NewUserRepository(db, SQLite), NewTokenRepository(db, SQLite)
+	})
+}
+
+func TestSQLiteAPIKeyRepository(t *testing.T) {
+	repositorytest.RunAPIKeyRepository(t, func(t *testing.T) (repository.UserRepository, repository.APIKeyRepository) {
+		db := openTestSQLite(t, filepath.Join(t.TempDir(), "todo.db"))
+		return NewUserRepository(db, SQLite), NewAPIKeyRepository(db, SQLite)
+	})
+}
+
+func TestSQLiteMigrateIsIdempotent(t *testing.T) {
+	db := openTestSQLite(t, filepath.Join(t.TempDir(), "todo.db"))
+
+	applied, err := Migrate(context.Background(), db, SQLite)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("reapplied %d migrations", len(applied))
+	}
+}
+
+func TestSQLiteWithinTxRollback(t *testing.T) {
+	db := openTestSQLite(t, filepath.Join(t.TempDir(), "todo.db"))
+	repo := NewTaskRepository(db, SQLite)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, domain.NewTask(1, "Task 1", "Description")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	errAbort := errors.New("abort")
+	err := repo.WithinTx(ctx, func(tx repository.TaskRepository) error {
+		task, err := tx.Get(ctx, 1)
+		if err != nil {
+			return err
+		}
+		task.Done = true
+		if err := tx.Update(ctx, task); err != nil {
+			return err
+		}
+		if err := tx.Create(ctx, domain.NewTask(2, "Task 2", "Description")); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("want errAbort, got %v", err)
+	}
+
+	task, _ := repo.Get(ctx, 1)
+	if task.Done {
+		t.Error("update not rolled back")
+	}
+	if _, err := repo.Get(ctx, 2); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("create not rolled back: %v", err)
+	}
+}
+
+func TestSQLiteWithinTxCommit(t *testing.T) {
+	db := openTestSQLite(t, filepath.Join(t.TempDir(), "todo.db"))
+	repo := NewTaskRepository(db, SQLite)
+	ctx := context.Background()
+
+	err := repository.WithinTx(ctx, repo, func(tx repository.TaskRepository) error {
+		for id := 1; id <= 3; id++ {
+			if err := tx.Create(ctx, domain.NewTask(id, "Task", "Description")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("tx: %v", err)
+	}
+
+	tasks, _ := repo.List(ctx)
+	if len(tasks) != 3 {
+		t.Errorf("len != 3: %d", len(tasks))
+	}
+}
+
+func TestSQLiteBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "todo.db")
+	backupPath := filepath.Join(dir, "backup", "todo.db")
+	ctx := context.Background()
+
+	db := openTestSQLite(t, dbPath)
+	repo := NewTaskRepository(db, SQLite)
+	if err := repo.Create(ctx, domain.NewTask(1, "Task 1", "Description")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Копия снимается при открытом соединении, как у работающего сервера
+	if err := BackupSQLite(ctx, db, backupPath); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if err := BackupSQLite(ctx, db, backupPath); err == nil {
+		t.Error("backup overwrote existing file")
+	}
+
+	if err := repo.Create(ctx, domain.NewTask(2, "Task 2", "Description")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	db.Close()
+
+	if err := RestoreSQLite(ctx, backupPath, dbPath); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	restored := NewTaskRepository(openTestSQLite(t, dbPath), SQLite)
+	tasks, err := restored.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != 1 {
+		t.Errorf("restored tasks: %v", tasks)
+	}
+}
+
+func TestSQLiteRestoreRejectsInvalidBackup(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	if err := RestoreSQLite(ctx, filepath.Join(dir, "missing.db"), filepath.Join(dir, "todo.db")); err == nil {
+		t.Error("no error for missing backup")
+	}
+
+	empty := filepath.Join(dir, "empty.db")
+	db, err := OpenSQLite(ctx, empty)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	db.Close()
+	if err := RestoreSQLite(ctx, empty, filepath.Join(dir, "todo.db")); err == nil {
+		t.Error("no error for foreign database")
+	}
+}
@@ -0,0 +1,47 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// dbtx покрывает общие методы *sql.DB и *sql.Tx, чтобы репозитории могли
+// работать как напрямую, так и внутри транзакции.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Open открывает пул соединений и проверяет доступность базы.
+func Open(ctx context.Context, dialect *Dialect, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(dialect.DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to %s: %w", dialect.Name, err)
+	}
+	return db, nil
+}
+
+// execOne выполняет UPDATE одной записи и возвращает domain.ErrNotFound,
+// если записи нет.
+func execOne(ctx context.Context, db dbtx, query string, args ...any) error {
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var (
+	_ repository.TaskRepository = (*TaskRepository)(nil)
+	_ repository.Transactor     = (*TaskRepository)(nil)
+)
+
+type TaskRepository struct {
+	db      dbtx
+	dialect *Dialect
+}
+
+func NewTaskRepository(db *sql.DB, dialect *Dialect) *TaskRepository {
+	return &TaskRepository{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+// WithinTx выполняет fn в одной транзакции базы. Вложенный вызов
+// переиспользует уже открытую транзакцию.
+func (r *TaskRepository) WithinTx(ctx context.Context, fn func(tx repository.TaskRepository) error) error {
+	return r.inTx(ctx, func(tx *TaskRepository) error {
+		return fn(tx)
+	})
+}
+
+func (r *TaskRepository) inTx(ctx context.Context, fn func(tx *TaskRepository) error) error {
+	db, ok := r.db.(*sql.DB)
+	if !ok {
+		return fn(r)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&TaskRepository{db: tx, dialect: r.dialect}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const taskColumns = "id, headline, description, priority, due_at, due_timezone, parent_id, recurrence, occurrence, series_id, " +
+	"status, status_changed_at, started_at, cancelled_at, done, created_at, completed_at, archived_at, deleted_at, version, owner_id"
+
+func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	return r.inTx(ctx, func(tx *TaskRepository) error {
+		_, err := tx.db.ExecContext(ctx,
+			tx.dialect.Rebind("INSERT INTO tasks ("+taskColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+			task.ID, task.Headline, task.Description, string(task.Priority.OrDefault()), nullTime(task.DueAt), task.DueTimezone,
+			nullInt(task.ParentID), task.Recurrence, task.Occurrence, task.SeriesID,
+			string(task.CurrentStatus()), nullTime(task.StatusChangedAt), nullTime(task.StartedAt), nullTime(task.CancelledAt), task.Done, task.CreatedAt.UTC(), nullTime(task.CompletedAt),
+			nullTime(task.ArchivedAt), nullTime(task.DeletedAt), task.Version, task.OwnerID,
+		)
+		if err != nil {
+			if tx.dialect.isUniqueViolation(err) {
+				return domain.ErrAlreadyExists
+			}
+			return err
+		}
+		return tx.insertRelations(ctx, task)
+	})
+}
+
+func (r *TaskRepository) Get(ctx context.Context, id int) (*domain.Task, error) {
+	row := r.db.QueryRowContext(ctx,
+		r.dialect.Rebind("SELECT "+taskColumns+" FROM tasks WHERE id = ?"),
+		id,
+	)
+	task, err := scanTask(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[int]*domain.Task{id: task}
+	if err := r.loadRelations(ctx, byID, " WHERE task_id = ?", id); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (r *TaskRepository) List(ctx context.Context) ([]*domain.Task, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+taskColumns+" FROM tasks ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]*domain.Task, 0)
+	byID := make(map[int]*domain.Task)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+		byID[task.ID] = task
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := r.loadRelations(ctx, byID, ""); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// loadRelations дочитывает теги и зависимости задач из byID. where
+// ограничивает выборку по task_id.
+func (r *TaskRepository) loadRelations(ctx context.Context, byID map[int]*domain.Task, where string, args ...any) error {
+	tagRows, err := r.db.QueryContext(ctx, r.dialect.Rebind("SELECT task_id, tag FROM task_tags"+where+" ORDER BY task_id, tag"), args...)
+	if err != nil {
+		return err
+	}
+	defer tagRows.Close()
+	for tagRows.Next() {
+		var id int
+		var tag string
+		if err := tagRows.Scan(&id, &tag); err != nil {
+			return err
+		}
+		// Задача могла появиться между запросами вне транзакции
+		if task, ok := byID[id]; ok {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+	if err := tagRows.Err(); err != nil {
+		return err
+	}
+	tagRows.Close()
+
+	depRows, err := r.db.QueryContext(ctx, r.dialect.Rebind("SELECT task_id, blocker_id FROM task_dependencies"+where+" ORDER BY task_id, blocker_id"), args...)
+	if err != nil {
+		return err
+	}
+	defer depRows.Close()
+	for depRows.Next() {
+		var id, blocker int
+		if err := depRows.Scan(&id, &blocker); err != nil {
+			return err
+		}
+		if task, ok := byID[id]; ok {
+			task.BlockedBy = append(task.BlockedBy, blocker)
+		}
+	}
+	return depRows.Err()
+}
+
+func (r *TaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	return r.inTx(ctx, func(tx *TaskRepository) error {
+		res, err := tx.db.ExecContext(ctx,
+			tx.dialect.Rebind(`UPDATE tasks SET headline = ?, description = ?, priority = ?, due_at = ?, due_timezone = ?,
+				parent_id = ?, recurrence = ?, occurrence = ?, series_id = ?,
+				status = ?, status_changed_at = ?, started_at = ?, cancelled_at = ?, done = ?, completed_at = ?, archived_at = ?, deleted_at = ?, version = ?, owner_id = ? WHERE id = ?`),
+			task.Headline, task.Description, string(task.Priority.OrDefault()), nullTime(task.DueAt), task.DueTimezone,
+			nullInt(task.ParentID), task.Recurrence, task.Occurrence, task.SeriesID,
+			string(task.CurrentStatus()), nullTime(task.StatusChangedAt), nullTime(task.StartedAt), nullTime(task.CancelledAt), task.Done, nullTime(task.CompletedAt),
+			nullTime(task.ArchivedAt), nullTime(task.DeletedAt), task.Version, task.OwnerID, task.ID,
+		)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(res); err != nil {
+			return err
+		}
+
+		for _, table := range []string{"task_tags", "task_dependencies"} {
+			if _, err := tx.db.ExecContext(ctx, tx.dialect.Rebind("DELETE FROM "+table+" WHERE task_id = ?"), task.ID); err != nil {
+				return err
+			}
+		}
+		return tx.insertRelations(ctx, task)
+	})
+}
+
+func (r *TaskRepository) Delete(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, r.dialect.Rebind("DELETE FROM tasks WHERE id = ?"), id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+func (r *TaskRepository) AppendRevision(ctx context.Context, rev *domain.Revision) error {
+	changes, err := json.Marshal(rev.Changes)
+	if err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(rev.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	return r.inTx(ctx, func(tx *TaskRepository) error {
+		// Строки нет, если нет самой задачи
+		var number int
+		err := tx.db.QueryRowContext(ctx,
+			tx.dialect.Rebind(`SELECT COALESCE(MAX(r.number), 0) + 1 FROM tasks t
+				LEFT JOIN task_revisions r ON r.task_id = t.id WHERE t.id = ? GROUP BY t.id`),
+			rev.TaskID,
+		).Scan(&number)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// JSON передаём строкой: []byte драйвер PostgreSQL отправил бы как bytea
+		_, err = tx.db.ExecContext(ctx,
+			tx.dialect.Rebind("INSERT INTO task_revisions (task_id, number, actor, action, revert_of, created_at, changes, snapshot) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"),
+			rev.TaskID, number, rev.Actor, rev.Action, rev.RevertOf, rev.At.UTC(), string(changes), string(snapshot),
+		)
+		if err != nil {
+			return err
+		}
+		rev.Number = number
+		return nil
+	})
+}
+
+func (r *TaskRepository) ListRevisions(ctx context.Context, taskID int) ([]*domain.Revision, error) {
+	rows, err := r.db.QueryContext(ctx,
+		r.dialect.Rebind("SELECT task_id, number, actor, action, revert_of, created_at, changes, snapshot FROM task_revisions WHERE task_id = ? ORDER BY number"),
+		taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]*domain.Revision, 0)
+	for rows.Next() {
+		var rev domain.Revision
+		var changes, snapshot []byte
+		if err := rows.Scan(&rev.TaskID, &rev.Number, &rev.Actor, &rev.Action, &rev.RevertOf, &rev.At, &changes, &snapshot); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &rev.Changes); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, &rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *TaskRepository) insertRelations(ctx context.Context, task *domain.Task) error {
+	for _, tag := range task.Tags {
+		_, err := r.db.ExecContext(ctx, r.dialect.Rebind("INSERT INTO task_tags (task_id, tag) VALUES (?, ?)"), task.ID, tag)
+		if err != nil {
+			return err
+		}
+	}
+	for _, blocker := range task.BlockedBy {
+		_, err := r.db.ExecContext(ctx, r.dialect.Rebind("INSERT INTO task_dependencies (task_id, blocker_id) VALUES (?, ?)"), task.ID, blocker)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(s scanner) (*domain.Task, error) {
+	var task domain.Task
+	var priority, status string
+	var dueAt, statusChangedAt, startedAt, cancelledAt, completedAt, archivedAt, deletedAt sql.NullTime
+	var parentID sql.NullInt64
+	err := s.Scan(&task.ID, &task.Headline, &task.Description, &priority, &dueAt, &task.DueTimezone,
+		&parentID, &task.Recurrence, &task.Occurrence, &task.SeriesID,
+		&status, &statusChangedAt, &startedAt, &cancelledAt, &task.Done, &task.CreatedAt, &completedAt, &archivedAt, &deletedAt, &task.Version, &task.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	task.Priority = domain.Priority(priority)
+	task.Status = domain.Status(status)
+	task.StatusChangedAt = timePtr(statusChangedAt)
+	task.StartedAt = timePtr(startedAt)
+	task.CancelledAt = timePtr(cancelledAt)
+	task.DueAt = timePtr(dueAt)
+	task.CompletedAt = timePtr(completedAt)
+	task.ArchivedAt = timePtr(archivedAt)
+	task.DeletedAt = timePtr(deletedAt)
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		task.ParentID = &id
+	}
+	return &task, nil
+}
+
+func nullInt(v *int) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*v), Valid: true}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func expectAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.TokenRepository = (*TokenRepository)(nil)
+
+type TokenRepository struct {
+	db      dbtx
+	dialect *Dialect
+}
+
+func NewTokenRepository(db *sql.DB, dialect *Dialect) *TokenRepository {
+	return &TokenRepository{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+const refreshTokenColumns = "id, user_id, family_id, hash, created_at, expires_at, used_at, revoked_at"
+
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind("INSERT INTO refresh_tokens ("+refreshTokenColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)"),
+		token.ID, token.UserID, token.FamilyID, token.Hash, token.CreatedAt.UTC(), token.ExpiresAt.UTC(),
+		nullTime(token.UsedAt), nullTime(token.RevokedAt),
+	)
+	if err != nil && r.dialect.isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *TokenRepository) GetRefreshToken(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	row := r.db.QueryRowContext(ctx,
+		r.dialect.Rebind("SELECT "+refreshTokenColumns+" FROM refresh_tokens WHERE hash = ?"),
+		hash,
+	)
+	var token domain.RefreshToken
+	var usedAt, revokedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.UserID, &token.FamilyID, &token.Hash, &token.CreatedAt, &token.ExpiresAt, &usedAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	token.UsedAt = timePtr(usedAt)
+	token.RevokedAt = timePtr(revokedAt)
+	return &token, nil
+}
+
+func (r *TokenRepository) UseRefreshToken(ctx context.Context, id int, at time.Time) error {
+	err := execOne(ctx, r.db,
+		r.dialect.Rebind("UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL"),
+		at.UTC(), id,
+	)
+	if !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	// Токена нет или он уже использован
+	var exists int
+	err = r.db.QueryRowContext(ctx, r.dialect.Rebind("SELECT 1 FROM refresh_tokens WHERE id = ?"), id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return domain.ErrTokenReused
+}
+
+func (r *TokenRepository) RevokeRefreshFamily(ctx context.Context, familyID int, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind("UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL"),
+		at.UTC(), familyID,
+	)
+	return err
+}
+
+func (r *TokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID int, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind("UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL"),
+		at.UTC(), userID,
+	)
+	return err
+}
+
+func (r *TokenRepository) RevokeAccessToken(ctx context.Context, token *domain.RevokedToken) error {
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind("INSERT INTO revoked_tokens (id, user_id, expires_at) VALUES (?, ?, ?) ON CONFLICT (id) DO NOTHING"),
+		token.ID, token.UserID, token.ExpiresAt.UTC(),
+	)
+	return err
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	var exists int
+	err := r.db.QueryRowContext(ctx, r.dialect.Rebind("SELECT 1 FROM revoked_tokens WHERE id = ?"), id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *TokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	total := 0
+	for _, table := range []string{"refresh_tokens", "revoked_tokens"} {
+		res, err := r.db.ExecContext(ctx, r.dialect.Rebind("DELETE FROM "+table+" WHERE expires_at < ?"), before.UTC())
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += int(n)
+	}
+	return total, nil
+}
@@ -0,0 +1,83 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+var _ repository.UserRepository = (*UserRepository)(nil)
+
+type UserRepository struct {
+	db      dbtx
+	dialect *Dialect
+}
+
+func NewUserRepository(db *sql.DB, dialect *Dialect) *UserRepository {
+	return &UserRepository{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+const userColumns = "id, name, password_hash, created_at"
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	_, err := r.db.ExecContext(ctx,
+		r.dialect.Rebind("INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?)"),
+		user.ID, user.Name, user.PasswordHash, user.CreatedAt.UTC(),
+	)
+	if err != nil && r.dialect.isUniqueViolation(err) {
+		return domain.ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *UserRepository) Get(ctx context.Context, id int) (*domain.User, error) {
+	return r.getBy(ctx, "id", id)
+}
+
+func (r *UserRepository) GetByName(ctx context.Context, name string) (*domain.User, error) {
+	return r.getBy(ctx, "name", name)
+}
+
+func (r *UserRepository) getBy(ctx context.Context, column string, value any) (*domain.User, error) {
+	row := r.db.QueryRowContext(ctx,
+		r.dialect.Rebind("SELECT "+userColumns+" FROM users WHERE "+column+" = ?"),
+		value,
+	)
+	var user domain.User
+	err := row.Scan(&user.ID, &user.Name, &user.PasswordHash, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) List(ctx context.Context) ([]*domain.User, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+userColumns+" FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0)
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ID, &user.Name, &user.PasswordHash, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, hash string) error {
+	return execOne(ctx, r.db, r.dialect.Rebind("UPDATE users SET password_hash = ? WHERE id = ?"), hash, id)
+}
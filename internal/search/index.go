@@ -0,0 +1,237 @@
+// Package search реализует полнотекстовый поиск по заголовкам и описаниям
+// задач на основе инвертированного индекса.
+package search
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+)
+
+type field int
+
+const (
+	fieldHeadline field = iota
+	fieldDescription
+	fieldCount
+)
+
+// Совпадение в заголовке весит больше, чем в описании.
+var fieldWeights = [fieldCount]float64{fieldHeadline: 2.5, fieldDescription: 1}
+
+// Параметры ранжирования BM25.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+type posting struct {
+	positions [fieldCount][]int
+}
+
+func (p *posting) weightedFreq() float64 {
+	var tf float64
+	for f := range fieldCount {
+		tf += fieldWeights[f] * float64(len(p.positions[f]))
+	}
+	return tf
+}
+
+type document struct {
+	terms  []string
+	length float64
+}
+
+type Result struct {
+	ID    int
+	Score float64
+}
+
+// Index — инвертированный индекс: для каждого слова хранит документы и
+// позиции вхождений. Index не синхронизирован: изменения должны
+// выполняться под внешней блокировкой, исключающей параллельный поиск.
+type Index struct {
+	postings    map[string]map[int]*posting
+	docs        map[int]*document
+	vocab       []string
+	totalLength float64
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int]*posting),
+		docs:     make(map[int]*document),
+	}
+}
+
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+// Add индексирует документ, заменяя предыдущую версию с тем же id.
+func (ix *Index) Add(id int, headline string, description string) {
+	ix.Remove(id)
+
+	doc := &document{}
+	for f, text := range [fieldCount]string{fieldHeadline: headline, fieldDescription: description} {
+		tokens := tokenize(text)
+		doc.length += fieldWeights[f] * float64(len(tokens))
+		for _, tok := range tokens {
+			docs, ok := ix.postings[tok.term]
+			if !ok {
+				docs = make(map[int]*posting)
+				ix.postings[tok.term] = docs
+				i, _ := slices.BinarySearch(ix.vocab, tok.term)
+				ix.vocab = slices.Insert(ix.vocab, i, tok.term)
+			}
+			p, ok := docs[id]
+			if !ok {
+				p = &posting{}
+				docs[id] = p
+				doc.terms = append(doc.terms, tok.term)
+			}
+			p.positions[f] = append(p.positions[f], tok.pos)
+		}
+	}
+
+	ix.docs[id] = doc
+	ix.totalLength += doc.length
+}
+
+func (ix *Index) Remove(id int) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		docs := ix.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(ix.postings, term)
+			if i, found := slices.BinarySearch(ix.vocab, term); found {
+				ix.vocab = slices.Delete(ix.vocab, i, i+1)
+			}
+		}
+	}
+
+	ix.totalLength -= doc.length
+	delete(ix.docs, id)
+}
+
+// Search возвращает документы, удовлетворяющие всем условиям запроса, в
+// порядке убывания релевантности.
+func (ix *Index) Search(query Query) []Result {
+	var scores map[int]float64
+	for _, c := range query.clauses {
+		clauseScores := ix.scoreClause(c)
+		if scores == nil {
+			scores = clauseScores
+		} else {
+			for id, score := range scores {
+				if s, ok := clauseScores[id]; ok {
+					scores[id] = score + s
+				} else {
+					delete(scores, id)
+				}
+			}
+		}
+		if len(scores) == 0 {
+			return []Result{}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, Result{ID: id, Score: score})
+	}
+	slices.SortFunc(results, func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return results
+}
+
+func (ix *Index) scoreClause(c clause) map[int]float64 {
+	switch c.kind {
+	case clausePrefix:
+		// Для префикса берём лучшее из подходящих слов, чтобы документ с
+		// несколькими формами слова не получал преимущества
+		scores := make(map[int]float64)
+		i, _ := slices.BinarySearch(ix.vocab, c.terms[0])
+		for ; i < len(ix.vocab) && strings.HasPrefix(ix.vocab[i], c.terms[0]); i++ {
+			for id, score := range ix.scoreTerm(ix.vocab[i]) {
+				scores[id] = max(scores[id], score)
+			}
+		}
+		return scores
+	case clausePhrase:
+		return ix.scorePhrase(c.terms)
+	default:
+		return ix.scoreTerm(c.terms[0])
+	}
+}
+
+func (ix *Index) scoreTerm(term string) map[int]float64 {
+	docs := ix.postings[term]
+	scores := make(map[int]float64, len(docs))
+	idf := ix.idf(len(docs))
+	for id, p := range docs {
+		scores[id] = ix.bm25(idf, p.weightedFreq(), id)
+	}
+	return scores
+}
+
+func (ix *Index) scorePhrase(terms []string) map[int]float64 {
+	first := ix.postings[terms[0]]
+	var idf float64
+	for _, term := range terms {
+		idf += ix.idf(len(ix.postings[term]))
+	}
+
+	scores := make(map[int]float64)
+	for id, p := range first {
+		var tf float64
+		for f := range fieldCount {
+			for _, pos := range p.positions[f] {
+				if ix.phraseAt(terms[1:], id, f, pos+1) {
+					tf += fieldWeights[f]
+				}
+			}
+		}
+		if tf > 0 {
+			scores[id] = ix.bm25(idf, tf, id)
+		}
+	}
+	return scores
+}
+
+func (ix *Index) phraseAt(terms []string, id int, f field, pos int) bool {
+	for i, term := range terms {
+		p, ok := ix.postings[term][id]
+		if !ok {
+			return false
+		}
+		if _, found := slices.BinarySearch(p.positions[f], pos+i); !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (ix *Index) idf(df int) float64 {
+	n := float64(len(ix.docs))
+	return math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+func (ix *Index) bm25(idf float64, tf float64, id int) float64 {
+	avg := ix.totalLength / float64(len(ix.docs))
+	norm := 1.0
+	if avg > 0 {
+		norm = 1 - bm25B + bm25B*ix.docs[id].length/avg
+	}
+	return idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+}
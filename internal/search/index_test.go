@@ -0,0 +1,126 @@
+package search
+
+import (
+	"testing"
+)
+
+func mustParse(t *testing.T, q string) Query {
+	t.Helper()
+	query, err := ParseQuery(q)
+	if err != nil {
+		t.Fatalf("parse %q: %v", q, err)
+	}
+	return query
+}
+
+func ids(results []Result) []int {
+	ids := make([]int, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func newTestIndex() *Index {
+	ix := NewIndex()
+	ix.Add(1, "Купить молоко", "Зайти в магазин после работы")
+	ix.Add(2, "Написать отчёт", "Еженедельный отчет для руководства, молоко не забыть")
+	ix.Add(3, "Отчеты за квартал", "Собрать цифры")
+	ix.Add(4, "Deploy backend", "Run migrations, then deploy")
+	return ix
+}
+
+func TestSearchMultiWord(t *testing.T) {
+	ix := newTestIndex()
+
+	got := ids(ix.Search(mustParse(t, "молоко магазин")))
+	if len(got) != 1 || got[0] != 1 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestSearchRanksHeadlineHigher(t *testing.T) {
+	ix := newTestIndex()
+
+	got := ids(ix.Search(mustParse(t, "МОЛОКО")))
+	if len(got) != 2 || got[0] != 1 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestSearchYoNormalization(t *testing.T) {
+	ix := newTestIndex()
+
+	for _, q := range []string{"отчёт", "отчет"} {
+		got := ids(ix.Search(mustParse(t, q)))
+		if len(got) != 1 || got[0] != 2 {
+			t.Errorf("%s: got %v", q, got)
+		}
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	ix := newTestIndex()
+
+	got := ids(ix.Search(mustParse(t, "отч*")))
+	if len(got) != 2 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestSearchPhrase(t *testing.T) {
+	ix := newTestIndex()
+
+	got := ids(ix.Search(mustParse(t, `"написать отчет"`)))
+	if len(got) != 1 || got[0] != 2 {
+		t.Errorf("got %v", got)
+	}
+
+	got = ids(ix.Search(mustParse(t, `"отчет написать"`)))
+	if len(got) != 0 {
+		t.Errorf("reversed phrase matched: %v", got)
+	}
+}
+
+func TestSearchUpdateAndRemove(t *testing.T) {
+	ix := newTestIndex()
+
+	ix.Add(4, "Deploy frontend", "")
+	if got := ids(ix.Search(mustParse(t, "backend"))); len(got) != 0 {
+		t.Errorf("stale terms: %v", got)
+	}
+	if got := ids(ix.Search(mustParse(t, "frontend"))); len(got) != 1 {
+		t.Errorf("new terms: %v", got)
+	}
+
+	ix.Remove(1)
+	if got := ids(ix.Search(mustParse(t, "магазин"))); len(got) != 0 {
+		t.Errorf("removed doc found: %v", got)
+	}
+	if ix.Len() != 3 {
+		t.Errorf("len != 3: %d", ix.Len())
+	}
+	for _, term := range ix.vocab {
+		if term == "магазин" {
+			t.Error("vocab not cleaned")
+		}
+	}
+}
+
+func TestParseQueryEmpty(t *testing.T) {
+	for _, q := range []string{"", "   ", `""`, "!!! ---"} {
+		if _, err := ParseQuery(q); err != ErrEmptyQuery {
+			t.Errorf("%q: want ErrEmptyQuery, got %v", q, err)
+		}
+	}
+}
+
+func TestParseQueryMixed(t *testing.T) {
+	query := mustParse(t, `бэкенд "ночной деплой"отч*`)
+	if len(query.clauses) != 3 {
+		t.Fatalf("clauses != 3: %d", len(query.clauses))
+	}
+	if query.clauses[0].kind != clauseTerm || query.clauses[1].kind != clausePhrase || query.clauses[2].kind != clausePrefix {
+		t.Errorf("kinds: %v", query.clauses)
+	}
+}
@@ -0,0 +1,87 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrEmptyQuery = errors.New("empty search query")
+
+type clauseKind int
+
+const (
+	clauseTerm clauseKind = iota
+	clausePrefix
+	clausePhrase
+)
+
+type clause struct {
+	kind  clauseKind
+	terms []string
+}
+
+// Query — разобранный поисковый запрос. Все условия должны выполняться
+// одновременно:
+//
+//	купить молоко    — оба слова в любом месте задачи
+//	отчёт*           — слово, начинающееся с "отчёт"
+//	"купить молоко"  — слова подряд в заголовке или описании
+type Query struct {
+	clauses []clause
+}
+
+func ParseQuery(q string) (Query, error) {
+	var query Query
+	rest := q
+	for {
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			break
+		}
+
+		if rest[0] == '"' {
+			phrase, tail, _ := strings.Cut(rest[1:], `"`)
+			rest = tail
+			terms := terms(tokenize(phrase))
+			switch len(terms) {
+			case 0:
+			case 1:
+				query.clauses = append(query.clauses, clause{kind: clauseTerm, terms: terms})
+			default:
+				query.clauses = append(query.clauses, clause{kind: clausePhrase, terms: terms})
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		rest = rest[end:]
+
+		prefix := strings.HasSuffix(word, "*")
+		terms := terms(tokenize(strings.TrimSuffix(word, "*")))
+		for i, term := range terms {
+			kind := clauseTerm
+			if prefix && i == len(terms)-1 {
+				kind = clausePrefix
+			}
+			query.clauses = append(query.clauses, clause{kind: kind, terms: []string{term}})
+		}
+	}
+
+	if len(query.clauses) == 0 {
+		return query, ErrEmptyQuery
+	}
+	return query, nil
+}
+
+func terms(tokens []token) []string {
+	terms := make([]string, len(tokens))
+	for i, t := range tokens {
+		terms[i] = t.term
+	}
+	return terms
+}
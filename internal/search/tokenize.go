@@ -0,0 +1,46 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+type token struct {
+	term string
+	pos  int
+}
+
+// tokenize разбивает текст на слова из букв и цифр любого алфавита и
+// приводит их к нормальной форме (см. normalize).
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{term: normalize(text[start:i]), pos: len(tokens)})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: normalize(text[start:]), pos: len(tokens)})
+	}
+	return tokens
+}
+
+// normalize приводит слово к нижнему регистру и заменяет "ё" на "е": в
+// русских текстах их пишут вперемешку, и поиск не должен зависеть от этого.
+func normalize(word string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if r == 'ё' {
+			return 'е'
+		}
+		return r
+	}, word)
+}
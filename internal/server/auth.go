@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/handlers"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+// Authenticator находит пользователя по ключу API или токену доступа из
+// заголовка Authorization и возвращает domain.ErrUnauthorized для
+// неверных учётных данных.
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*domain.Identity, error)
+}
+
+// WithAuthenticator включает проверку заголовка Authorization: Bearer
+// <ключ или токен>. Пользователь попадает в контекст запроса, см.
+// domain.WithIdentity, и становится автором изменений; X-Actor лишь
+// уточняет клиента, см. userActor. Если required выключен, запросы без
+// заголовка выполняются без пользователя.
+func WithAuthenticator(auth Authenticator, required bool) Option {
+	return func(o *options) {
+		o.auth = auth
+		o.authRequired = required
+	}
+}
+
+// isPublic сообщает, доступен ли путь без ключа: без регистрации новому
+// пользователю негде взять первый ключ, а вход и обновление токенов
+// сами проверяют учётные данные из тела запроса.
+func isPublic(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/users", "/auth/login", "/auth/refresh":
+		return true
+	}
+	return false
+}
+
+func withAuth(auth Authenticator, required bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPublic(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			if required {
+				handlers.SendUnauthorized(w, domain.ErrUnauthorized.Error()+": API key or access token is required")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scheme, secret, _ := strings.Cut(header, " ")
+		secret = strings.TrimSpace(secret)
+		if !strings.EqualFold(scheme, "Bearer") || secret == "" {
+			handlers.SendUnauthorized(w, domain.ErrUnauthorized.Error()+": authorization header must use the Bearer scheme")
+			return
+		}
+
+		identity, err := auth.Authenticate(r.Context(), secret)
+		if err != nil {
+			if errors.Is(err, domain.ErrUnauthorized) {
+				logger.Logger.Warn("authentication failed", "path", r.URL.Path, "error", err.Error())
+				handlers.SendUnauthorized(w, err.Error())
+				return
+			}
+			logger.Logger.Error("internal server error", "error", err.Error())
+			sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		ctx := domain.WithIdentity(r.Context(), identity)
+		ctx = domain.WithActor(ctx, userActor(identity.User.Name, r.Header.Get(ActorHeader)))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// userActor возвращает автора изменений для запроса пользователя name.
+// Клиент из X-Actor записывается в скобках после имени: назваться чужим
+// именем он не может, но его изменения отличимы от других клиентов того
+// же пользователя — в истории, стеке отмены и ключах идемпотентности.
+func userActor(name string, client string) string {
+	client = strings.TrimSpace(client)
+	if client == "" || client == name {
+		return name
+	}
+	return name + " (" + client + ")"
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+type stubAuthenticator map[string]*domain.User
+
+func (a stubAuthenticator) Authenticate(_ context.Context, credential string) (*domain.Identity, error) {
+	user, ok := a[credential]
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	return &domain.Identity{User: user, Method: domain.AuthAPIKey}, nil
+}
+
+func TestAuthenticatedActor(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	auth := stubAuthenticator{"secret": {ID: 1, Name: "alice"}}
+
+	var actor string
+	var userID int
+	handler := withActor(withAuth(auth, true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor = domain.ActorFrom(r.Context())
+		userID = domain.UserID(r.Context())
+	})))
+
+	for _, tc := range []struct{ header, want string }{
+		{"", "alice"},
+		{"alice", "alice"},
+		{"bob", "alice (bob)"},
+		{" ci-bot ", "alice (ci-bot)"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/todos", nil)
+		r.Header.Set("Authorization", "Bearer secret")
+		r.Header.Set(ActorHeader, tc.header)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || actor != tc.want || userID != 1 {
+			t.Errorf("X-Actor %q: %d, actor %q, user %d", tc.header, w.Code, actor, userID)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/todos", nil)
+	r.Header.Set("Authorization", "Bearer wrong")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("wrong key: %d", w.Code)
+	}
+}
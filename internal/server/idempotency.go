@@ -0,0 +1,237 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+const (
+	// IdempotencyHeader — заголовок с ключом, по которому повтор запроса
+	// получает сохранённый ответ первого запроса вместо нового изменения.
+	IdempotencyHeader = "Idempotency-Key"
+	// ReplayedHeader помечает ответ, взятый из сохранённых.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	defaultIdempotencyTTL = 24 * time.Hour
+	maxIdempotencyKeyLen  = 255
+	// maxIdempotentBodySize — наибольшее тело запроса с ключом: тело
+	// читается в память целиком, чтобы посчитать его хеш.
+	maxIdempotentBodySize = 1 << 20
+	// defaultIdempotencyEntries — сколько ключей хранится одновременно.
+	defaultIdempotencyEntries = 10000
+)
+
+// idempotencyStore хранит ответы на запросы с Idempotency-Key. Ключи
+// принадлежат пользователю и автору изменений из контекста, поэтому разные
+// клиенты могут использовать одинаковые ключи. Хранится не больше
+// maxEntries ключей: при переполнении вытесняются сохранённые ответы с
+// самым ранним сроком, а если все ключи заняты выполняющимися запросами,
+// новый запрос получает 503.
+type idempotencyStore struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mtx       sync.Mutex
+	entries   map[string]*idempotencyEntry
+	nextSweep time.Time
+}
+
+type idempotencyEntry struct {
+	// fingerprint — хеш метода, пути и тела запроса.
+	fingerprint [sha256.Size]byte
+	expiresAt   time.Time
+	// done — ответ уже сохранён; до этого запрос ещё выполняется.
+	done   bool
+	status int
+	header http.Header
+	body   []byte
+}
+
+func newIdempotencyStore(ttl time.Duration) *idempotencyStore {
+	return &idempotencyStore{
+		ttl:        ttl,
+		maxEntries: defaultIdempotencyEntries,
+		entries:    make(map[string]*idempotencyEntry),
+	}
+}
+
+func (s *idempotencyStore) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyHeader)
+		if key == "" || !isMutation(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			sendError(w, domain.ErrInvalidRequest.Error()+": idempotency key is too long", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			logger.Logger.Warn("idempotent request body is too large", "key", key, "limit", tooLarge.Limit)
+			sendError(w, domain.ErrInvalidRequest.Error()+": request body is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			logger.Logger.Error("failed to read request body", "error", err.Error())
+			sendError(w, domain.ErrInternalError.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		h := sha256.New()
+		io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+		h.Write(body)
+		var fingerprint [sha256.Size]byte
+		h.Sum(fingerprint[:0])
+
+		id := strconv.Itoa(domain.UserID(r.Context())) + "\x00" + domain.ActorFrom(r.Context()) + "\x00" + key
+		entry, ok, full := s.begin(id, fingerprint)
+		switch {
+		case full:
+			logger.Logger.Warn("idempotency store is full", "key", key)
+			sendError(w, domain.ErrIdempotencyStoreFull.Error(), http.StatusServiceUnavailable)
+			return
+		case !ok:
+			// Новый ключ: выполняем запрос и запоминаем ответ
+		case entry.fingerprint != fingerprint:
+			logger.Logger.Warn("idempotency key reused", "key", key)
+			sendError(w, domain.ErrIdempotencyKeyReused.Error(), http.StatusUnprocessableEntity)
+			return
+		case !entry.done:
+			logger.Logger.Warn("idempotent request in progress", "key", key)
+			sendError(w, domain.ErrIdempotencyInProgress.Error(), http.StatusConflict)
+			return
+		default:
+			logger.Logger.Info("replaying idempotent response", "key", key, "status", entry.status)
+			for name, values := range entry.header {
+				w.Header()[name] = values
+			}
+			w.Header().Set(ReplayedHeader, "true")
+			w.WriteHeader(entry.status)
+			w.Write(entry.body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w}
+		defer s.finish(id, rec)
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// begin возвращает запись ключа id, если она есть и не устарела. Иначе
+// заводит новую запись о выполняющемся запросе и возвращает ok = false;
+// full = true — места для новой записи нет.
+func (s *idempotencyStore) begin(id string, fingerprint [sha256.Size]byte) (entry idempotencyEntry, ok bool, full bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	if e, ok := s.entries[id]; ok && now.Before(e.expiresAt) {
+		return *e, true, false
+	}
+	if _, exists := s.entries[id]; !exists && len(s.entries) >= s.maxEntries && !s.evict(now) {
+		return idempotencyEntry{}, false, true
+	}
+	s.entries[id] = &idempotencyEntry{fingerprint: fingerprint, expiresAt: now.Add(s.ttl)}
+	return idempotencyEntry{}, false, false
+}
+
+// evict освобождает место для новой записи: удаляет устаревшие записи, а
+// если таких нет — сохранённый ответ с самым ранним сроком. Возвращает
+// false, если все записи принадлежат выполняющимся запросам.
+func (s *idempotencyStore) evict(now time.Time) bool {
+	s.nextSweep = time.Time{}
+	s.sweep(now)
+	if len(s.entries) < s.maxEntries {
+		return true
+	}
+
+	var oldest string
+	for id, entry := range s.entries {
+		if entry.done && (oldest == "" || entry.expiresAt.Before(s.entries[oldest].expiresAt)) {
+			oldest = id
+		}
+	}
+	if oldest == "" {
+		return false
+	}
+	delete(s.entries, oldest)
+	return true
+}
+
+// finish сохраняет ответ на запрос с ключом id. Ответы 5xx и оборванные
+// запросы не сохраняются, чтобы клиент мог повторить запрос после сбоя.
+func (s *idempotencyStore) finish(id string, rec *responseRecorder) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return
+	}
+	if rec.status == 0 || rec.status >= http.StatusInternalServerError {
+		delete(s.entries, id)
+		return
+	}
+	entry.done = true
+	entry.status = rec.status
+	entry.header = rec.Header().Clone()
+	entry.body = rec.body.Bytes()
+	entry.expiresAt = time.Now().Add(s.ttl)
+}
+
+// sweep удаляет устаревшие записи, но не чаще раза в минуту.
+func (s *idempotencyStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	s.nextSweep = now.Add(time.Minute)
+	for id, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, id)
+		}
+	}
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder передаёт ответ клиенту и запоминает его копию.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/pkg/logger"
+)
+
+// countingHandler отвечает кодом status и номером вызова в теле.
+type countingHandler struct {
+	calls  int
+	status int
+	// block, если задан, держит запрос до закрытия канала.
+	started chan struct{}
+	block   chan struct{}
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	if h.block != nil {
+		close(h.started)
+		<-h.block
+	}
+	w.Header().Set("Location", "/todos/1")
+	w.WriteHeader(h.status)
+	io.WriteString(w, strconv.Itoa(h.calls))
+}
+
+func idempotentRequest(handler http.Handler, key string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(body))
+	r.Header.Set(IdempotencyHeader, key)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	next := &countingHandler{status: http.StatusCreated}
+	handler := newIdempotencyStore(time.Hour).wrap(next)
+
+	first := idempotentRequest(handler, "k1", `{"headline":"A"}`)
+	second := idempotentRequest(handler, "k1", `{"headline":"A"}`)
+	if next.calls != 1 {
+		t.Fatalf("handler called %d times", next.calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() ||
+		second.Header().Get("Location") != "/todos/1" || second.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("replay: %d %q %v", second.Code, second.Body, second.Header())
+	}
+	if first.Header().Get(ReplayedHeader) != "" {
+		t.Error("first response marked as replayed")
+	}
+
+	if w := idempotentRequest(handler, "k1", `{"headline":"B"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key: want 422, got %d", w.Code)
+	}
+
+	// Ключи другого клиента не пересекаются с первым
+	r := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"headline":"A"}`))
+	r.Header.Set(IdempotencyHeader, "k1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r.WithContext(domain.WithActor(r.Context(), "bob")))
+	if w.Code != http.StatusCreated || next.calls != 2 {
+		t.Errorf("other client: %d, calls %d", w.Code, next.calls)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	next := &countingHandler{status: http.StatusCreated, started: make(chan struct{}), block: make(chan struct{})}
+	handler := newIdempotencyStore(time.Hour).wrap(next)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- idempotentRequest(handler, "k1", "{}") }()
+	<-next.started
+
+	if w := idempotentRequest(handler, "k1", "{}"); w.Code != http.StatusConflict {
+		t.Errorf("in progress: want 409, got %d", w.Code)
+	}
+	close(next.block)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Errorf("first request: %d", w.Code)
+	}
+}
+
+func TestIdempotencySkipsServerErrors(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	next := &countingHandler{status: http.StatusInternalServerError}
+	handler := newIdempotencyStore(time.Hour).wrap(next)
+
+	if w := idempotentRequest(handler, "k1", "{}"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first: %d", w.Code)
+	}
+	next.status = http.StatusCreated
+	w := idempotentRequest(handler, "k1", "{}")
+	if w.Code != http.StatusCreated || w.Header().Get(ReplayedHeader) != "" || next.calls != 2 {
+		t.Errorf("retry after 5xx: %d, calls %d", w.Code, next.calls)
+	}
+}
+
+func TestIdempotencyExpires(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	next := &countingHandler{status: http.StatusCreated}
+	ttl := 20 * time.Millisecond
+	handler := newIdempotencyStore(ttl).wrap(next)
+
+	idempotentRequest(handler, "k1", "{}")
+	time.Sleep(2 * ttl)
+	w := idempotentRequest(handler, "k1", `{"headline":"other body"}`)
+	if w.Code != http.StatusCreated || w.Header().Get(ReplayedHeader) != "" || next.calls != 2 {
+		t.Errorf("expired key: %d, calls %d", w.Code, next.calls)
+	}
+}
+
+func TestIdempotencyLimits(t *testing.T) {
+	logger.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	next := &countingHandler{status: http.StatusCreated}
+	store := newIdempotencyStore(time.Hour)
+	store.maxEntries = 2
+	handler := store.wrap(next)
+
+	if w := idempotentRequest(handler, "big", strings.Repeat("x", maxIdempotentBodySize+1)); w.Code != http.StatusRequestEntityTooLarge || next.calls != 0 {
+		t.Errorf("large body: %d, calls %d", w.Code, next.calls)
+	}
+
+	// Третий ключ вытесняет самый старый сохранённый ответ
+	for _, key := range []string{"k1", "k2", "k3"} {
+		idempotentRequest(handler, key, "{}")
+		time.Sleep(time.Millisecond)
+	}
+	if len(store.entries) != 2 {
+		t.Errorf("entries: %d", len(store.entries))
+	}
+	if w := idempotentRequest(handler, "k3", "{}"); w.Header().Get(ReplayedHeader) != "true" {
+		t.Error("newest key was evicted")
+	}
+	if w := idempotentRequest(handler, "k1", "{}"); w.Header().Get(ReplayedHeader) != "" || next.calls != 4 {
+		t.Errorf("evicted key replayed, calls %d", next.calls)
+	}
+
+	// Места нет, пока все ключи заняты выполняющимися запросами
+	store.entries = map[string]*idempotencyEntry{
+		"a": {expiresAt: time.Now().Add(time.Hour)},
+		"b": {expiresAt: time.Now().Add(time.Hour)},
+	}
+	if w := idempotentRequest(handler, "k4", "{}"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("full store: want 503, got %d", w.Code)
+	}
+}
@@ -10,7 +10,28 @@ import (
 	"github.com/S1FFFkA/todo-list/internal/handlers"
 )
 
-func NewRouter(taskHandler *handlers.TaskHandler) http.Handler {
+type Option func(o *options)
+
+type options struct {
+	idempotencyTTL time.Duration
+	auth           Authenticator
+	authRequired   bool
+}
+
+// WithIdempotencyTTL задаёт, сколько хранится ответ на запрос с
+// Idempotency-Key; 0 отключает поддержку ключей.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.idempotencyTTL = ttl
+	}
+}
+
+func NewRouter(taskHandler *handlers.TaskHandler, userHandler *handlers.UserHandler, authHandler *handlers.AuthHandler, opts ...Option) http.Handler {
+	o := options{idempotencyTTL: defaultIdempotencyTTL}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/todos", func(w http.ResponseWriter, r *http.Request) {
@@ -24,6 +45,42 @@ func NewRouter(taskHandler *handlers.TaskHandler) http.Handler {
 		}
 	})
 
+	mux.HandleFunc("/todos/search", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			taskHandler.SearchTasks(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/todos/order", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			taskHandler.GetTaskOrder(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/todos:batch", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			taskHandler.Batch(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/todos:bulk", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			taskHandler.Bulk(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc("/todos/", func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/todos/")
 		if path == "" {
@@ -31,13 +88,47 @@ func NewRouter(taskHandler *handlers.TaskHandler) http.Handler {
 			return
 		}
 
+		if _, action, ok := strings.Cut(path, "/"); ok {
+			switch action {
+			case "children":
+				taskHandler.ListChildren(w, r)
+			case "subtree":
+				taskHandler.GetSubtree(w, r)
+			case "occurrences":
+				taskHandler.PreviewOccurrences(w, r)
+			case "transitions":
+				taskHandler.TransitionTask(w, r)
+			case "complete":
+				taskHandler.CompleteTask(w, r)
+			case "archive":
+				taskHandler.ArchiveTask(w, r)
+			case "unarchive":
+				taskHandler.UnarchiveTask(w, r)
+			case "history":
+				taskHandler.TaskHistory(w, r)
+			default:
+				// revisions/{n} и revisions/{n}/revert
+				rest, isRevision := strings.CutPrefix(action, "revisions/")
+				_, sub, hasSub := strings.Cut(rest, "/")
+				switch {
+				case isRevision && !hasSub:
+					taskHandler.GetTaskRevision(w, r)
+				case isRevision && sub == "revert":
+					taskHandler.RevertTask(w, r)
+				default:
+					sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+				}
+			}
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			taskHandler.GetTask(w, r)
 		case http.MethodPut:
 			taskHandler.UpdateTask(w, r)
 		case http.MethodPatch:
-			taskHandler.CompleteTask(w, r)
+			taskHandler.PatchTask(w, r)
 		case http.MethodDelete:
 			taskHandler.DeleteTask(w, r)
 		default:
@@ -45,7 +136,188 @@ func NewRouter(taskHandler *handlers.TaskHandler) http.Handler {
 		}
 	})
 
-	return mux
+	mux.HandleFunc("/undo", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			taskHandler.Undo(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/redo", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			taskHandler.Redo(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/trash", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			taskHandler.ListTrash(w, r)
+		case http.MethodDelete:
+			taskHandler.EmptyTrash(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/trash/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/trash/")
+		if path == "" {
+			sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if _, action, ok := strings.Cut(path, "/"); ok {
+			switch action {
+			case "restore":
+				taskHandler.RestoreTask(w, r)
+			default:
+				sendError(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+			}
+			return
+		}
+
+		switch r.Method {
+		case http.MethodDelete:
+			taskHandler.PurgeTask(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			userHandler.CreateUser(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/users/me/password", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			authHandler.ChangePassword(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			authHandler.Login(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			authHandler.Refresh(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/auth/logout", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			authHandler.Logout(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api-keys", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			userHandler.CreateAPIKey(w, r)
+		case http.MethodGet:
+			userHandler.ListAPIKeys(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api-keys/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, "/api-keys/") == "" {
+			sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodDelete:
+			userHandler.RevokeAPIKey(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/tags", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			taskHandler.ListTags(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/tags/merge", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			taskHandler.MergeTags(w, r)
+		case http.MethodPatch:
+			// Переименование тега с именем "merge"
+			taskHandler.RenameTag(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/tags/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, "/tags/") == "" {
+			sendError(w, domain.ErrInvalidRequest.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPatch:
+			taskHandler.RenameTag(w, r)
+		default:
+			sendError(w, domain.ErrMethodNotAllowed.Error(), http.StatusMethodNotAllowed)
+		}
+	})
+
+	var handler http.Handler = mux
+	if o.idempotencyTTL > 0 {
+		handler = newIdempotencyStore(o.idempotencyTTL).wrap(handler)
+	}
+	// Ключи идемпотентности принадлежат пользователю, поэтому проверка
+	// учётных данных идёт раньше
+	if o.auth != nil {
+		handler = withAuth(o.auth, o.authRequired, handler)
+	}
+	return withActor(handler)
+}
+
+// ActorHeader — заголовок, которым клиент называет автора изменений. Имя
+// попадает в историю ревизий задач.
+const ActorHeader = "X-Actor"
+
+func withActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := strings.TrimSpace(r.Header.Get(ActorHeader)); actor != "" {
+			r = r.WithContext(domain.WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 func sendError(w http.ResponseWriter, message string, statusCode int) {
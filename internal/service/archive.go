@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// ArchiveTask убирает закрытую задачу в архив вместе со всеми подзадачами.
+// Если задача или какая-то из подзадач не закрыта, возвращается
+// domain.ErrNotClosed со списком незакрытых задач.
+func (s *TaskService) ArchiveTask(ctx context.Context, id int) (*domain.Task, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if !s.owns(ctx, id) {
+		return nil, domain.ErrNotFound
+	}
+	ids := append([]int{id}, s.tree.descendants(id)...)
+	var open []int
+	for _, id := range ids {
+		if !s.tree.isClosed(id) {
+			open = append(open, id)
+		}
+	}
+	if len(open) > 0 {
+		slices.Sort(open)
+		return nil, fmt.Errorf("%w: %s", domain.ErrNotClosed, formatIDs(open))
+	}
+
+	now := time.Now()
+	tasks, err := s.setArchived(ctx, ids, &now)
+	if err != nil {
+		return nil, err
+	}
+	task := tasks[0]
+	s.decorate(task)
+	return task, nil
+}
+
+// UnarchiveTask возвращает задачу из архива вместе со всеми подзадачами.
+func (s *TaskService) UnarchiveTask(ctx context.Context, id int) (*domain.Task, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if !s.owns(ctx, id) {
+		return nil, domain.ErrNotFound
+	}
+	tasks, err := s.setArchived(ctx, append([]int{id}, s.tree.descendants(id)...), nil)
+	if err != nil {
+		return nil, err
+	}
+	task := tasks[0]
+	s.decorate(task)
+	return task, nil
+}
+
+// ArchiveClosed убирает в архив задачи, закрытые раньше before, вместе с
+// подзадачами и возвращает число задач, попавших в архив. Задачи с
+// незакрытыми подзадачами остаются на месте.
+func (s *TaskService) ArchiveClosed(ctx context.Context, before time.Time) (int, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	tasks, err := listActive(ctx, s.repo)
+	if err != nil {
+		return 0, err
+	}
+
+	archived := make(map[int]bool)
+	var ids []int
+	for _, task := range tasks {
+		if task.IsArchived() {
+			archived[task.ID] = true
+		}
+	}
+	for _, task := range tasks {
+		closedAt, ok := task.ClosedAt()
+		if !ok || archived[task.ID] || !closedAt.Before(before) {
+			continue
+		}
+		subtree := append([]int{task.ID}, s.tree.descendants(task.ID)...)
+		if slices.ContainsFunc(subtree, func(id int) bool { return !s.tree.isClosed(id) }) {
+			continue
+		}
+		for _, id := range subtree {
+			if !archived[id] {
+				archived[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	if _, err := s.setArchived(ctx, ids, &now); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// setArchived задаёт задачам ids время архивации в одной транзакции. Задачи,
+// уже находящиеся в нужном состоянии, не меняются. Архив не влияет на
+// индексы сервиса, поэтому после ошибки их не нужно перестраивать.
+func (s *TaskService) setArchived(ctx context.Context, ids []int, archivedAt *time.Time) ([]*domain.Task, error) {
+	typ := domain.EventTaskUnarchived
+	if archivedAt != nil {
+		typ = domain.EventTaskArchived
+	}
+	tasks := make([]*domain.Task, 0, len(ids))
+	err := repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+		for _, id := range ids {
+			task, err := getActive(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			tasks = append(tasks, task)
+			if task.IsArchived() == (archivedAt != nil) {
+				continue
+			}
+			task.ArchivedAt = archivedAt
+			if err := saveTask(ctx, tx, typ, task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func listArchived(t *testing.T, service *TaskService, archived bool) []*domain.Task {
+	t.Helper()
+	page, err := service.ListTasks(context.Background(), domain.TaskQuery{Filter: domain.TaskFilter{Archived: &archived}})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	return page.Tasks
+}
+
+func TestArchiveAndUnarchive(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	parent := mustCreate(t, service, "Parent", "D")
+	child := mustCreateChild(t, service, "Child", parent.ID)
+
+	if _, err := service.UpdateTask(ctx, parent.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete parent: %v", err)
+	}
+	if _, err := service.ArchiveTask(ctx, parent.ID); !errors.Is(err, domain.ErrNotClosed) {
+		t.Errorf("archive with open subtask: %v", err)
+	}
+
+	if _, err := service.UpdateTask(ctx, child.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete child: %v", err)
+	}
+	archived, err := service.ArchiveTask(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if !archived.IsArchived() {
+		t.Errorf("task was not archived")
+	}
+	if tasks := listArchived(t, service, false); len(tasks) != 0 {
+		t.Errorf("active list: %v", tasks)
+	}
+	if tasks := listArchived(t, service, true); len(tasks) != 2 {
+		t.Errorf("archive: %v", tasks)
+	}
+
+	if _, err := service.UnarchiveTask(ctx, parent.ID); err != nil {
+		t.Fatalf("unarchive: %v", err)
+	}
+	if tasks := listArchived(t, service, false); len(tasks) != 2 {
+		t.Errorf("after unarchive: %v", tasks)
+	}
+}
+
+func TestReopenLeavesArchive(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	task := mustCreate(t, service, "H", "D")
+	if _, err := service.UpdateTask(ctx, task.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if _, err := service.ArchiveTask(ctx, task.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	reopened, err := service.TransitionTask(ctx, task.ID, domain.StatusTodo, domain.CompleteOptions{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if reopened.IsArchived() {
+		t.Errorf("reopened task stayed in archive")
+	}
+}
+
+func TestArchiveClosed(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	old := mustCreate(t, service, "Old", "D")
+	recent := mustCreate(t, service, "Recent", "D")
+	open := mustCreate(t, service, "Open", "D")
+	for _, id := range []int{old.ID, recent.ID} {
+		if _, err := service.UpdateTask(ctx, id, domain.CompleteOptions{}); err != nil {
+			t.Fatalf("complete: %v", err)
+		}
+	}
+
+	// Сдвигаем время завершения первой задачи в прошлое
+	task, _ := service.repo.Get(ctx, old.ID)
+	closedAt := time.Now().Add(-48 * time.Hour)
+	task.StatusChangedAt = &closedAt
+	if err := service.repo.Update(ctx, task); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	n, err := service.ArchiveClosed(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("archive: %d %v", n, err)
+	}
+	if tasks := listArchived(t, service, true); len(tasks) != 1 || tasks[0].ID != old.ID {
+		t.Errorf("archive: %v", tasks)
+	}
+
+	if n, err := service.ArchiveClosed(ctx, time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Errorf("second run: %d %v", n, err)
+	}
+	if got, _ := service.GetTask(ctx, open.ID); got.IsArchived() {
+		t.Errorf("open task was archived")
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/auth"
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// lastUsedPrecision — как часто обновляется время последнего
+// использования ключа, чтобы не писать в хранилище на каждый запрос.
+const lastUsedPrecision = time.Minute
+
+var errInvalidCredentials = fmt.Errorf("%w: invalid user name or password", domain.ErrUnauthorized)
+
+// TokenConfig задаёт выпуск токенов: подпись и издателя токенов доступа и
+// сроки жизни обоих токенов.
+type TokenConfig struct {
+	Signer     auth.Signer
+	Issuer     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// AuthService проверяет учётные данные: пароли, ключи API и токены, — и
+// выдаёт токены при входе по паролю.
+type AuthService struct {
+	users  repository.UserRepository
+	keys   repository.APIKeyRepository
+	tokens repository.TokenRepository
+	cfg    TokenConfig
+}
+
+func NewAuthService(users repository.UserRepository, keys repository.APIKeyRepository, tokens repository.TokenRepository, cfg TokenConfig) *AuthService {
+	return &AuthService{
+		users:  users,
+		keys:   keys,
+		tokens: tokens,
+		cfg:    cfg,
+	}
+}
+
+// Login проверяет пароль и открывает сессию: выдаёт токен доступа и токен
+// обновления нового семейства. Неизвестный пользователь, пользователь без
+// пароля и неверный пароль неотличимы ни по ответу, ни по времени.
+func (s *AuthService) Login(ctx context.Context, name string, password string) (*domain.Session, error) {
+	name, err := domain.NormalizeUserName(name)
+	if err != nil {
+		auth.WastePasswordCheck(ctx, password)
+		return nil, errInvalidCredentials
+	}
+	user, err := s.users.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && !user.HasPassword()) {
+		auth.WastePasswordCheck(ctx, password)
+		return nil, errInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := auth.VerifyPassword(ctx, user.PasswordHash, password)
+	if err != nil {
+		return nil, fmt.Errorf("password of user %d: %w", user.ID, err)
+	}
+	if !ok {
+		return nil, errInvalidCredentials
+	}
+	return s.issueSession(ctx, user, generateID(), time.Now())
+}
+
+// Refresh обменивает токен обновления на новую пару токенов. Старый токен
+// после этого недействителен; повторное его предъявление считается
+// кражей и отзывает всё семейство.
+func (s *AuthService) Refresh(ctx context.Context, secret string) (*domain.Session, error) {
+	token, err := s.tokens.GetRefreshToken(ctx, domain.HashRefreshToken(secret))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: invalid refresh token", domain.ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.UsedAt != nil {
+		return nil, s.revokeReused(ctx, token, now)
+	}
+	if !token.Valid(now) {
+		return nil, fmt.Errorf("%w: refresh token is expired or revoked", domain.ErrUnauthorized)
+	}
+	err = s.tokens.UseRefreshToken(ctx, token.ID, now)
+	if errors.Is(err, domain.ErrTokenReused) {
+		return nil, s.revokeReused(ctx, token, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.Get(ctx, token.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: invalid refresh token", domain.ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.issueSession(ctx, user, token.FamilyID, now)
+}
+
+func (s *AuthService) revokeReused(ctx context.Context, token *domain.RefreshToken, now time.Time) error {
+	if err := s.tokens.RevokeRefreshFamily(ctx, token.FamilyID, now); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %w", domain.ErrUnauthorized, domain.ErrTokenReused)
+}
+
+// Logout закрывает сессию: отзывает токен доступа, которым подписан
+// запрос, и семейство токена обновления refresh, если он передан и
+// принадлежит тому же пользователю.
+func (s *AuthService) Logout(ctx context.Context, refresh string) error {
+	identity, ok := domain.IdentityFrom(ctx)
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+
+	now := time.Now()
+	if identity.Method == domain.AuthJWT {
+		err := s.tokens.RevokeAccessToken(ctx, &domain.RevokedToken{
+			ID:        identity.TokenID,
+			UserID:    identity.User.ID,
+			ExpiresAt: identity.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if refresh == "" {
+		return nil
+	}
+	token, err := s.tokens.GetRefreshToken(ctx, domain.HashRefreshToken(refresh))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if token.UserID != identity.User.ID {
+		return nil
+	}
+	return s.tokens.RevokeRefreshFamily(ctx, token.FamilyID, now)
+}
+
+// ChangePassword задаёт пароль пользователю из контекста. Если пароль уже
+// был, нужен текущий. Все токены обновления пользователя отзываются, так
+// что другие сессии закончатся, когда истекут их токены доступа.
+func (s *AuthService) ChangePassword(ctx context.Context, current string, password string) error {
+	identity, ok := domain.IdentityFrom(ctx)
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+	if err := domain.ValidatePassword(password); err != nil {
+		return err
+	}
+
+	user, err := s.users.Get(ctx, identity.User.ID)
+	if err != nil {
+		return err
+	}
+	if user.HasPassword() {
+		ok, err := auth.VerifyPassword(ctx, user.PasswordHash, current)
+		if err != nil {
+			return fmt.Errorf("password of user %d: %w", user.ID, err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: current password is incorrect", domain.ErrUnauthorized)
+		}
+	}
+
+	hash, err := auth.HashPassword(ctx, password)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	return s.tokens.RevokeUserRefreshTokens(ctx, user.ID, time.Now())
+}
+
+// Authenticate проверяет учётные данные из заголовка Authorization: ключ
+// API или токен доступа. Для неверных, отозванных и истёкших данных
+// возвращается domain.ErrUnauthorized.
+func (s *AuthService) Authenticate(ctx context.Context, credential string) (*domain.Identity, error) {
+	if domain.IsAPIKey(credential) {
+		return s.authenticateAPIKey(ctx, credential)
+	}
+	return s.authenticateToken(ctx, credential)
+}
+
+func (s *AuthService) authenticateAPIKey(ctx context.Context, secret string) (*domain.Identity, error) {
+	key, err := s.keys.GetByHash(ctx, domain.HashAPIKey(secret))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: invalid API key", domain.ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key.Revoked() {
+		return nil, fmt.Errorf("%w: API key is revoked", domain.ErrUnauthorized)
+	}
+
+	user, err := s.users.Get(ctx, key.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("owner of API key %d: %w", key.ID, err)
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedPrecision {
+		if err := s.keys.Touch(ctx, key.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	return &domain.Identity{User: user, Method: domain.AuthAPIKey}, nil
+}
+
+func (s *AuthService) authenticateToken(ctx context.Context, token string) (*domain.Identity, error) {
+	claims, err := auth.Verify(s.cfg.Signer, token, s.cfg.Issuer, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrUnauthorized, err)
+	}
+	revoked, err := s.tokens.IsAccessTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("%w: access token is revoked", domain.ErrUnauthorized)
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid token subject", domain.ErrUnauthorized)
+	}
+	user, err := s.users.Get(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown user", domain.ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Identity{
+		User:      user,
+		Method:    domain.AuthJWT,
+		TokenID:   claims.ID,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}, nil
+}
+
+// PurgeExpired удаляет истёкшие токены обновления и записи об отозванных
+// токенах доступа и возвращает их число.
+func (s *AuthService) PurgeExpired(ctx context.Context) (int, error) {
+	return s.tokens.DeleteExpired(ctx, time.Now())
+}
+
+// issueSession выдаёт токен доступа и токен обновления семейства familyID.
+func (s *AuthService) issueSession(ctx context.Context, user *domain.User, familyID int, now time.Time) (*domain.Session, error) {
+	var refresh *domain.RefreshToken
+	var secret string
+	for {
+		var err error
+		refresh, secret, err = domain.NewRefreshToken(user.ID, familyID, now, s.cfg.RefreshTTL)
+		if err != nil {
+			return nil, err
+		}
+		refresh.ID = generateID()
+		err = s.tokens.CreateRefreshToken(ctx, refresh)
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	expiresAt := now.Add(s.cfg.AccessTTL)
+	access, err := auth.Sign(s.cfg.Signer, auth.Claims{
+		Issuer:    s.cfg.Issuer,
+		Subject:   strconv.Itoa(user.ID),
+		Name:      user.Name,
+		ID:        rand.Text(),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Session{
+		User:             user,
+		AccessToken:      access,
+		AccessExpiresAt:  expiresAt,
+		RefreshToken:     secret,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/auth"
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository/memory"
+)
+
+func newTestAuth(t *testing.T) (*UserService, *AuthService) {
+	t.Helper()
+	signer, err := auth.NewHMACSigner(bytes.Repeat([]byte("k"), auth.MinHMACSecretLength))
+	if err != nil {
+		t.Fatalf("signer: %v", err)
+	}
+	users, keys := memory.NewUserRepository(), memory.NewAPIKeyRepository()
+	authService := NewAuthService(users, keys, memory.NewTokenRepository(), TokenConfig{
+		Signer:     signer,
+		Issuer:     "todo-list",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+	return NewUserService(users, keys), authService
+}
+
+// authenticate проверяет токен доступа и возвращает контекст запроса с
+// пользователем, как это делает middleware.
+func authenticate(t *testing.T, s *AuthService, token string) context.Context {
+	t.Helper()
+	identity, err := s.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	return domain.WithIdentity(context.Background(), identity)
+}
+
+func TestLogin(t *testing.T) {
+	users, s := newTestAuth(t)
+	ctx := context.Background()
+	alice, err := users.CreateUser(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if _, err := users.CreateUser(ctx, "bob", ""); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	session, err := s.Login(ctx, " Alice ", "correct horse")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if session.User.ID != alice.ID || session.AccessToken == "" || session.RefreshToken == "" ||
+		!session.AccessExpiresAt.Before(session.RefreshExpiresAt) {
+		t.Errorf("session: %+v", session)
+	}
+
+	identity, err := s.Authenticate(ctx, session.AccessToken)
+	if err != nil || identity.User.ID != alice.ID || identity.Method != domain.AuthJWT || identity.TokenID == "" {
+		t.Fatalf("authenticate: %+v, %v", identity, err)
+	}
+
+	for _, tc := range []struct{ name, password string }{
+		{"alice", "wrong password"},
+		{"carol", "correct horse"},
+		{"bob", ""},
+		{"-", "correct horse"},
+	} {
+		if _, err := s.Login(ctx, tc.name, tc.password); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("login %q: want ErrUnauthorized, got %v", tc.name, err)
+		}
+	}
+	if _, err := s.Authenticate(ctx, session.AccessToken+"x"); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("forged token: want ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	users, s := newTestAuth(t)
+	ctx := context.Background()
+	if _, err := users.CreateUser(ctx, "alice", "correct horse"); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	first, err := s.Login(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	second, err := s.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Error("refresh returned the same tokens")
+	}
+	third, err := s.Refresh(ctx, second.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh again: %v", err)
+	}
+
+	// Повторное предъявление старого токена отзывает всё семейство
+	if _, err := s.Refresh(ctx, first.RefreshToken); !errors.Is(err, domain.ErrTokenReused) || !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("reuse: want ErrTokenReused, got %v", err)
+	}
+	if _, err := s.Refresh(ctx, third.RefreshToken); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("family revoked: want ErrUnauthorized, got %v", err)
+	}
+	if _, err := s.Refresh(ctx, "unknown"); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("unknown: want ErrUnauthorized, got %v", err)
+	}
+
+	// Другие сессии пользователя это не задевает
+	other, err := s.Login(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if _, err := s.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Errorf("other session: %v", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	users, s := newTestAuth(t)
+	ctx := context.Background()
+	if _, err := users.CreateUser(ctx, "alice", "correct horse"); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	session, err := s.Login(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	if err := s.Logout(authenticate(t, s, session.AccessToken), session.RefreshToken); err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+	if _, err := s.Authenticate(ctx, session.AccessToken); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("revoked access token: want ErrUnauthorized, got %v", err)
+	}
+	if _, err := s.Refresh(ctx, session.RefreshToken); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("revoked refresh token: want ErrUnauthorized, got %v", err)
+	}
+	if err := s.Logout(ctx, ""); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("logout without user: want ErrUnauthorized, got %v", err)
+	}
+
+	n, err := s.PurgeExpired(ctx)
+	if err != nil || n != 0 {
+		t.Errorf("purge: %d, %v", n, err)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	users, s := newTestAuth(t)
+	ctx := context.Background()
+	alice, err := users.CreateUser(ctx, "alice", "")
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	_, secret, err := users.CreateAPIKey(domain.WithUser(ctx, alice), "default")
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+
+	// Первый пароль задаётся без текущего
+	if err := s.ChangePassword(authenticate(t, s, secret), "", "correct horse"); err != nil {
+		t.Fatalf("set password: %v", err)
+	}
+	session, err := s.Login(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	userCtx := authenticate(t, s, session.AccessToken)
+	if err := s.ChangePassword(userCtx, "wrong password", "battery staple"); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("wrong current: want ErrUnauthorized, got %v", err)
+	}
+	if err := s.ChangePassword(userCtx, "correct horse", "short"); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("short password: want ErrInvalidRequest, got %v", err)
+	}
+	if err := s.ChangePassword(userCtx, "correct horse", "battery staple"); err != nil {
+		t.Fatalf("change password: %v", err)
+	}
+
+	if _, err := s.Login(ctx, "alice", "correct horse"); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("old password: want ErrUnauthorized, got %v", err)
+	}
+	if _, err := s.Login(ctx, "alice", "battery staple"); err != nil {
+		t.Errorf("new password: %v", err)
+	}
+	if _, err := s.Refresh(ctx, session.RefreshToken); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("refresh after change: want ErrUnauthorized, got %v", err)
+	}
+}
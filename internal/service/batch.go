@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// Batch выполняет операции по порядку под одной блокировкой сервиса, так
+// что между ними не вклинятся другие запросы. Каждая операция проверяется
+// и записывается в историю так же, как отдельный запрос, и её можно
+// отменить через Undo.
+//
+// Без atomic операции независимы: ошибка одной попадает в её результат и
+// не мешает остальным. С atomic пакет выполняется целиком или не
+// выполняется вовсе: при первой ошибке изменения откатываются, у упавшей
+// операции в результате её ошибка, у остальных — domain.ErrBatchAborted.
+// Хранилищам без транзакций изменения передаются только после успеха всех
+// операций, см. repository.Atomically.
+func (s *TaskService) Batch(ctx context.Context, ops []domain.BatchOp, atomic bool) ([]domain.BatchResult, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.batch(ctx, ops, atomic)
+}
+
+func (s *TaskService) batch(ctx context.Context, ops []domain.BatchOp, atomic bool) ([]domain.BatchResult, error) {
+	results := make([]domain.BatchResult, len(ops))
+	if !atomic {
+		for i, op := range ops {
+			var undo *undoOp
+			results[i], undo = s.batchOp(ctx, op)
+			s.pushUndo(ctx, undo)
+		}
+		return results, nil
+	}
+
+	var undo []*undoOp
+	failed := -1
+	repo := s.repo
+	err := repository.Atomically(ctx, repo, func(tx repository.TaskRepository) error {
+		// Операции сервиса работают с s.repo, поэтому на время пакета
+		// подменяем его транзакцией
+		s.repo = tx
+		defer func() { s.repo = repo }()
+
+		for i, op := range ops {
+			res, u := s.batchOp(ctx, op)
+			results[i] = res
+			if res.Err != nil {
+				failed = i
+				return res.Err
+			}
+			undo = append(undo, u)
+		}
+		return nil
+	})
+	if err != nil {
+		// Индексы уже содержат изменения откаченных операций
+		if rerr := s.rebuildIndexes(ctx); rerr != nil {
+			return nil, fmt.Errorf("rebuild indexes after failed batch: %w", rerr)
+		}
+		if failed < 0 {
+			return nil, err
+		}
+		for i := range results {
+			if i != failed {
+				results[i] = domain.BatchResult{
+					TaskID: ops[i].ID,
+					Err:    fmt.Errorf("%w: operation %d failed", domain.ErrBatchAborted, failed),
+				}
+			}
+		}
+		return results, nil
+	}
+
+	for _, u := range undo {
+		s.pushUndo(ctx, u)
+	}
+	return results, nil
+}
+
+// batchOp выполняет операцию пакета и возвращает её итог и запись для
+// стека отмены.
+func (s *TaskService) batchOp(ctx context.Context, op domain.BatchOp) (domain.BatchResult, *undoOp) {
+	if op.Version > 0 {
+		ctx = domain.WithIfMatch(ctx, []int{op.Version})
+	}
+
+	res := domain.BatchResult{TaskID: op.ID}
+	var undo *undoOp
+	switch op.Kind {
+	case domain.BatchCreate:
+		res.Task, undo, res.Err = s.createWithUndo(ctx, op.Input)
+		if res.Task != nil {
+			res.TaskID = res.Task.ID
+		}
+	case domain.BatchUpdate:
+		res.Task, undo, res.Err = s.patchWithUndo(ctx, op.ID, op.Patch)
+	case domain.BatchComplete:
+		res.Task, undo, res.Err = s.transitionWithUndo(ctx, op.ID, domain.StatusDone, op.Complete)
+	case domain.BatchDelete:
+		undo, res.Err = s.deleteWithUndo(ctx, op.ID, op.Delete)
+	default:
+		res.Err = fmt.Errorf("%w: unknown operation %q", domain.ErrInvalidRequest, op.Kind)
+	}
+	return res, undo
+}
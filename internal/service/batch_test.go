@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func headlinePatch(headline string) func(task *domain.Task) (domain.TaskInput, error) {
+	return func(task *domain.Task) (domain.TaskInput, error) {
+		input := task.Input()
+		input.Headline = headline
+		return input, nil
+	}
+}
+
+func TestBatchBestEffort(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	first := mustCreate(t, service, "First", "D")
+	second := mustCreate(t, service, "Second", "D")
+
+	results, err := service.Batch(ctx, []domain.BatchOp{
+		{Kind: domain.BatchCreate, Input: domain.TaskInput{Headline: "Third", Description: "D"}},
+		{Kind: domain.BatchUpdate, ID: 99999, Patch: headlinePatch("Missing")},
+		{Kind: domain.BatchComplete, ID: first.ID},
+		{Kind: domain.BatchDelete, ID: second.ID},
+	}, false)
+	if err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+
+	if results[0].Err != nil || results[0].Task == nil || results[0].TaskID != results[0].Task.ID {
+		t.Errorf("create: %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, domain.ErrNotFound) {
+		t.Errorf("update missing: want ErrNotFound, got %v", results[1].Err)
+	}
+	if results[2].Err != nil || results[2].Task.CurrentStatus() != domain.StatusDone {
+		t.Errorf("complete: %+v", results[2])
+	}
+	if results[3].Err != nil {
+		t.Errorf("delete: %v", results[3].Err)
+	}
+	if _, err := service.GetTask(ctx, second.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("deleted task: %v", err)
+	}
+}
+
+func TestBatchAtomicRollback(t *testing.T) {
+	service := newTestService()
+	ctx := domain.WithActor(context.Background(), "alice")
+	task := mustCreate(t, service, "Task", "D")
+
+	results, err := service.Batch(ctx, []domain.BatchOp{
+		{Kind: domain.BatchCreate, Input: domain.TaskInput{Headline: "New", Description: "D"}},
+		{Kind: domain.BatchUpdate, ID: task.ID, Patch: headlinePatch("Renamed")},
+		{Kind: domain.BatchDelete, ID: task.ID, Version: 1},
+	}, true)
+	if err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	if !errors.Is(results[2].Err, domain.ErrVersionMismatch) {
+		t.Errorf("failed operation: want ErrVersionMismatch, got %v", results[2].Err)
+	}
+	for _, i := range []int{0, 1} {
+		if !errors.Is(results[i].Err, domain.ErrBatchAborted) {
+			t.Errorf("operation %d: want ErrBatchAborted, got %v", i, results[i].Err)
+		}
+	}
+
+	tasks, err := service.GetAllTasks(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Headline != "Task" || tasks[0].Version != 1 {
+		t.Fatalf("batch was not rolled back: %+v", tasks)
+	}
+	if hits, _ := service.SearchTasks(ctx, "new", 10); len(hits) != 0 {
+		t.Errorf("search index kept rolled back task: %+v", hits)
+	}
+	history, _ := service.TaskHistory(ctx, task.ID)
+	if len(history) != 1 {
+		t.Errorf("history has %d revisions, want 1", len(history))
+	}
+	if _, err := service.Undo(ctx); !errors.Is(err, domain.ErrNothingToUndo) {
+		t.Errorf("undo after rollback: want ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestBatchAtomicUndo(t *testing.T) {
+	service := newTestService()
+	ctx := domain.WithActor(context.Background(), "alice")
+	task := mustCreate(t, service, "Task", "D")
+
+	results, err := service.Batch(ctx, []domain.BatchOp{
+		{Kind: domain.BatchUpdate, ID: task.ID, Version: 1, Patch: headlinePatch("Renamed")},
+		{Kind: domain.BatchComplete, ID: task.ID, Version: 2},
+	}, true)
+	if err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	for i, res := range results {
+		if res.Err != nil {
+			t.Fatalf("operation %d: %v", i, res.Err)
+		}
+	}
+
+	for _, want := range []string{undoComplete, undoUpdate} {
+		res, err := service.Undo(ctx)
+		if err != nil {
+			t.Fatalf("undo %s: %v", want, err)
+		}
+		if res.Operation != want {
+			t.Errorf("undo operation = %s, want %s", res.Operation, want)
+		}
+	}
+	got, _ := service.GetTask(ctx, task.ID)
+	if got.Headline != "Task" || got.CurrentStatus() != domain.StatusTodo {
+		t.Errorf("after undo: %q %s", got.Headline, got.CurrentStatus())
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// Bulk применяет операцию ко всем задачам, подходящим под фильтр, как пакет
+// из Batch. Поиск и изменения идут под одной блокировкой, так что набор
+// задач не меняется между ними. Подзадачи обрабатываются раньше
+// родителей: так удаление без cascade проходит, если под фильтр попало
+// всё поддерево.
+func (s *TaskService) Bulk(ctx context.Context, req domain.BulkRequest) (*domain.BulkResult, error) {
+	if req.Op.Kind == domain.BatchCreate {
+		return nil, fmt.Errorf("%w: %s is not a bulk operation", domain.ErrInvalidRequest, req.Op.Kind)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	tasks, err := s.queryTasks(ctx, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if req.Filter.Now.IsZero() {
+		req.Filter.Now = time.Now()
+	}
+	s.decorate(tasks...)
+
+	depths := make(map[int]int)
+	var ids []int
+	for _, task := range tasks {
+		if req.Filter.Match(task) {
+			ids = append(ids, task.ID)
+			depths[task.ID] = s.tree.depth(task.ID)
+		}
+	}
+	slices.SortFunc(ids, func(a, b int) int {
+		return cmp.Or(cmp.Compare(depths[b], depths[a]), cmp.Compare(a, b))
+	})
+
+	res := &domain.BulkResult{IDs: ids}
+	if req.DryRun || len(ids) == 0 {
+		return res, nil
+	}
+
+	ops := make([]domain.BatchOp, len(ids))
+	for i, id := range ids {
+		ops[i] = req.Op
+		ops[i].ID = id
+		ops[i].Version = 0
+	}
+	if res.Results, err = s.batch(ctx, ops, req.Atomic); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func tagFilter(t *testing.T, tag string) domain.TaskFilter {
+	t.Helper()
+	clause, err := domain.ParseTagClause(tag)
+	if err != nil {
+		t.Fatalf("clause %s: %v", tag, err)
+	}
+	return domain.TaskFilter{Tags: []domain.TagClause{clause}}
+}
+
+func TestBulkDryRunAndComplete(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	first := mustCreateTagged(t, service, "First", "release")
+	second := mustCreateTagged(t, service, "Second", "release")
+	mustCreateTagged(t, service, "Other", "backlog")
+
+	req := domain.BulkRequest{
+		Filter: tagFilter(t, "release"),
+		Op:     domain.BatchOp{Kind: domain.BatchComplete},
+		Atomic: true,
+		DryRun: true,
+	}
+	res, err := service.Bulk(ctx, req)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !slices.Equal(res.IDs, sortedIDs(first, second)) || res.Results != nil {
+		t.Fatalf("dry run: %+v", res)
+	}
+	if task, _ := service.GetTask(ctx, first.ID); task.Done {
+		t.Fatal("dry run changed the task")
+	}
+
+	req.DryRun = false
+	if res, err = service.Bulk(ctx, req); err != nil {
+		t.Fatalf("bulk: %v", err)
+	}
+	for i, result := range res.Results {
+		if result.Err != nil || !result.Task.Done {
+			t.Errorf("task %d: %+v", res.IDs[i], result)
+		}
+	}
+	history, _ := service.TaskHistory(ctx, first.ID)
+	if len(history) != 2 {
+		t.Errorf("history has %d revisions, want 2", len(history))
+	}
+}
+
+func TestBulkDeleteSubtreeChildrenFirst(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	root := mustCreateTagged(t, service, "Root", "cleanup")
+	parentID := root.ID
+	child, err := service.CreateTask(ctx, domain.TaskInput{Headline: "Child", Description: "D", ParentID: &parentID, Tags: []string{"cleanup"}})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+
+	res, err := service.Bulk(ctx, domain.BulkRequest{
+		Filter: tagFilter(t, "cleanup"),
+		Op:     domain.BatchOp{Kind: domain.BatchDelete},
+		Atomic: true,
+	})
+	if err != nil {
+		t.Fatalf("bulk: %v", err)
+	}
+	if !slices.Equal(res.IDs, []int{child.ID, root.ID}) {
+		t.Errorf("order = %v, want child before root", res.IDs)
+	}
+	for _, result := range res.Results {
+		if result.Err != nil {
+			t.Errorf("task %d: %v", result.TaskID, result.Err)
+		}
+	}
+	if _, err := service.GetTask(ctx, root.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("root was not deleted: %v", err)
+	}
+}
+
+func TestBulkRejectsCreate(t *testing.T) {
+	service := newTestService()
+	_, err := service.Bulk(context.Background(), domain.BulkRequest{Op: domain.BatchOp{Kind: domain.BatchCreate}})
+	if !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("want ErrInvalidRequest, got %v", err)
+	}
+}
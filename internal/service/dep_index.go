@@ -0,0 +1,101 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// depIndex — граф зависимостей: для каждой задачи хранятся задачи, которые
+// её блокируют, и задачи, которые блокирует она сама.
+// Внутренней синхронизации нет, доступ защищает мьютекс сервиса.
+type depIndex struct {
+	blockers   map[int][]int
+	dependents map[int]map[int]struct{}
+}
+
+func newDepIndex() *depIndex {
+	return &depIndex{
+		blockers:   make(map[int][]int),
+		dependents: make(map[int]map[int]struct{}),
+	}
+}
+
+func (idx *depIndex) set(task *domain.Task) {
+	idx.unlink(task.ID)
+	if len(task.BlockedBy) == 0 {
+		return
+	}
+
+	idx.blockers[task.ID] = slices.Clone(task.BlockedBy)
+	for _, blocker := range task.BlockedBy {
+		ids, ok := idx.dependents[blocker]
+		if !ok {
+			ids = make(map[int]struct{})
+			idx.dependents[blocker] = ids
+		}
+		ids[task.ID] = struct{}{}
+	}
+}
+
+// remove убирает исходящие связи задачи. Входящие связи (задачи, которые
+// она блокирует) остаются, пока их не обновят.
+func (idx *depIndex) remove(id int) {
+	idx.unlink(id)
+}
+
+func (idx *depIndex) unlink(id int) {
+	for _, blocker := range idx.blockers[id] {
+		delete(idx.dependents[blocker], id)
+		if len(idx.dependents[blocker]) == 0 {
+			delete(idx.dependents, blocker)
+		}
+	}
+	delete(idx.blockers, id)
+}
+
+// dependentIDs возвращает задачи, которые блокирует id, в порядке возрастания.
+func (idx *depIndex) dependentIDs(id int) []int {
+	ids := make([]int, 0, len(idx.dependents[id]))
+	for dependent := range idx.dependents[id] {
+		ids = append(ids, dependent)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// findCycle проверяет, замкнёт ли цикл зависимость id от blockers, и
+// возвращает путь цикла от id обратно к id. Путь ищется обходом в глубину
+// по рёбрам «заблокирована задачей»: цикл есть, если из какого-то блокера
+// можно дойти до самой задачи.
+func (idx *depIndex) findCycle(id int, blockers []int) []int {
+	visited := make(map[int]bool)
+	var path []int
+
+	var visit func(node int) bool
+	visit = func(node int) bool {
+		if node == id {
+			return true
+		}
+		if visited[node] {
+			return false
+		}
+		visited[node] = true
+
+		path = append(path, node)
+		for _, next := range idx.blockers[node] {
+			if visit(next) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	for _, blocker := range blockers {
+		if visit(blocker) {
+			return append(append([]int{id}, path...), id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// planOrder — порядок среди задач, которые можно брать в работу одновременно:
+// сначала важные, затем с ближайшим сроком.
+var planOrder = []domain.SortKey{
+	{Field: domain.SortByPriority, Desc: true},
+	{Field: domain.SortByDueAt},
+	{Field: domain.SortByCreatedAt},
+}
+
+// TopologicalOrder возвращает невыполненные задачи в порядке, в котором их
+// можно выполнять: каждая задача идёт после всех своих блокирующих задач.
+// Среди доступных одновременно задач первыми идут более важные.
+func (s *TaskService) TopologicalOrder(ctx context.Context) ([]*domain.Task, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	tasks, err := listActive(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	open := make(map[int]*domain.Task)
+	for _, task := range tasks {
+		if !task.CurrentStatus().IsClosed() {
+			open[task.ID] = task
+		}
+	}
+
+	// Алгоритм Кана: считаем невыполненные блокеры каждой задачи и берём
+	// задачи, у которых их не осталось
+	pending := make(map[int]int, len(open))
+	ready := &taskHeap{}
+	for id, task := range open {
+		for _, blocker := range task.BlockedBy {
+			if _, ok := open[blocker]; ok {
+				pending[id]++
+			}
+		}
+		if pending[id] == 0 {
+			heap.Push(ready, task)
+		}
+	}
+
+	order := make([]*domain.Task, 0, len(open))
+	for ready.Len() > 0 {
+		task := heap.Pop(ready).(*domain.Task)
+		order = append(order, task)
+		delete(open, task.ID)
+
+		for _, dependent := range s.deps.dependentIDs(task.ID) {
+			if _, ok := open[dependent]; !ok {
+				continue
+			}
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				heap.Push(ready, open[dependent])
+			}
+		}
+	}
+
+	// Циклов быть не должно: они отсекаются при добавлении зависимостей.
+	// Если данные всё же повреждены, оставшиеся задачи идут в конце
+	rest := &taskHeap{}
+	for _, task := range open {
+		heap.Push(rest, task)
+	}
+	for rest.Len() > 0 {
+		order = append(order, heap.Pop(rest).(*domain.Task))
+	}
+
+	s.decorate(order...)
+	return order, nil
+}
+
+type taskHeap []*domain.Task
+
+func (h taskHeap) Len() int { return len(h) }
+func (h taskHeap) Less(i, j int) bool {
+	return domain.CompareTasks(h[i], h[j], planOrder) < 0
+}
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *taskHeap) Push(x any)   { *h = append(*h, x.(*domain.Task)) }
+func (h *taskHeap) Pop() any {
+	old := *h
+	n := len(old)
+	task := old[n-1]
+	*h = old[:n-1]
+	return task
+}
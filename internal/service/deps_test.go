@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func mustCreateBlocked(t *testing.T, service *TaskService, headline string, priority domain.Priority, blockers ...int) *domain.Task {
+	t.Helper()
+	task, err := service.CreateTask(context.Background(), domain.TaskInput{Headline: headline, Description: "D", Priority: priority, BlockedBy: blockers})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return task
+}
+
+func TestDependencyCycleDetection(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	a := mustCreate(t, service, "A", "D")
+	b := mustCreateBlocked(t, service, "B", "", a.ID)
+	c := mustCreateBlocked(t, service, "C", "", b.ID)
+
+	for _, blocker := range []int{c.ID, b.ID, a.ID} {
+		_, err := service.UpdateContent(ctx, a.ID, domain.TaskInput{Headline: "A", Description: "D", BlockedBy: []int{blocker}})
+		if !errors.Is(err, domain.ErrDependencyCycle) {
+			t.Errorf("blocked by %d: want ErrDependencyCycle, got %v", blocker, err)
+		}
+	}
+
+	missing := 99999
+	if _, err := service.CreateTask(ctx, domain.TaskInput{Headline: "H", Description: "D", BlockedBy: []int{missing}}); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("missing blocker: %v", err)
+	}
+
+	// Ромб без цикла допустим
+	if _, err := service.UpdateContent(ctx, c.ID, domain.TaskInput{Headline: "C", Description: "D", BlockedBy: []int{a.ID, b.ID}}); err != nil {
+		t.Errorf("diamond: %v", err)
+	}
+}
+
+func TestBlockedStateAndCompletion(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	blocker := mustCreate(t, service, "Blocker", "D")
+	task := mustCreateBlocked(t, service, "Task", "", blocker.ID)
+
+	got, _ := service.GetTask(ctx, task.ID)
+	if !got.IsBlocked() || !slices.Equal(got.OpenBlockers, []int{blocker.ID}) {
+		t.Errorf("blocked: %v %v", got.IsBlocked(), got.OpenBlockers)
+	}
+
+	blocked := true
+	page, _ := service.ListTasks(ctx, domain.TaskQuery{Filter: domain.TaskFilter{Blocked: &blocked}})
+	if len(page.Tasks) != 1 || page.Tasks[0].ID != task.ID {
+		t.Errorf("blocked filter: %v", page.Tasks)
+	}
+
+	if _, err := service.UpdateTask(ctx, task.ID, domain.CompleteOptions{}); !errors.Is(err, domain.ErrBlocked) {
+		t.Fatalf("want ErrBlocked, got %v", err)
+	}
+	got, _ = service.GetTask(ctx, task.ID)
+	if got.Done {
+		t.Error("blocked task completed")
+	}
+
+	if _, err := service.UpdateTask(ctx, blocker.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete blocker: %v", err)
+	}
+	got, _ = service.GetTask(ctx, task.ID)
+	if got.IsBlocked() {
+		t.Error("still blocked after blocker completed")
+	}
+	if _, err := service.UpdateTask(ctx, task.ID, domain.CompleteOptions{}); err != nil {
+		t.Errorf("complete: %v", err)
+	}
+}
+
+func TestForceCompleteBlockedTask(t *testing.T) {
+	service := newTestService()
+	blocker := mustCreate(t, service, "Blocker", "D")
+	task := mustCreateBlocked(t, service, "Task", "", blocker.ID)
+
+	got, err := service.UpdateTask(context.Background(), task.ID, domain.CompleteOptions{Force: true})
+	if err != nil {
+		t.Fatalf("force: %v", err)
+	}
+	if !got.Done || !slices.Equal(got.OpenBlockers, []int{blocker.ID}) {
+		t.Errorf("force result: %+v", got)
+	}
+}
+
+func TestCascadeCompleteIgnoresBlockersInsideSubtree(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	root := mustCreate(t, service, "Root", "D")
+	first := mustCreateChild(t, service, "First", root.ID)
+	second, err := service.CreateTask(ctx, domain.TaskInput{Headline: "Second", Description: "D", ParentID: &root.ID, BlockedBy: []int{first.ID}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := service.UpdateTask(ctx, root.ID, domain.CompleteOptions{Cascade: true}); err != nil {
+		t.Fatalf("cascade: %v", err)
+	}
+	got, _ := service.GetTask(ctx, second.ID)
+	if !got.Done {
+		t.Error("second not completed")
+	}
+}
+
+func TestDeleteBlockerUnblocksDependents(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	blocker := mustCreate(t, service, "Blocker", "D")
+	other := mustCreate(t, service, "Other", "D")
+	task := mustCreateBlocked(t, service, "Task", "", blocker.ID, other.ID)
+
+	if err := service.DeleteTask(ctx, blocker.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	// Задача в корзине не блокирует, но связь остаётся до очистки корзины
+	got, _ := service.GetTask(ctx, task.ID)
+	if !slices.Equal(got.BlockedBy, domain.NormalizeBlockers([]int{blocker.ID, other.ID})) || !slices.Equal(got.OpenBlockers, []int{other.ID}) {
+		t.Errorf("after delete: blocked_by %v, open %v", got.BlockedBy, got.OpenBlockers)
+	}
+
+	// blocked_by можно отправить обратно как есть, но новую связь с
+	// задачей в корзине не создать
+	input := got.Input()
+	input.Headline = "Renamed"
+	if _, err := service.UpdateContent(ctx, task.ID, input); err != nil {
+		t.Errorf("update with trashed blocker: %v", err)
+	}
+	if _, err := service.CreateTask(ctx, domain.TaskInput{Headline: "New", Description: "D", BlockedBy: []int{blocker.ID}}); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("new dependency on trashed task: want ErrInvalidRequest, got %v", err)
+	}
+
+	if err := service.PurgeTask(ctx, blocker.ID); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	got, _ = service.GetTask(ctx, task.ID)
+	if !slices.Equal(got.BlockedBy, []int{other.ID}) {
+		t.Errorf("after purge: blocked_by %v", got.BlockedBy)
+	}
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	design := mustCreateBlocked(t, service, "Design", domain.PriorityLow)
+	backend := mustCreateBlocked(t, service, "Backend", domain.PriorityNormal, design.ID)
+	frontend := mustCreateBlocked(t, service, "Frontend", domain.PriorityHigh, design.ID)
+	release := mustCreateBlocked(t, service, "Release", domain.PriorityUrgent, backend.ID, frontend.ID)
+	hotfix := mustCreateBlocked(t, service, "Hotfix", domain.PriorityUrgent)
+	done := mustCreate(t, service, "Done", "D")
+	if _, err := service.UpdateTask(ctx, done.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	order, err := service.TopologicalOrder(ctx)
+	if err != nil {
+		t.Fatalf("order: %v", err)
+	}
+	want := []int{hotfix.ID, design.ID, frontend.ID, backend.ID, release.ID}
+	got := make([]int, 0, len(order))
+	for _, task := range order {
+		got = append(got, task.ID)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("order: %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// insertTask сохраняет новую задачу и её первую ревизию.
+func insertTask(ctx context.Context, tx repository.TaskRepository, task *domain.Task) error {
+	task.Version = 1
+	return recordTask(ctx, tx, domain.EventTaskCreated, nil, task, 0)
+}
+
+// saveTask сохраняет изменённую задачу со следующим номером версии как
+// событие typ и записывает ревизию с отличиями от сохранённой версии.
+func saveTask(ctx context.Context, tx repository.TaskRepository, typ domain.EventType, task *domain.Task) error {
+	before, err := tx.Get(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	task.Version = before.Version + 1
+	return recordTask(ctx, tx, typ, before, task, 0)
+}
+
+// recordTask сохраняет задачу событием typ вместе с ревизией от имени
+// автора из ctx. Изменение без отличий в отслеживаемых полях в историю не
+// попадает.
+func recordTask(ctx context.Context, tx repository.TaskRepository, typ domain.EventType, before *domain.Task, after *domain.Task, revertOf int) error {
+	changes := domain.Diff(before, after)
+	if before != nil && len(changes) == 0 {
+		return repository.Record(ctx, tx, typ, after, nil)
+	}
+
+	snapshot := after.Clone()
+	snapshot.Subtasks = nil
+	snapshot.OpenBlockers = nil
+	snapshot.NextOccurrenceID = 0
+	return repository.Record(ctx, tx, typ, after, &domain.Revision{
+		TaskID:   after.ID,
+		Actor:    domain.ActorFrom(ctx),
+		Action:   typ.Action(),
+		RevertOf: revertOf,
+		At:       time.Now(),
+		Changes:  changes,
+		Snapshot: snapshot,
+	})
+}
+
+// TaskHistory возвращает ревизии задачи по возрастанию номера. История
+// доступна и для задачи в корзине.
+func (s *TaskService) TaskHistory(ctx context.Context, id int) ([]*domain.Revision, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	task, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !task.VisibleTo(ctx) {
+		return nil, domain.ErrNotFound
+	}
+	return s.repo.ListRevisions(ctx, id)
+}
+
+// TaskAtRevision возвращает задачу в том виде, в каком она была сразу после
+// ревизии number.
+func (s *TaskService) TaskAtRevision(ctx context.Context, id int, number int) (*domain.Revision, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	task, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !task.VisibleTo(ctx) {
+		return nil, domain.ErrNotFound
+	}
+	return s.revision(ctx, id, number)
+}
+
+func (s *TaskService) revision(ctx context.Context, id int, number int) (*domain.Revision, error) {
+	revisions, err := s.repo.ListRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, rev := range revisions {
+		if rev.Number == number {
+			return rev, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// RevertTask возвращает содержимое задачи к ревизии number: заголовок,
+// описание, приоритет, срок, теги, родителя, зависимости и правило
+// повторения. Статус, архив и корзина не откатываются — для них есть свои
+// действия. Откат проверяется так же, как обычное изменение, и сам
+// записывается новой ревизией.
+func (s *TaskService) RevertTask(ctx context.Context, id int, number int) (*domain.Task, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	task, err := getActive(ctx, s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := s.revision(ctx, id, number)
+	if err != nil {
+		return nil, err
+	}
+
+	input := rev.Snapshot.Input()
+	if err := s.checkParent(ctx, id, input.ParentID); err != nil {
+		return nil, fmt.Errorf("revert to revision %d: %w", number, err)
+	}
+	if err := s.checkBlockers(ctx, id, domain.NormalizeBlockers(input.BlockedBy), task.BlockedBy); err != nil {
+		return nil, fmt.Errorf("revert to revision %d: %w", number, err)
+	}
+	if err := checkRecurrence(input); err != nil {
+		return nil, fmt.Errorf("revert to revision %d: %w", number, err)
+	}
+
+	before := task.Clone()
+	task.Apply(input)
+	task.Version++
+	err = repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+		return recordTask(ctx, tx, domain.EventTaskReverted, before, task, number)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.indexTask(task)
+	s.decorate(task)
+	return task, nil
+}
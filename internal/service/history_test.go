@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository/file"
+)
+
+func TestTaskHistoryRecordsMutations(t *testing.T) {
+	service := newTestService()
+	ctx := domain.WithActor(context.Background(), "alice")
+
+	task, err := service.CreateTask(ctx, domain.TaskInput{Headline: "Draft", Description: "D"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: "Final", Description: "D"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	// Изменение без отличий в историю не попадает
+	if _, err := service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: "Final", Description: "D"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := service.UpdateTask(context.Background(), task.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	history, err := service.TaskHistory(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	actions := []string{domain.ActionCreate, domain.ActionUpdate, domain.ActionTransition}
+	if len(history) != len(actions) {
+		t.Fatalf("len != %d: %+v", len(actions), history)
+	}
+	for i, rev := range history {
+		if rev.Number != i+1 || rev.Action != actions[i] {
+			t.Errorf("revision %d: %d %s", i, rev.Number, rev.Action)
+		}
+	}
+	if history[1].Actor != "alice" || history[2].Actor != domain.AnonymousActor {
+		t.Errorf("actors: %s %s", history[1].Actor, history[2].Actor)
+	}
+	changes := history[1].Changes
+	if len(changes) != 1 || changes[0].Field != "headline" || string(changes[0].From) != `"Draft"` || string(changes[0].To) != `"Final"` {
+		t.Errorf("changes: %+v", changes)
+	}
+
+	if err := service.DeleteTask(ctx, task.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	history, err = service.TaskHistory(ctx, task.ID)
+	if err != nil || history[len(history)-1].Action != domain.ActionDelete {
+		t.Errorf("history of trashed task: %v", err)
+	}
+	if _, err := service.TaskHistory(ctx, 42); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("missing task: %v", err)
+	}
+}
+
+func TestTaskAtRevisionAndRevert(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	blocker := mustCreate(t, service, "Blocker", "D")
+	task, err := service.CreateTask(ctx, domain.TaskInput{Headline: "v1", Description: "D", BlockedBy: []int{blocker.ID}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: "v2", Description: "D", Priority: domain.PriorityHigh}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	rev, err := service.TaskAtRevision(ctx, task.ID, 1)
+	if err != nil {
+		t.Fatalf("revision: %v", err)
+	}
+	if rev.Snapshot.Headline != "v1" {
+		t.Errorf("snapshot: %+v", rev.Snapshot)
+	}
+	if _, err := service.TaskAtRevision(ctx, task.ID, 9); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("missing revision: %v", err)
+	}
+
+	reverted, err := service.RevertTask(ctx, task.ID, 1)
+	if err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if reverted.Headline != "v1" || reverted.Priority != domain.PriorityNormal || len(reverted.BlockedBy) != 1 {
+		t.Errorf("reverted: %+v", reverted)
+	}
+	history, _ := service.TaskHistory(ctx, task.ID)
+	last := history[len(history)-1]
+	if last.Action != domain.ActionRevert || last.RevertOf != 1 {
+		t.Errorf("last revision: %+v", last)
+	}
+
+	// Блокирующей задачи больше нет: откат к ревизии с ней не проходит проверку
+	if _, err := service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: "v3", Description: "D"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := service.DeleteTask(ctx, blocker.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete blocker: %v", err)
+	}
+	if _, err := service.RevertTask(ctx, task.ID, 1); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("revert to missing blocker: %v", err)
+	}
+}
+
+func TestEventLogRecordsServiceActions(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := file.OpenEventRepository(dir, file.Options{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+	service, err := NewTaskService(ctx, repo)
+	if err != nil {
+		t.Fatalf("service: %v", err)
+	}
+
+	task := mustCreate(t, service, "v1", "D")
+	if _, err := service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: "v2", Description: "D"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := service.UpdateTask(ctx, task.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if _, err := service.ArchiveTask(ctx, task.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if _, err := service.TransitionTask(ctx, task.ID, domain.StatusTodo, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if _, err := service.RevertTask(ctx, task.ID, 1); err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+
+	var events []*domain.Event
+	err = file.ReadEvents(dir, func(e *domain.Event) error {
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read events: %v", err)
+	}
+	want := []domain.EventType{
+		domain.EventTaskCreated, domain.EventTaskContentUpdated, domain.EventTaskCompleted,
+		domain.EventTaskArchived, domain.EventTaskStatusChanged, domain.EventTaskReverted,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events: %+v", events)
+	}
+	for i, e := range events {
+		// Ревизия записывается тем же событием, что и изменение задачи
+		if e.Type != want[i] || e.Revision == nil || e.Revision.Action != e.Type.Action() {
+			t.Errorf("event %d: %s, revision %+v", e.Seq, e.Type, e.Revision)
+		}
+	}
+	if c := events[4].Change; c.Status == nil || c.Archived == nil || c.Content != nil {
+		t.Errorf("reopen change: %+v", c)
+	}
+	if events[5].Revision.RevertOf != 1 {
+		t.Errorf("revert revision: %+v", events[5].Revision)
+	}
+}
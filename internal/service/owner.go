@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// ClaimTasks назначает пользователю ownerID все задачи без владельца,
+// включая задачи в корзине, и возвращает их число. Нужен, чтобы задачи,
+// созданные до появления учётных записей, остались доступны, когда
+// запросы без пользователя отклоняются.
+func (s *TaskService) ClaimTasks(ctx context.Context, ownerID int) (int, error) {
+	if ownerID <= 0 {
+		return 0, fmt.Errorf("%w: owner must be a user", domain.ErrInvalidRequest)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	tasks, err := s.repo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var claimed []*domain.Task
+	var ids []int
+	err = repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+		for _, task := range tasks {
+			if task.OwnerID != 0 {
+				continue
+			}
+			task.OwnerID = ownerID
+			if err := saveTask(ctx, tx, domain.EventTaskClaimed, task); err != nil {
+				return err
+			}
+			claimed = append(claimed, task)
+			ids = append(ids, task.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		s.reindex(ctx, ids)
+		return 0, err
+	}
+
+	for _, task := range claimed {
+		s.indexTask(task)
+	}
+	return len(claimed), nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func TestClaimTasks(t *testing.T) {
+	service := newTestService()
+	anonymous := context.Background()
+	alice := domain.WithUser(context.Background(), &domain.User{ID: 1, Name: "alice"})
+	bob := domain.WithUser(context.Background(), &domain.User{ID: 2, Name: "bob"})
+
+	parent := mustCreate(t, service, "Parent", "D")
+	child, err := service.CreateTask(anonymous, domain.TaskInput{Headline: "Child", Description: "D", ParentID: &parent.ID})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	trashed := mustCreate(t, service, "Trashed", "D")
+	if err := service.DeleteTask(anonymous, trashed.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	owned, err := service.CreateTask(bob, domain.TaskInput{Headline: "Bob's", Description: "D"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := service.ClaimTasks(alice, 0); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("claim without owner: %v", err)
+	}
+	n, err := service.ClaimTasks(domain.WithAllUsers(anonymous), 1)
+	if err != nil || n != 3 {
+		t.Fatalf("claim: %d, %v", n, err)
+	}
+
+	if _, err := service.GetTask(anonymous, parent.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("claimed task visible without user: %v", err)
+	}
+	tree, err := service.GetSubtree(alice, parent.ID)
+	if err != nil || len(tree.Children) != 1 || tree.Children[0].Task.ID != child.ID {
+		t.Errorf("subtree: %+v, %v", tree, err)
+	}
+	if trash, _ := service.ListTrash(alice); len(trash) != 1 || trash[0].ID != trashed.ID {
+		t.Errorf("trash: %+v", trash)
+	}
+	if task, err := service.GetTask(bob, owned.ID); err != nil || task.OwnerID != 2 {
+		t.Errorf("other owner's task: %+v, %v", task, err)
+	}
+
+	history, err := service.TaskHistory(alice, parent.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	last := history[len(history)-1]
+	if last.Action != domain.ActionClaim || len(last.Changes) != 1 || last.Changes[0].Field != "owner_id" {
+		t.Errorf("claim revision: %+v", last)
+	}
+
+	if n, err := service.ClaimTasks(domain.WithAllUsers(anonymous), 1); err != nil || n != 0 {
+		t.Errorf("second claim: %d, %v", n, err)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"sort"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// cursor хранит значения ключей сортировки последней задачи страницы.
+// Следующая страница начинается с первой задачи строго после неё, поэтому
+// вставки и удаления между запросами не приводят к пропускам и повторам.
+type cursor struct {
+	Sort  string       `json:"sort"`
+	Pivot *domain.Task `json:"pivot"`
+}
+
+func encodeCursor(keys []domain.SortKey, last *domain.Task) string {
+	pivot := last.Clone()
+	pivot.Description = ""
+	if !slices.ContainsFunc(keys, func(k domain.SortKey) bool { return k.Field == domain.SortByHeadline }) {
+		pivot.Headline = ""
+	}
+
+	b, err := json.Marshal(cursor{Sort: domain.FormatSort(keys), Pivot: pivot})
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string, keys []domain.SortKey) (*domain.Task, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Pivot == nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	// Курсор привязан к порядку сортировки, с другим порядком он бессмыслен
+	if c.Sort != domain.FormatSort(keys) {
+		return nil, domain.ErrInvalidCursor
+	}
+	return c.Pivot, nil
+}
+
+func paginate(tasks []*domain.Task, query domain.TaskQuery) (*domain.TaskPage, error) {
+	keys := query.Sort
+	if len(keys) == 0 {
+		keys = domain.DefaultSort
+	}
+
+	matched := make([]*domain.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if query.Filter.Match(task) {
+			matched = append(matched, task)
+		}
+	}
+	slices.SortFunc(matched, func(a, b *domain.Task) int {
+		return domain.CompareTasks(a, b, keys)
+	})
+
+	start := 0
+	if query.Cursor != "" {
+		pivot, err := decodeCursor(query.Cursor, keys)
+		if err != nil {
+			return nil, err
+		}
+		start = sort.Search(len(matched), func(i int) bool {
+			return domain.CompareTasks(matched[i], pivot, keys) > 0
+		})
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+	end := min(start+limit, len(matched))
+
+	page := &domain.TaskPage{
+		Tasks: matched[start:end],
+		Total: len(matched),
+		Limit: limit,
+	}
+	if end < len(matched) {
+		page.NextCursor = encodeCursor(keys, matched[end-1])
+	}
+	return page, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func TestListTasksPaginatesWithCursor(t *testing.T) {
+	service := newTestService()
+	for i := 0; i < 7; i++ {
+		mustCreate(t, service, "Task", "Description")
+	}
+
+	seen := make(map[int]bool)
+	query := domain.TaskQuery{Limit: 3}
+	pages := 0
+	for {
+		page, err := service.ListTasks(context.Background(), query)
+		if err != nil {
+			t.Fatalf("error: %v", err)
+		}
+		pages++
+		if page.Total != 7 {
+			t.Errorf("total != 7: %d", page.Total)
+		}
+		for _, task := range page.Tasks {
+			if seen[task.ID] {
+				t.Errorf("duplicate: %d", task.ID)
+			}
+			seen[task.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	if pages != 3 {
+		t.Errorf("pages != 3: %d", pages)
+	}
+	if len(seen) != 7 {
+		t.Errorf("len != 7: %d", len(seen))
+	}
+}
+
+func TestListTasksSortAndFilter(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+
+	b := mustCreate(t, service, "b task", "Description")
+	a := mustCreate(t, service, "A task", "Description")
+	mustCreate(t, service, "other", "Description")
+	if _, err := service.UpdateTask(ctx, b.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	page, err := service.ListTasks(ctx, domain.TaskQuery{
+		Filter: domain.TaskFilter{HeadlineContains: "TASK"},
+		Sort:   []domain.SortKey{{Field: domain.SortByHeadline, Desc: true}},
+	})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(page.Tasks) != 2 {
+		t.Fatalf("len != 2: %d", len(page.Tasks))
+	}
+	if page.Tasks[0].ID != b.ID || page.Tasks[1].ID != a.ID {
+		t.Errorf("order: %d, %d", page.Tasks[0].ID, page.Tasks[1].ID)
+	}
+
+	done := true
+	page, err = service.ListTasks(ctx, domain.TaskQuery{Filter: domain.TaskFilter{Done: &done}})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(page.Tasks) != 1 || page.Tasks[0].ID != b.ID {
+		t.Errorf("done filter: %v", page.Tasks)
+	}
+
+	future := time.Now().Add(time.Hour)
+	page, err = service.ListTasks(ctx, domain.TaskQuery{Filter: domain.TaskFilter{CreatedAfter: &future}})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(page.Tasks) != 0 {
+		t.Errorf("created_after filter: %d", len(page.Tasks))
+	}
+}
+
+func TestListTasksCursorSortMismatch(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		mustCreate(t, service, "Task", "Description")
+	}
+
+	page, err := service.ListTasks(ctx, domain.TaskQuery{Limit: 1})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	_, err = service.ListTasks(ctx, domain.TaskQuery{
+		Limit:  1,
+		Cursor: page.NextCursor,
+		Sort:   []domain.SortKey{{Field: domain.SortByHeadline}},
+	})
+	if err != domain.ErrInvalidCursor {
+		t.Errorf("want ErrInvalidCursor, got %v", err)
+	}
+
+	_, err = service.ListTasks(ctx, domain.TaskQuery{Cursor: "not a cursor"})
+	if err != domain.ErrInvalidCursor {
+		t.Errorf("want ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestListTasksCursorSurvivesDeletion(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		mustCreate(t, service, "Task", "Description")
+	}
+
+	query := domain.TaskQuery{Limit: 2, Sort: []domain.SortKey{{Field: domain.SortByID}}}
+	first, err := service.ListTasks(ctx, query)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	// Удаляем последнюю задачу первой страницы: курсор не должен сломаться
+	if err := service.DeleteTask(ctx, first.Tasks[1].ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	query.Cursor = first.NextCursor
+	second, err := service.ListTasks(ctx, query)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(second.Tasks) != 2 {
+		t.Fatalf("len != 2: %d", len(second.Tasks))
+	}
+	if second.Tasks[0].ID <= first.Tasks[1].ID {
+		t.Errorf("not after cursor: %d", second.Tasks[0].ID)
+	}
+}
+
+func TestListTasksTriageOrder(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	now := time.Now()
+
+	create := func(headline string, priority domain.Priority, due time.Duration) *domain.Task {
+		dueAt := now.Add(due)
+		task, err := service.CreateTask(ctx, domain.TaskInput{Headline: headline, Description: "D", Priority: priority, DueAt: &dueAt})
+		if err != nil {
+			t.Fatalf("error: %v", err)
+		}
+		return task
+	}
+	urgentLater := create("urgent later", domain.PriorityUrgent, 48*time.Hour)
+	urgentSoon := create("urgent soon", domain.PriorityUrgent, time.Hour)
+	overdueLow := create("overdue low", domain.PriorityLow, -time.Hour)
+	noDue, _ := service.CreateTask(ctx, domain.TaskInput{Headline: "no due", Description: "D", Priority: domain.PriorityHigh})
+
+	sort, _ := domain.ParseSort("-priority,due_at")
+	page, err := service.ListTasks(ctx, domain.TaskQuery{Sort: sort})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	want := []int{urgentSoon.ID, urgentLater.ID, noDue.ID, overdueLow.ID}
+	for i, task := range page.Tasks {
+		if task.ID != want[i] {
+			t.Errorf("position %d: %s", i, task.Headline)
+		}
+	}
+
+	overdue := true
+	page, _ = service.ListTasks(ctx, domain.TaskQuery{Filter: domain.TaskFilter{Overdue: &overdue}})
+	if len(page.Tasks) != 1 || page.Tasks[0].ID != overdueLow.ID {
+		t.Errorf("overdue filter: %v", page.Tasks)
+	}
+
+	dueBefore := now.Add(2 * time.Hour)
+	page, _ = service.ListTasks(ctx, domain.TaskQuery{Filter: domain.TaskFilter{DueBefore: &dueBefore}})
+	if len(page.Tasks) != 2 {
+		t.Errorf("due_before filter: %d", len(page.Tasks))
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/recurrence"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// checkRecurrence проверяет правило повторения: оно должно разбираться и
+// требует срока, от которого считаются повторения.
+func checkRecurrence(input domain.TaskInput) error {
+	if input.Recurrence == "" {
+		return nil
+	}
+	if _, err := recurrence.Parse(input.Recurrence); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidRequest, err)
+	}
+	if input.DueAt == nil {
+		return fmt.Errorf("%w: recurrence requires due_at", domain.ErrInvalidRequest)
+	}
+	return nil
+}
+
+// spawnNextOccurrence создаёт следующее повторение только что завершённой
+// задачи. Срок считается от срока завершённой задачи, а не от момента
+// завершения, поэтому пропущенные повторения тоже появятся. Возвращает nil,
+// если задача не повторяется или серия закончилась.
+func (s *TaskService) spawnNextOccurrence(ctx context.Context, tx repository.TaskRepository, task *domain.Task, pending []*domain.Task) (*domain.Task, error) {
+	if task.Recurrence == "" || task.DueAt == nil {
+		return nil, nil
+	}
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("task %d: %w", task.ID, err)
+	}
+
+	dueAt, ok := rule.Next(task.DueAt.In(task.DueLocation()), task.Occurrence)
+	if !ok {
+		return nil, nil
+	}
+
+	next := task.NextOccurrence(s.unusedID(pending), dueAt)
+	if err := insertTask(ctx, tx, next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// unusedID подбирает ID, которого нет ни в хранилище, ни среди задач,
+// созданных в текущей транзакции. Внутри транзакции нельзя полагаться на
+// повтор после ErrAlreadyExists: PostgreSQL после ошибки отменяет транзакцию.
+func (s *TaskService) unusedID(pending []*domain.Task) int {
+	for {
+		id := generateID()
+		if s.tree.exists(id) || slices.ContainsFunc(pending, func(t *domain.Task) bool { return t.ID == id }) {
+			continue
+		}
+		return id
+	}
+}
+
+// PreviewOccurrences возвращает до n следующих повторений задачи в её
+// часовом поясе.
+func (s *TaskService) PreviewOccurrences(ctx context.Context, id int, n int) ([]domain.Occurrence, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	task, err := getActive(ctx, s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Recurrence == "" || task.DueAt == nil {
+		return nil, fmt.Errorf("%w: task %d is not recurring", domain.ErrInvalidRequest, id)
+	}
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("task %d: %w", id, err)
+	}
+
+	dates := rule.Preview(task.DueAt.In(task.DueLocation()), task.Occurrence, n)
+	occurrences := make([]domain.Occurrence, 0, len(dates))
+	for i, dueAt := range dates {
+		occurrences = append(occurrences, domain.Occurrence{Number: task.Occurrence + i + 1, DueAt: dueAt})
+	}
+	return occurrences, nil
+}
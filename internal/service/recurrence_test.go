@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func mustCreateRecurring(t *testing.T, service *TaskService, rule string, dueAt time.Time) *domain.Task {
+	t.Helper()
+	task, err := service.CreateTask(context.Background(), domain.TaskInput{
+		Headline: "Standup", Description: "D", DueAt: &dueAt, DueTimezone: dueAt.Location().String(),
+		Tags: []string{"work"}, Recurrence: rule,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return task
+}
+
+func TestCompleteSpawnsNextOccurrence(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	loc, _ := time.LoadLocation("Europe/Moscow")
+	first := mustCreateRecurring(t, service, "FREQ=WEEKLY;BYDAY=MO,WE", time.Date(2025, 3, 3, 9, 0, 0, 0, loc))
+	if first.Occurrence != 1 || first.SeriesID != first.ID {
+		t.Fatalf("series: %d %d", first.Occurrence, first.SeriesID)
+	}
+
+	done, err := service.UpdateTask(ctx, first.ID, domain.CompleteOptions{})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if done.NextOccurrenceID == 0 {
+		t.Fatal("next occurrence was not created")
+	}
+
+	next, err := service.GetTask(ctx, done.NextOccurrenceID)
+	if err != nil {
+		t.Fatalf("get next: %v", err)
+	}
+	want := time.Date(2025, 3, 5, 9, 0, 0, 0, loc)
+	if !next.DueAt.Equal(want) || next.Done || next.Occurrence != 2 || next.SeriesID != first.ID {
+		t.Errorf("next: due=%v done=%v occurrence=%d series=%d", next.DueAt, next.Done, next.Occurrence, next.SeriesID)
+	}
+	if !next.HasTag("work") || next.Recurrence != first.Recurrence {
+		t.Errorf("next did not inherit fields: %+v", next)
+	}
+
+	// Повторное завершение уже выполненной задачи новую задачу не создаёт
+	again, err := service.UpdateTask(ctx, first.ID, domain.CompleteOptions{})
+	if err != nil {
+		t.Fatalf("complete again: %v", err)
+	}
+	if again.NextOccurrenceID != 0 || len(mustList(t, service)) != 2 {
+		t.Errorf("completing twice spawned another occurrence")
+	}
+}
+
+func TestRecurrenceCountEndsSeries(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	task := mustCreateRecurring(t, service, "FREQ=DAILY;COUNT=2", time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	done, err := service.UpdateTask(ctx, task.ID, domain.CompleteOptions{})
+	if err != nil || done.NextOccurrenceID == 0 {
+		t.Fatalf("first: %v %d", err, done.NextOccurrenceID)
+	}
+	last, err := service.UpdateTask(ctx, done.NextOccurrenceID, domain.CompleteOptions{})
+	if err != nil {
+		t.Fatalf("second: %v", err)
+	}
+	if last.NextOccurrenceID != 0 {
+		t.Errorf("series should end after COUNT occurrences")
+	}
+}
+
+func TestPreviewOccurrences(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	task := mustCreateRecurring(t, service, "FREQ=MONTHLY;BYMONTHDAY=31", time.Date(2025, 1, 31, 18, 0, 0, 0, time.UTC))
+
+	occurrences, err := service.PreviewOccurrences(ctx, task.ID, 3)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	want := []time.Time{
+		time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC),
+		time.Date(2025, 5, 31, 18, 0, 0, 0, time.UTC),
+		time.Date(2025, 7, 31, 18, 0, 0, 0, time.UTC),
+	}
+	if len(occurrences) != len(want) {
+		t.Fatalf("got %d occurrences", len(occurrences))
+	}
+	for i, o := range occurrences {
+		if !o.DueAt.Equal(want[i]) || o.Number != i+2 {
+			t.Errorf("occurrence %d: %d %v", i, o.Number, o.DueAt)
+		}
+	}
+
+	plain := mustCreate(t, service, "H", "D")
+	if _, err := service.PreviewOccurrences(ctx, plain.ID, 3); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("non-recurring task: %v", err)
+	}
+}
+
+func TestRecurrenceValidation(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	due := time.Now().Add(time.Hour)
+	inputs := []domain.TaskInput{
+		{Headline: "H", Description: "D", Recurrence: "FREQ=DAILY"},
+		{Headline: "H", Description: "D", DueAt: &due, Recurrence: "FREQ=HOURLY"},
+	}
+	for _, input := range inputs {
+		if _, err := service.CreateTask(ctx, input); !errors.Is(err, domain.ErrInvalidRequest) {
+			t.Errorf("%q: want ErrInvalidRequest, got %v", input.Recurrence, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository/memory"
+)
+
+func TestSearchTasksFollowsMutations(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+
+	task := mustCreate(t, service, "Купить молоко", "В магазине у дома")
+	mustCreate(t, service, "Позвонить маме", "Вечером")
+
+	results, err := service.SearchTasks(ctx, "молоко", 10)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(results) != 1 || results[0].Task.ID != task.ID {
+		t.Fatalf("results: %v", results)
+	}
+
+	if _, err := service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: "Купить хлеб", Description: "В пекарне"}); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if results, _ := service.SearchTasks(ctx, "молоко", 10); len(results) != 0 {
+		t.Errorf("stale index after update: %v", results)
+	}
+	if results, _ := service.SearchTasks(ctx, "пекарн*", 10); len(results) != 1 {
+		t.Errorf("update not indexed: %v", results)
+	}
+
+	if err := service.DeleteTask(ctx, task.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if results, _ := service.SearchTasks(ctx, "хлеб", 10); len(results) != 0 {
+		t.Errorf("stale index after delete: %v", results)
+	}
+}
+
+func TestSearchTasksIndexesExistingTasks(t *testing.T) {
+	repo := memory.NewTaskRepository()
+	ctx := context.Background()
+	if err := repo.Create(ctx, domain.NewTask(1, "Ночной деплой", "Бэкенд")); err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	service, err := NewTaskService(ctx, repo)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	results, err := service.SearchTasks(ctx, `"ночной деплой"`, 10)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("len != 1: %d", len(results))
+	}
+}
+
+func TestSearchTasksLimitAndInvalidQuery(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		mustCreate(t, service, "Отчёт", "Description")
+	}
+
+	results, err := service.SearchTasks(ctx, "отчет", 3)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("len != 3: %d", len(results))
+	}
+
+	if _, err := service.SearchTasks(ctx, "  ", 3); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("want ErrInvalidRequest, got %v", err)
+	}
+}
+
+func TestSearchIndexUnchangedOnStorageError(t *testing.T) {
+	service, err := NewTaskService(context.Background(), &failingRepository{TaskRepository: memory.NewTaskRepository()})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	task := mustCreate(t, service, "Молоко", "Description")
+
+	if err := service.DeleteTask(context.Background(), task.ID, domain.DeleteOptions{}); !errors.Is(err, errStorage) {
+		t.Fatalf("want errStorage, got %v", err)
+	}
+	if results, _ := service.SearchTasks(context.Background(), "молоко", 10); len(results) != 1 {
+		t.Errorf("index changed: %v", results)
+	}
+}
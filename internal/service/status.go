@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// TransitionTask переводит задачу в статус to. Переход в текущий статус
+// ничего не меняет, запрещённый переход возвращает domain.ErrInvalidTransition.
+//
+// С opts.Cascade задача выполняется или отменяется вместе со всеми
+// подзадачами в одной транзакции, если хранилище их поддерживает; уже
+// закрытые подзадачи сохраняют свой статус. Если у выполняемых задач
+// остались незакрытые блокирующие задачи, возвращается domain.ErrBlocked;
+// с opts.Force задача выполняется, а блокирующие задачи остаются в
+// OpenBlockers результата. Закрытие повторяющейся задачи создаёт её
+// следующее повторение. Если версия задачи не та, что ожидает клиент (см.
+// domain.WithIfMatch), возвращается domain.ErrVersionMismatch.
+func (s *TaskService) TransitionTask(ctx context.Context, id int, to domain.Status, opts domain.CompleteOptions) (*domain.Task, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	task, op, err := s.transitionWithUndo(ctx, id, to, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.pushUndo(ctx, op)
+	return task, nil
+}
+
+// transitionWithUndo проверяет версию задачи, меняет статус и возвращает
+// запись для стека отмены. Отменить можно только выполнение задачи.
+func (s *TaskService) transitionWithUndo(ctx context.Context, id int, to domain.Status, opts domain.CompleteOptions) (*domain.Task, *undoOp, error) {
+	if err := s.checkVersion(ctx, id); err != nil {
+		return nil, nil, err
+	}
+	res, err := s.transition(ctx, id, to, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if to != domain.StatusDone || len(res.before) == 0 {
+		return res.task, nil, nil
+	}
+	op := &undoOp{kind: undoComplete, taskID: id, statuses: res.before, completeOpts: opts, spawned: res.spawned}
+	ids := slices.Clone(res.spawned)
+	for _, task := range res.before {
+		ids = append(ids, task.ID)
+	}
+	return res.task, s.undoEntry(ctx, op, ids...), nil
+}
+
+// transitionResult — итог перехода: задача id, задачи, чей статус
+// действительно изменился, в состоянии до перехода, и созданные повторения.
+type transitionResult struct {
+	task    *domain.Task
+	before  []*domain.Task
+	spawned []int
+}
+
+func (s *TaskService) transition(ctx context.Context, id int, to domain.Status, opts domain.CompleteOptions) (*transitionResult, error) {
+	ids := []int{id}
+	if opts.Cascade {
+		if !to.IsClosed() {
+			return nil, fmt.Errorf("%w: cascade is supported only for %s and %s", domain.ErrInvalidRequest, domain.StatusDone, domain.StatusCancelled)
+		}
+		ids = append(ids, s.tree.descendants(id)...)
+	}
+
+	if to == domain.StatusDone && !opts.Force {
+		completing := make(map[int]bool, len(ids))
+		for _, id := range ids {
+			completing[id] = true
+		}
+		var open []int
+		for _, id := range ids {
+			open = append(open, s.openBlockers(s.deps.blockers[id], completing)...)
+		}
+		if len(open) > 0 {
+			open = domain.NormalizeBlockers(open)
+			return nil, fmt.Errorf("%w: %s", domain.ErrBlocked, formatIDs(open))
+		}
+	}
+
+	now := time.Now()
+	updated := make([]*domain.Task, 0, len(ids))
+	var before []*domain.Task
+	var spawned []*domain.Task
+	err := repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+		for i, id := range ids {
+			task, err := getActive(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			updated = append(updated, task)
+
+			from := task.CurrentStatus()
+			// Уже закрытые подзадачи сохраняют свой статус и время перехода
+			if from == to || (i > 0 && from.IsClosed()) {
+				continue
+			}
+			before = append(before, task.Clone())
+			if err := task.Transition(to, now); err != nil {
+				return fmt.Errorf("task %d: %w", id, err)
+			}
+			if err := saveTask(ctx, tx, domain.StatusEvent(to), task); err != nil {
+				return err
+			}
+
+			if from.IsClosed() || !to.IsClosed() {
+				continue
+			}
+			next, err := s.spawnNextOccurrence(ctx, tx, task, spawned)
+			if err != nil {
+				return err
+			}
+			if next != nil {
+				task.NextOccurrenceID = next.ID
+				spawned = append(spawned, next)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if len(ids) > 1 || len(spawned) > 0 {
+			for _, next := range spawned {
+				ids = append(ids, next.ID)
+			}
+			s.reindex(ctx, ids)
+		}
+		return nil, err
+	}
+
+	for _, task := range updated {
+		s.tree.set(task)
+	}
+	res := &transitionResult{task: updated[0], before: before}
+	for _, next := range spawned {
+		s.indexTask(next)
+		res.spawned = append(res.spawned, next.ID)
+	}
+	s.decorate(res.task)
+	return res, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func TestTransitionWorkflow(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	task := mustCreate(t, service, "H", "D")
+
+	started, err := service.TransitionTask(ctx, task.ID, domain.StatusInProgress, domain.CompleteOptions{})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if started.Status != domain.StatusInProgress || started.StartedAt == nil || started.Done {
+		t.Errorf("started: %+v", started)
+	}
+
+	done, err := service.TransitionTask(ctx, task.ID, domain.StatusDone, domain.CompleteOptions{})
+	if err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if !done.Done || done.CompletedAt == nil || !done.StartedAt.Equal(*started.StartedAt) {
+		t.Errorf("done: %+v", done)
+	}
+
+	// Переоткрытая задача снова не выполнена
+	reopened, err := service.TransitionTask(ctx, task.ID, domain.StatusTodo, domain.CompleteOptions{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if reopened.Done || reopened.CompletedAt != nil || reopened.CurrentStatus() != domain.StatusTodo {
+		t.Errorf("reopened: %+v", reopened)
+	}
+
+	if _, err := service.TransitionTask(ctx, task.ID, domain.StatusCancelled, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	for _, to := range []domain.Status{domain.StatusDone, domain.StatusInProgress, domain.StatusBlocked} {
+		if _, err := service.TransitionTask(ctx, task.ID, to, domain.CompleteOptions{}); !errors.Is(err, domain.ErrInvalidTransition) {
+			t.Errorf("cancelled → %s: want ErrInvalidTransition, got %v", to, err)
+		}
+	}
+	got, _ := service.GetTask(ctx, task.ID)
+	if got.CurrentStatus() != domain.StatusCancelled || got.CancelledAt == nil {
+		t.Errorf("failed transition changed the task: %+v", got)
+	}
+}
+
+func TestTransitionCascadeCancel(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	parent := mustCreate(t, service, "Parent", "D")
+	open := mustCreateChild(t, service, "Open", parent.ID)
+	finished := mustCreateChild(t, service, "Finished", parent.ID)
+	if _, err := service.UpdateTask(ctx, finished.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	if _, err := service.TransitionTask(ctx, parent.ID, domain.StatusInProgress, domain.CompleteOptions{Cascade: true}); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("cascade start: %v", err)
+	}
+	if _, err := service.TransitionTask(ctx, parent.ID, domain.StatusCancelled, domain.CompleteOptions{Cascade: true}); err != nil {
+		t.Fatalf("cascade cancel: %v", err)
+	}
+
+	if got, _ := service.GetTask(ctx, open.ID); got.CurrentStatus() != domain.StatusCancelled {
+		t.Errorf("open subtask: %s", got.CurrentStatus())
+	}
+	if got, _ := service.GetTask(ctx, finished.ID); got.CurrentStatus() != domain.StatusDone {
+		t.Errorf("finished subtask: %s", got.CurrentStatus())
+	}
+}
+
+func TestCancelledBlockerDoesNotBlock(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	blocker := mustCreate(t, service, "Blocker", "D")
+	task := mustCreateBlocked(t, service, "Task", "", blocker.ID)
+
+	if _, err := service.TransitionTask(ctx, blocker.ID, domain.StatusCancelled, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	got, _ := service.GetTask(ctx, task.ID)
+	if got.IsBlocked() {
+		t.Errorf("task is blocked by a cancelled task")
+	}
+	if _, err := service.UpdateTask(ctx, task.ID, domain.CompleteOptions{}); err != nil {
+		t.Errorf("complete: %v", err)
+	}
+}
+
+func TestStatusFilterAndLegacyTasks(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	a := mustCreate(t, service, "A", "D")
+	mustCreate(t, service, "B", "D")
+	if _, err := service.TransitionTask(ctx, a.ID, domain.StatusInProgress, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	page, _ := service.ListTasks(ctx, domain.TaskQuery{Filter: domain.TaskFilter{Statuses: []domain.Status{domain.StatusInProgress}}})
+	if len(page.Tasks) != 1 || page.Tasks[0].ID != a.ID {
+		t.Errorf("status filter: %v", page.Tasks)
+	}
+
+	// Задачи, сохранённые до появления статусов, получают статус из Done
+	legacy := &domain.Task{ID: 1, Headline: "Old", Done: true, CreatedAt: time.Now()}
+	if legacy.CurrentStatus() != domain.StatusDone {
+		t.Errorf("legacy done task: %s", legacy.CurrentStatus())
+	}
+}
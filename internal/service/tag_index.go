@@ -0,0 +1,122 @@
+package service
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// tagIndex — вторичный индекс тег -> задачи. С ним запросы по тегам и
+// переименование тегов не перебирают все задачи хранилища.
+// Внутренней синхронизации нет, доступ защищает мьютекс сервиса.
+type tagIndex struct {
+	tasks  map[string]map[int]struct{}
+	byTask map[int][]string
+}
+
+func newTagIndex() *tagIndex {
+	return &tagIndex{
+		tasks:  make(map[string]map[int]struct{}),
+		byTask: make(map[int][]string),
+	}
+}
+
+// set заменяет теги задачи в индексе.
+func (idx *tagIndex) set(id int, tags []string) {
+	idx.remove(id)
+	if len(tags) == 0 {
+		return
+	}
+
+	idx.byTask[id] = slices.Clone(tags)
+	for _, tag := range tags {
+		ids, ok := idx.tasks[tag]
+		if !ok {
+			ids = make(map[int]struct{})
+			idx.tasks[tag] = ids
+		}
+		ids[id] = struct{}{}
+	}
+}
+
+func (idx *tagIndex) remove(id int) {
+	for _, tag := range idx.byTask[id] {
+		delete(idx.tasks[tag], id)
+		if len(idx.tasks[tag]) == 0 {
+			delete(idx.tasks, tag)
+		}
+	}
+	delete(idx.byTask, id)
+}
+
+// ids возвращает задачи с тегом в порядке возрастания ID.
+func (idx *tagIndex) ids(tag string) []int {
+	ids := make([]int, 0, len(idx.tasks[tag]))
+	for id := range idx.tasks[tag] {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// candidates сужает выборку по положительным условиям запроса: задача
+// должна иметь хотя бы один тег из каждого такого условия. Если положительных
+// условий нет, ok == false и проверять придётся все задачи.
+func (idx *tagIndex) candidates(clauses []domain.TagClause) (ids []int, ok bool) {
+	var result map[int]struct{}
+	for _, clause := range clauses {
+		if clause.Negate {
+			continue
+		}
+
+		matched := make(map[int]struct{})
+		for _, tag := range clause.AnyOf {
+			for id := range idx.tasks[tag] {
+				if result == nil {
+					matched[id] = struct{}{}
+				} else if _, ok := result[id]; ok {
+					matched[id] = struct{}{}
+				}
+			}
+		}
+		result = matched
+		if len(result) == 0 {
+			break
+		}
+	}
+	if result == nil {
+		return nil, false
+	}
+
+	ids = make([]int, 0, len(result))
+	for id := range result {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, true
+}
+
+// counts считает задачи, для которых keep вернул true, и возвращает теги
+// по убыванию их числа, при равенстве — по имени.
+func (idx *tagIndex) counts(keep func(id int) bool) []domain.TagCount {
+	counts := make([]domain.TagCount, 0, len(idx.tasks))
+	for tag, ids := range idx.tasks {
+		n := 0
+		for id := range ids {
+			if keep(id) {
+				n++
+			}
+		}
+		if n > 0 {
+			counts = append(counts, domain.TagCount{Name: tag, Count: n})
+		}
+	}
+	slices.SortFunc(counts, func(a, b domain.TagCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return counts
+}
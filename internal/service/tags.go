@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// ListTags возвращает теги, используемые в доступных из ctx задачах, с
+// числом задач.
+func (s *TaskService) ListTags(ctx context.Context) ([]domain.TagCount, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.tags.counts(func(id int) bool { return s.owns(ctx, id) }), nil
+}
+
+// RenameTag переименовывает тег во всех доступных из ctx задачах. Если тег to уже
+// используется, теги сливаются. Возвращает число изменённых задач.
+func (s *TaskService) RenameTag(ctx context.Context, from string, to string) (int, error) {
+	return s.MergeTags(ctx, []string{from}, to)
+}
+
+// MergeTags заменяет теги sources на target во всех доступных из ctx задачах. Изменения
+// применяются в одной транзакции, если хранилище их поддерживает.
+// Возвращает domain.ErrNotFound, если ни один из тегов sources не используется.
+func (s *TaskService) MergeTags(ctx context.Context, sources []string, target string) (int, error) {
+	target, err := domain.NormalizeTag(target)
+	if err != nil {
+		return 0, err
+	}
+	sources, err = domain.NormalizeTags(sources)
+	if err != nil {
+		return 0, err
+	}
+	sources = slices.DeleteFunc(sources, func(tag string) bool { return tag == target })
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var ids []int
+	for _, tag := range sources {
+		for _, id := range s.tags.ids(tag) {
+			if s.owns(ctx, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return 0, domain.ErrNotFound
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	updated := make([]*domain.Task, 0, len(ids))
+	err = repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+		for _, id := range ids {
+			task, err := tx.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			tags := slices.DeleteFunc(task.Tags, func(tag string) bool {
+				_, found := slices.BinarySearch(sources, tag)
+				return found
+			})
+			task.Tags, err = domain.NormalizeTags(append(tags, target))
+			if err != nil {
+				return err
+			}
+			if err := saveTask(ctx, tx, domain.EventTaskContentUpdated, task); err != nil {
+				return err
+			}
+			updated = append(updated, task)
+		}
+		return nil
+	})
+	if err != nil {
+		s.reindex(ctx, ids)
+		return 0, err
+	}
+
+	for _, task := range updated {
+		s.tags.set(task.ID, task.Tags)
+	}
+	return len(updated), nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository/memory"
+)
+
+func mustCreateTagged(t *testing.T, service *TaskService, headline string, tags ...string) *domain.Task {
+	t.Helper()
+	task, err := service.CreateTask(context.Background(), domain.TaskInput{Headline: headline, Description: "D", Tags: tags})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return task
+}
+
+func listIDs(t *testing.T, service *TaskService, clauses ...string) []int {
+	t.Helper()
+	var filter domain.TaskFilter
+	for _, c := range clauses {
+		clause, err := domain.ParseTagClause(c)
+		if err != nil {
+			t.Fatalf("clause %s: %v", c, err)
+		}
+		filter.Tags = append(filter.Tags, clause)
+	}
+
+	page, err := service.ListTasks(context.Background(), domain.TaskQuery{Filter: filter, Sort: []domain.SortKey{{Field: domain.SortByID}}})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	ids := make([]int, 0, len(page.Tasks))
+	for _, task := range page.Tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func sortedIDs(tasks ...*domain.Task) []int {
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestListTasksByTags(t *testing.T) {
+	service := newTestService()
+	api := mustCreateTagged(t, service, "API", "backend")
+	blocked := mustCreateTagged(t, service, "Migration", "backend", "blocked")
+	ui := mustCreateTagged(t, service, "UI", "frontend")
+	untagged := mustCreateTagged(t, service, "Untagged")
+
+	if got := listIDs(t, service, "backend"); !slices.Equal(got, sortedIDs(api, blocked)) {
+		t.Errorf("backend: %v", got)
+	}
+	if got := listIDs(t, service, "backend", "-blocked"); !slices.Equal(got, sortedIDs(api)) {
+		t.Errorf("backend -blocked: %v", got)
+	}
+	if got := listIDs(t, service, "backend,frontend"); !slices.Equal(got, sortedIDs(api, blocked, ui)) {
+		t.Errorf("backend,frontend: %v", got)
+	}
+	if got := listIDs(t, service, "-backend"); !slices.Equal(got, sortedIDs(ui, untagged)) {
+		t.Errorf("-backend: %v", got)
+	}
+	if got := listIDs(t, service, "backend", "frontend"); len(got) != 0 {
+		t.Errorf("backend frontend: %v", got)
+	}
+}
+
+func TestTagIndexFollowsMutations(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	task := mustCreateTagged(t, service, "Task", "backend")
+
+	_, err := service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: "Task", Description: "D", Tags: []string{"frontend"}})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := listIDs(t, service, "backend"); len(got) != 0 {
+		t.Errorf("stale backend: %v", got)
+	}
+	if got := listIDs(t, service, "frontend"); !slices.Equal(got, []int{task.ID}) {
+		t.Errorf("frontend: %v", got)
+	}
+
+	if err := service.DeleteTask(ctx, task.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	tags, _ := service.ListTags(ctx)
+	if len(tags) != 0 {
+		t.Errorf("tags after delete: %v", tags)
+	}
+}
+
+func TestTagIndexBuiltOnStartup(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewTaskRepository()
+	task := domain.NewTask(1, "Task", "D")
+	task.Tags = []string{"backend"}
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	service, err := NewTaskService(ctx, repo)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if got := listIDs(t, service, "backend"); !slices.Equal(got, []int{1}) {
+		t.Errorf("backend: %v", got)
+	}
+}
+
+func TestListTags(t *testing.T) {
+	service := newTestService()
+	mustCreateTagged(t, service, "1", "backend", "urgent")
+	mustCreateTagged(t, service, "2", "backend")
+	mustCreateTagged(t, service, "3", "api")
+
+	tags, err := service.ListTags(context.Background())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	want := []domain.TagCount{{Name: "backend", Count: 2}, {Name: "api", Count: 1}, {Name: "urgent", Count: 1}}
+	if !slices.Equal(tags, want) {
+		t.Errorf("tags: %v", tags)
+	}
+}
+
+func TestRenameAndMergeTags(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	a := mustCreateTagged(t, service, "A", "back-end")
+	b := mustCreateTagged(t, service, "B", "be", "backend")
+	c := mustCreateTagged(t, service, "C", "server")
+
+	updated, err := service.RenameTag(ctx, "back-end", "Backend")
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("renamed %d tasks", updated)
+	}
+
+	updated, err = service.MergeTags(ctx, []string{"be", "server"}, "backend")
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("merged %d tasks", updated)
+	}
+
+	tags, _ := service.ListTags(ctx)
+	if !slices.Equal(tags, []domain.TagCount{{Name: "backend", Count: 3}}) {
+		t.Errorf("tags: %v", tags)
+	}
+	got, _ := service.GetTask(ctx, b.ID)
+	if !slices.Equal(got.Tags, []string{"backend"}) {
+		t.Errorf("merged task tags: %v", got.Tags)
+	}
+	if ids := listIDs(t, service, "backend"); !slices.Equal(ids, sortedIDs(a, b, c)) {
+		t.Errorf("backend: %v", ids)
+	}
+
+	if _, err := service.RenameTag(ctx, "missing", "other"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("missing: want ErrNotFound, got %v", err)
+	}
+	if _, err := service.RenameTag(ctx, "backend", "-bad"); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("invalid: want ErrInvalidRequest, got %v", err)
+	}
+}
+
+func TestMergeTagsStorageError(t *testing.T) {
+	service, err := NewTaskService(context.Background(), &failingRepository{TaskRepository: memory.NewTaskRepository()})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	task := mustCreateTagged(t, service, "Task", "old")
+
+	if _, err := service.RenameTag(context.Background(), "old", "new"); !errors.Is(err, errStorage) {
+		t.Fatalf("want errStorage, got %v", err)
+	}
+	if got := listIDs(t, service, "old"); !slices.Equal(got, []int{task.ID}) {
+		t.Errorf("index changed: %v", got)
+	}
+}
@@ -1,26 +1,188 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+	"github.com/S1FFFkA/todo-list/internal/search"
 )
 
 type TaskService struct {
-	tasks map[int]*domain.Task
+	repo  repository.TaskRepository
+	index *search.Index
+	tags  *tagIndex
+	tree  *treeIndex
+	deps  *depIndex
+	undo  *undoStacks
 	mtx   sync.RWMutex
 }
 
-func NewTaskService() *TaskService {
-	return &TaskService{
-		tasks: make(map[int]*domain.Task),
+type Option func(s *TaskService)
+
+// WithUndoDepth задаёт, сколько последних операций каждого клиента можно
+// отменить; 0 отключает отмену.
+func WithUndoDepth(depth int) Option {
+	return func(s *TaskService) {
+		s.undo.depth = depth
 	}
 }
 
-func (s *TaskService) generateID() int {
+// NewTaskService строит поисковый индекс, индекс тегов, дерево подзадач и
+// граф зависимостей по задачам, уже лежащим в хранилище.
+func NewTaskService(ctx context.Context, repo repository.TaskRepository, opts ...Option) (*TaskService, error) {
+	s := &TaskService{
+		repo: repo,
+		undo: newUndoStacks(defaultUndoDepth),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.rebuildIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("load tasks: %w", err)
+	}
+	return s, nil
+}
+
+// rebuildIndexes строит все индексы заново по задачам из хранилища.
+func (s *TaskService) rebuildIndexes(ctx context.Context) error {
+	tasks, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.index = search.NewIndex()
+	s.tags = newTagIndex()
+	s.tree = newTreeIndex()
+	s.deps = newDepIndex()
+	for _, task := range tasks {
+		s.indexTask(task)
+	}
+	return nil
+}
+
+// indexTask добавляет задачу в индексы. Задачи из корзины в индексы не
+// попадают, поэтому для остальных методов их не существует.
+func (s *TaskService) indexTask(task *domain.Task) {
+	if task.IsDeleted() {
+		s.unindexTask(task.ID)
+		return
+	}
+	s.index.Add(task.ID, task.Headline, task.Description)
+	s.tags.set(task.ID, task.Tags)
+	s.tree.set(task)
+	s.deps.set(task)
+}
+
+func (s *TaskService) unindexTask(id int) {
+	s.index.Remove(id)
+	s.tags.remove(id)
+	s.tree.remove(id)
+	s.deps.remove(id)
+}
+
+// reindex перечитывает задачи из хранилища и обновляет индексы. Нужен после
+// неудачного изменения нескольких задач: хранилище без транзакций могло
+// сохранить часть изменений.
+func (s *TaskService) reindex(ctx context.Context, ids []int) {
+	for _, id := range ids {
+		task, err := s.repo.Get(ctx, id)
+		if errors.Is(err, domain.ErrNotFound) {
+			s.unindexTask(id)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		s.indexTask(task)
+	}
+}
+
+// decorate заполняет вычисляемые поля задач.
+func (s *TaskService) decorate(tasks ...*domain.Task) {
+	for _, task := range tasks {
+		task.Subtasks = s.tree.progress(task.ID)
+		task.OpenBlockers = s.openBlockers(task.BlockedBy, nil)
+	}
+}
+
+// openBlockers возвращает незакрытые задачи из blockers, кроме тех, что
+// есть в except. Отменённая блокирующая задача больше не блокирует.
+func (s *TaskService) openBlockers(blockers []int, except map[int]bool) []int {
+	var open []int
+	for _, id := range blockers {
+		if s.tree.exists(id) && !s.tree.isClosed(id) && !except[id] {
+			open = append(open, id)
+		}
+	}
+	return open
+}
+
+// checkBlockers проверяет, что задача id может зависеть от blockers: все они
+// существуют и зависимость не замыкает цикл. Для новой задачи id равен 0.
+// Блокеры из current — те, от которых задача уже зависит, — могут лежать
+// в корзине: клиент вправе отправить blocked_by обратно без изменений.
+func (s *TaskService) checkBlockers(ctx context.Context, id int, blockers []int, current []int) error {
+	for _, blocker := range blockers {
+		if blocker == id {
+			return fmt.Errorf("%w: task %d cannot block itself", domain.ErrDependencyCycle, id)
+		}
+		if !s.owns(ctx, blocker) && !slices.Contains(current, blocker) {
+			return fmt.Errorf("%w: blocker task %d not found", domain.ErrInvalidRequest, blocker)
+		}
+	}
+	if id == 0 {
+		return nil
+	}
+	if cycle := s.deps.findCycle(id, blockers); cycle != nil {
+		return fmt.Errorf("%w: %s", domain.ErrDependencyCycle, formatPath(cycle))
+	}
+	return nil
+}
+
+func formatPath(ids []int) string {
+	return joinIDs(ids, " → ")
+}
+
+func formatIDs(ids []int) string {
+	return joinIDs(ids, ", ")
+}
+
+func joinIDs(ids []int, sep string) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, sep)
+}
+
+// checkParent проверяет, что задачу id можно поместить под parentID:
+// родитель существует и не является самой задачей или её подзадачей.
+// Для новой задачи id равен 0.
+func (s *TaskService) checkParent(ctx context.Context, id int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+	if !s.owns(ctx, *parentID) {
+		return fmt.Errorf("%w: parent task %d not found", domain.ErrInvalidRequest, *parentID)
+	}
+	if id != 0 && s.tree.isAncestor(id, *parentID) {
+		return fmt.Errorf("%w: task %d cannot be moved under its own subtask %d", domain.ErrCycle, id, *parentID)
+	}
+	return nil
+}
+
+func generateID() int {
 	for {
 		var b [8]byte
 		if _, err := rand.Read(b[:]); err != nil {
@@ -31,88 +193,356 @@ func (s *TaskService) generateID() int {
 			id = -id
 		}
 		// Генерируем 8-значное число от 1 до 99999999 (от 00000001 до 99999999)
-		id = 1 + id%99999999
-		if _, exists := s.tasks[id]; !exists {
-			return id
-		}
+		return 1 + id%99999999
 	}
 }
 
-func (s *TaskService) CreateTask(headline string, description string) *domain.Task {
+func (s *TaskService) CreateTask(ctx context.Context, input domain.TaskInput) (*domain.Task, error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
-	id := s.generateID()
-	task := domain.NewTask(id, headline, description)
+	task, op, err := s.createWithUndo(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	s.pushUndo(ctx, op)
+	return task, nil
+}
 
-	s.tasks[id] = task
-	return task
+// createWithUndo создаёт задачу и возвращает запись для стека отмены.
+func (s *TaskService) createWithUndo(ctx context.Context, input domain.TaskInput) (*domain.Task, *undoOp, error) {
+	task, err := s.createTask(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return task, s.undoEntry(ctx, &undoOp{kind: undoCreate, taskID: task.ID}, task.ID), nil
 }
 
-func (s *TaskService) GetAllTasks() []*domain.Task {
-	s.mtx.RLock()
-	defer s.mtx.RUnlock()
+func (s *TaskService) createTask(ctx context.Context, input domain.TaskInput) (*domain.Task, error) {
+	if err := s.checkParent(ctx, 0, input.ParentID); err != nil {
+		return nil, err
+	}
+	if err := s.checkBlockers(ctx, 0, input.BlockedBy, nil); err != nil {
+		return nil, err
+	}
+	if err := checkRecurrence(input); err != nil {
+		return nil, err
+	}
 
-	tasks := make([]*domain.Task, 0, len(s.tasks))
-	for _, task := range s.tasks {
-		tasks = append(tasks, task)
+	for {
+		task := domain.NewTask(generateID(), input.Headline, input.Description)
+		task.OwnerID = domain.UserID(ctx)
+		task.Apply(input)
+
+		// При совпадении ID хранилище вернёт ErrAlreadyExists, пробуем другой
+		err := repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+			return insertTask(ctx, tx, task)
+		})
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s.indexTask(task)
+		return task, nil
 	}
+}
 
-	return tasks
+func (s *TaskService) GetAllTasks(ctx context.Context) ([]*domain.Task, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return listActive(ctx, s.repo)
 }
 
-func (s *TaskService) GetTask(id int) (*domain.Task, error) {
+func (s *TaskService) ListTasks(ctx context.Context, query domain.TaskQuery) (*domain.TaskPage, error) {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
 
-	task, ok := s.tasks[id]
+	tasks, err := s.queryTasks(ctx, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Filter.Now.IsZero() {
+		query.Filter.Now = time.Now()
+	}
+	// Вычисляемые поля нужны фильтру, поэтому заполняем их до пагинации
+	s.decorate(tasks...)
+	return paginate(tasks, query)
+}
+
+// queryTasks возвращает задачи, среди которых стоит искать подходящие под
+// фильтр. Если в фильтре есть теги, кандидаты берутся из индекса тегов.
+func (s *TaskService) queryTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	ids, ok := s.tags.candidates(filter.Tags)
 	if !ok {
-		return nil, domain.ErrNotFound
+		return listActive(ctx, s.repo)
 	}
+	return s.getTasks(ctx, ids)
+}
 
+// getActive читает задачу, которая не лежит в корзине и доступна из ctx.
+func getActive(ctx context.Context, repo repository.TaskRepository, id int) (*domain.Task, error) {
+	task, err := repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.IsDeleted() || !task.VisibleTo(ctx) {
+		return nil, domain.ErrNotFound
+	}
 	return task, nil
 }
 
-func (s *TaskService) UpdateTask(id int) (*domain.Task, error) {
-	s.mtx.Lock()
-	defer s.mtx.Unlock()
-
-	task, ok := s.tasks[id]
+// checkVersion сверяет версию задачи id с версиями, которые клиент ожидает
+// по domain.WithIfMatch. Без ожидаемых версий проверка не нужна.
+func (s *TaskService) checkVersion(ctx context.Context, id int) error {
+	versions, ok := domain.IfMatchFrom(ctx)
 	if !ok {
-		return nil, domain.ErrNotFound
+		return nil
+	}
+	task, err := getActive(ctx, s.repo, id)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(versions, task.Version) {
+		return fmt.Errorf("%w: task %d is at version %d", domain.ErrVersionMismatch, id, task.Version)
+	}
+	return nil
+}
+
+// listActive возвращает доступные из ctx задачи, кроме лежащих в корзине.
+func listActive(ctx context.Context, repo repository.TaskRepository) ([]*domain.Task, error) {
+	tasks, err := repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(tasks, func(task *domain.Task) bool {
+		return task.IsDeleted() || !task.VisibleTo(ctx)
+	}), nil
+}
+
+// getTasks читает задачи ids, пропуская недоступные из ctx.
+func (s *TaskService) getTasks(ctx context.Context, ids []int) ([]*domain.Task, error) {
+	tasks := make([]*domain.Task, 0, len(ids))
+	for _, id := range ids {
+		if !s.owns(ctx, id) {
+			continue
+		}
+		task, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
 	}
+	return tasks, nil
+}
+
+// owns сообщает, есть ли вне корзины задача id, доступная из ctx.
+func (s *TaskService) owns(ctx context.Context, id int) bool {
+	if !s.tree.exists(id) {
+		return false
+	}
+	return domain.AllUsers(ctx) || s.tree.owner(id) == domain.UserID(ctx)
+}
+
+// SearchTasks ищет задачи по заголовку и описанию и возвращает не больше
+// limit результатов в порядке убывания релевантности.
+func (s *TaskService) SearchTasks(ctx context.Context, q string, limit int) ([]domain.SearchResult, error) {
+	query, err := search.ParseQuery(q)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidRequest, err)
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	hits := slices.DeleteFunc(s.index.Search(query), func(hit search.Result) bool {
+		return !s.owns(ctx, hit.ID)
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	results := make([]domain.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		task, err := s.repo.Get(ctx, hit.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.decorate(task)
+		results = append(results, domain.SearchResult{Task: task, Score: hit.Score})
+	}
+	return results, nil
+}
 
-	completeTime := time.Now()
-	task.Done = true
-	task.CompletedAt = &completeTime
+func (s *TaskService) GetTask(ctx context.Context, id int) (*domain.Task, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
 
+	task, err := getActive(ctx, s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+	s.decorate(task)
 	return task, nil
 }
 
-func (s *TaskService) UpdateContent(id int, headline string, description string) (*domain.Task, error) {
+// UpdateTask отмечает задачу выполненной: это переход в domain.StatusDone,
+// см. TransitionTask. Повторное завершение выполненной задачи ничего не меняет.
+func (s *TaskService) UpdateTask(ctx context.Context, id int, opts domain.CompleteOptions) (*domain.Task, error) {
+	return s.TransitionTask(ctx, id, domain.StatusDone, opts)
+}
+
+func (s *TaskService) UpdateContent(ctx context.Context, id int, input domain.TaskInput) (*domain.Task, error) {
+	return s.PatchContent(ctx, id, func(*domain.Task) (domain.TaskInput, error) {
+		return input, nil
+	})
+}
+
+// PatchContent частично меняет содержимое задачи: patch получает текущую
+// задачу и возвращает её новое содержимое. Чтение и запись идут под одной
+// блокировкой, поэтому параллельная правка между ними не потеряется.
+// Ошибка patch возвращается как есть.
+func (s *TaskService) PatchContent(ctx context.Context, id int, patch func(task *domain.Task) (domain.TaskInput, error)) (*domain.Task, error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
-	task, ok := s.tasks[id]
-	if !ok {
-		return nil, domain.ErrNotFound
+	task, op, err := s.patchWithUndo(ctx, id, patch)
+	if err != nil {
+		return nil, err
+	}
+	s.pushUndo(ctx, op)
+	return task, nil
+}
+
+// patchWithUndo проверяет версию задачи, меняет её содержимое и возвращает
+// запись для стека отмены; если содержимое не изменилось, отменять нечего.
+func (s *TaskService) patchWithUndo(ctx context.Context, id int, patch func(task *domain.Task) (domain.TaskInput, error)) (*domain.Task, *undoOp, error) {
+	if err := s.checkVersion(ctx, id); err != nil {
+		return nil, nil, err
+	}
+	before, err := getActive(ctx, s.repo, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	input, err := patch(before.Clone())
+	if err != nil {
+		return nil, nil, err
+	}
+	task, err := s.updateContent(ctx, id, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(domain.Diff(before, task)) == 0 {
+		return task, nil, nil
+	}
+	return task, s.undoEntry(ctx, &undoOp{kind: undoUpdate, taskID: id, before: before.Input(), after: task.Input()}, id), nil
+}
+
+func (s *TaskService) updateContent(ctx context.Context, id int, input domain.TaskInput) (*domain.Task, error) {
+	task, err := getActive(ctx, s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkParent(ctx, id, input.ParentID); err != nil {
+		return nil, err
+	}
+	if err := s.checkBlockers(ctx, id, domain.NormalizeBlockers(input.BlockedBy), task.BlockedBy); err != nil {
+		return nil, err
+	}
+	if err := checkRecurrence(input); err != nil {
+		return nil, err
+	}
+
+	task.Apply(input)
+
+	err = repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+		return saveTask(ctx, tx, domain.EventTaskContentUpdated, task)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	task.Headline = headline
-	task.Description = description
+	s.indexTask(task)
+	s.decorate(task)
 
 	return task, nil
 }
 
-func (s *TaskService) DeleteTask(id int) error {
+// DeleteTask перемещает задачу в корзину. Задачу с подзадачами можно удалить
+// только с opts.Cascade — тогда в корзину попадает всё поддерево. Из корзины
+// задачу можно восстановить (RestoreTask) или удалить навсегда (PurgeTask).
+// Версия задачи проверяется так же, как в TransitionTask.
+func (s *TaskService) DeleteTask(ctx context.Context, id int, opts domain.DeleteOptions) error {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
-	_, ok := s.tasks[id]
-	if !ok {
-		return domain.ErrNotFound
+	op, err := s.deleteWithUndo(ctx, id, opts)
+	if err != nil {
+		return err
 	}
-
-	delete(s.tasks, id)
+	s.pushUndo(ctx, op)
 	return nil
 }
+
+// deleteWithUndo проверяет версию задачи, перемещает её в корзину и
+// возвращает запись для стека отмены.
+func (s *TaskService) deleteWithUndo(ctx context.Context, id int, opts domain.DeleteOptions) (*undoOp, error) {
+	if err := s.checkVersion(ctx, id); err != nil {
+		return nil, err
+	}
+	ids, err := s.deleteTask(ctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.undoEntry(ctx, &undoOp{kind: undoDelete, taskID: id, cascade: opts.Cascade}, ids...), nil
+}
+
+// deleteTask перемещает задачу в корзину и возвращает ID задач, попавших
+// туда вместе с ней.
+func (s *TaskService) deleteTask(ctx context.Context, id int, opts domain.DeleteOptions) ([]int, error) {
+	if !s.owns(ctx, id) {
+		return nil, domain.ErrNotFound
+	}
+	if s.tree.hasChildren(id) && !opts.Cascade {
+		return nil, domain.ErrHasSubtasks
+	}
+
+	// Подзадачи убираем раньше родителей: если хранилище без транзакций
+	// прервётся на середине, в корзине не окажется родитель живой подзадачи
+	ids := append([]int{id}, s.tree.descendants(id)...)
+	slices.Reverse(ids)
+
+	// blocked_by задач, которые зависят от удалённых, не меняем: задачи в
+	// корзине не блокируют (см. openBlockers), а после восстановления
+	// зависимости снова действуют. Связи снимаются при очистке корзины.
+	deletedAt := time.Now()
+	err := repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+		for _, id := range ids {
+			task, err := tx.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			task.DeletedAt = &deletedAt
+			if err := saveTask(ctx, tx, domain.EventTaskDeleted, task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if len(ids) > 1 {
+			s.reindex(ctx, ids)
+		}
+		return nil, err
+	}
+
+	for _, id := range ids {
+		s.unindexTask(id)
+	}
+	return ids, nil
+}
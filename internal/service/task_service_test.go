@@ -1,30 +1,59 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository/memory"
 )
 
+func newTestService() *TaskService {
+	service, err := NewTaskService(context.Background(), memory.NewTaskRepository())
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+func mustCreate(t *testing.T, service *TaskService, headline string, description string) *domain.Task {
+	t.Helper()
+	task, err := service.CreateTask(context.Background(), domain.TaskInput{Headline: headline, Description: description})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return task
+}
+
+func mustList(t *testing.T, service *TaskService) []*domain.Task {
+	t.Helper()
+	tasks, err := service.GetAllTasks(context.Background())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	return tasks
+}
+
 func TestNewTaskService(t *testing.T) {
-	service := NewTaskService()
+	service := newTestService()
 	if service == nil {
 		t.Fatal("nil")
 	}
-	if service.tasks == nil {
-		t.Fatal("tasks nil")
+	if service.repo == nil {
+		t.Fatal("repo nil")
 	}
-	if len(service.tasks) != 0 {
+	if len(mustList(t, service)) != 0 {
 		t.Fatal("not empty")
 	}
 }
 
 func TestCreateTaskSuccess(t *testing.T) {
-	service := NewTaskService()
+	service := newTestService()
 	headline := "Test Task"
 	description := "Test Description"
 
-	task := service.CreateTask(headline, description)
+	task := mustCreate(t, service, headline, description)
 
 	if task == nil {
 		t.Fatal("nil")
@@ -51,18 +80,18 @@ func TestCreateTaskSuccess(t *testing.T) {
 		t.Error("CompletedAt != nil")
 	}
 
-	allTasks := service.GetAllTasks()
+	allTasks := mustList(t, service)
 	if len(allTasks) != 1 {
 		t.Errorf("len != 1: %d", len(allTasks))
 	}
 }
 
 func TestCreateTaskUniqueIDs(t *testing.T) {
-	service := NewTaskService()
+	service := newTestService()
 	ids := make(map[int]bool)
 
 	for i := 0; i < 100; i++ {
-		task := service.CreateTask("Task", "Description")
+		task := mustCreate(t, service, "Task", "Description")
 		if ids[task.ID] {
 			t.Errorf("duplicate: %d", task.ID)
 		}
@@ -75,8 +104,8 @@ func TestCreateTaskUniqueIDs(t *testing.T) {
 }
 
 func TestGetAllTasksEmpty(t *testing.T) {
-	service := NewTaskService()
-	tasks := service.GetAllTasks()
+	service := newTestService()
+	tasks := mustList(t, service)
 
 	if tasks == nil {
 		t.Fatal("nil")
@@ -87,23 +116,23 @@ func TestGetAllTasksEmpty(t *testing.T) {
 }
 
 func TestGetAllTasksMultiple(t *testing.T) {
-	service := NewTaskService()
+	service := newTestService()
 
-	service.CreateTask("Task 1", "Description 1")
-	service.CreateTask("Task 2", "Description 2")
-	service.CreateTask("Task 3", "Description 3")
+	mustCreate(t, service, "Task 1", "Description 1")
+	mustCreate(t, service, "Task 2", "Description 2")
+	mustCreate(t, service, "Task 3", "Description 3")
 
-	tasks := service.GetAllTasks()
+	tasks := mustList(t, service)
 	if len(tasks) != 3 {
 		t.Errorf("len != 3: %d", len(tasks))
 	}
 }
 
 func TestGetTaskSuccess(t *testing.T) {
-	service := NewTaskService()
-	createdTask := service.CreateTask("Test Task", "Test Description")
+	service := newTestService()
+	createdTask := mustCreate(t, service, "Test Task", "Test Description")
 
-	retrievedTask, err := service.GetTask(createdTask.ID)
+	retrievedTask, err := service.GetTask(context.Background(), createdTask.ID)
 	if err != nil {
 		t.Fatalf("error: %v", err)
 	}
@@ -119,9 +148,9 @@ func TestGetTaskSuccess(t *testing.T) {
 }
 
 func TestGetTaskNotFound(t *testing.T) {
-	service := NewTaskService()
+	service := newTestService()
 
-	_, err := service.GetTask(99999)
+	_, err := service.GetTask(context.Background(), 99999)
 	if err == nil {
 		t.Fatal("no error")
 	}
@@ -131,10 +160,10 @@ func TestGetTaskNotFound(t *testing.T) {
 }
 
 func TestUpdateTaskSuccess(t *testing.T) {
-	service := NewTaskService()
-	createdTask := service.CreateTask("Test Task", "Test Description")
+	service := newTestService()
+	createdTask := mustCreate(t, service, "Test Task", "Test Description")
 
-	updatedTask, err := service.UpdateTask(createdTask.ID)
+	updatedTask, err := service.UpdateTask(context.Background(), createdTask.ID, domain.CompleteOptions{})
 	if err != nil {
 		t.Fatalf("error: %v", err)
 	}
@@ -156,9 +185,9 @@ func TestUpdateTaskSuccess(t *testing.T) {
 }
 
 func TestUpdateTaskNotFound(t *testing.T) {
-	service := NewTaskService()
+	service := newTestService()
 
-	_, err := service.UpdateTask(99999)
+	_, err := service.UpdateTask(context.Background(), 99999, domain.CompleteOptions{})
 	if err == nil {
 		t.Fatal("no error")
 	}
@@ -168,13 +197,13 @@ func TestUpdateTaskNotFound(t *testing.T) {
 }
 
 func TestUpdateContentSuccess(t *testing.T) {
-	service := NewTaskService()
-	createdTask := service.CreateTask("Old Headline", "Old Description")
+	service := newTestService()
+	createdTask := mustCreate(t, service, "Old Headline", "Old Description")
 
 	newHeadline := "New Headline"
 	newDescription := "New Description"
 
-	updatedTask, err := service.UpdateContent(createdTask.ID, newHeadline, newDescription)
+	updatedTask, err := service.UpdateContent(context.Background(), createdTask.ID, domain.TaskInput{Headline: newHeadline, Description: newDescription})
 	if err != nil {
 		t.Fatalf("error: %v", err)
 	}
@@ -193,9 +222,9 @@ func TestUpdateContentSuccess(t *testing.T) {
 }
 
 func TestUpdateContentNotFound(t *testing.T) {
-	service := NewTaskService()
+	service := newTestService()
 
-	_, err := service.UpdateContent(99999, "Headline", "Description")
+	_, err := service.UpdateContent(context.Background(), 99999, domain.TaskInput{Headline: "Headline", Description: "Description"})
 	if err == nil {
 		t.Fatal("no error")
 	}
@@ -204,16 +233,85 @@ func TestUpdateContentNotFound(t *testing.T) {
 	}
 }
 
+func TestPatchContent(t *testing.T) {
+	service := newTestService()
+	task := mustCreate(t, service, "Headline", "Description")
+
+	patched, err := service.PatchContent(context.Background(), task.ID, func(current *domain.Task) (domain.TaskInput, error) {
+		input := current.Input()
+		input.Description = "Patched"
+		return input, nil
+	})
+	if err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	if patched.Headline != "Headline" || patched.Description != "Patched" {
+		t.Errorf("task = %q %q", patched.Headline, patched.Description)
+	}
+
+	_, err = service.PatchContent(context.Background(), task.ID, func(*domain.Task) (domain.TaskInput, error) {
+		return domain.TaskInput{}, domain.ErrPatchConflict
+	})
+	if !errors.Is(err, domain.ErrPatchConflict) {
+		t.Fatalf("want ErrPatchConflict, got %v", err)
+	}
+	got, _ := service.GetTask(context.Background(), task.ID)
+	if got.Description != "Patched" || got.Version != 2 {
+		t.Errorf("failed patch changed task: %q v%d", got.Description, got.Version)
+	}
+}
+
+func TestUpdateContentIfMatch(t *testing.T) {
+	service := newTestService()
+	task := mustCreate(t, service, "Headline", "Description")
+	if task.Version != 1 {
+		t.Fatalf("version after create = %d, want 1", task.Version)
+	}
+
+	ctx := domain.WithIfMatch(context.Background(), []int{1})
+	updated, err := service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: "New", Description: "Description"})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("version after update = %d, want 2", updated.Version)
+	}
+
+	// Вторая правка по той же версии не должна затереть первую
+	_, err = service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: "Stale", Description: "Description"})
+	if !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("want ErrVersionMismatch, got %v", err)
+	}
+	if err := service.DeleteTask(ctx, task.ID, domain.DeleteOptions{}); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("delete: want ErrVersionMismatch, got %v", err)
+	}
+	got, err := service.GetTask(context.Background(), task.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Headline != "New" || got.Version != 2 {
+		t.Errorf("task = %q v%d, want \"New\" v2", got.Headline, got.Version)
+	}
+
+	done, err := service.TransitionTask(domain.WithIfMatch(context.Background(), []int{2}), task.ID, domain.StatusDone, domain.CompleteOptions{})
+	if err != nil {
+		t.Fatalf("transition: %v", err)
+	}
+	if done.Version != 3 {
+		t.Errorf("version after transition = %d, want 3", done.Version)
+	}
+}
+
 func TestDeleteTaskSuccess(t *testing.T) {
-	service := NewTaskService()
-	createdTask := service.CreateTask("Test Task", "Test Description")
+	service := newTestService()
+	createdTask := mustCreate(t, service, "Test Task", "Test Description")
 
-	err := service.DeleteTask(createdTask.ID)
+	err := service.DeleteTask(context.Background(), createdTask.ID, domain.DeleteOptions{})
 	if err != nil {
 		t.Fatalf("error: %v", err)
 	}
 
-	_, err = service.GetTask(createdTask.ID)
+	_, err = service.GetTask(context.Background(), createdTask.ID)
 	if err == nil {
 		t.Fatal("not deleted")
 	}
@@ -221,16 +319,16 @@ func TestDeleteTaskSuccess(t *testing.T) {
 		t.Errorf("want ErrNotFound, got %v", err)
 	}
 
-	tasks := service.GetAllTasks()
+	tasks := mustList(t, service)
 	if len(tasks) != 0 {
 		t.Errorf("len != 0: %d", len(tasks))
 	}
 }
 
 func TestDeleteTaskNotFound(t *testing.T) {
-	service := NewTaskService()
+	service := newTestService()
 
-	err := service.DeleteTask(99999)
+	err := service.DeleteTask(context.Background(), 99999, domain.DeleteOptions{})
 	if err == nil {
 		t.Fatal("no error")
 	}
@@ -240,13 +338,15 @@ func TestDeleteTaskNotFound(t *testing.T) {
 }
 
 func TestTaskServiceConcurrency(t *testing.T) {
-	service := NewTaskService()
+	service := newTestService()
 	done := make(chan bool)
 
 	for i := 0; i < 10; i++ {
 		go func() {
 			for j := 0; j < 10; j++ {
-				service.CreateTask("Task", "Description")
+				if _, err := service.CreateTask(context.Background(), domain.TaskInput{Headline: "Task", Description: "Description"}); err != nil {
+					t.Errorf("error: %v", err)
+				}
 			}
 			done <- true
 		}()
@@ -256,8 +356,68 @@ func TestTaskServiceConcurrency(t *testing.T) {
 		<-done
 	}
 
-	tasks := service.GetAllTasks()
+	tasks := mustList(t, service)
 	if len(tasks) != 100 {
 		t.Errorf("len != 100: %d", len(tasks))
 	}
 }
+
+type collidingRepository struct {
+	*memory.TaskRepository
+	collisions int
+}
+
+func (r *collidingRepository) Create(ctx context.Context, task *domain.Task) error {
+	if r.collisions > 0 {
+		r.collisions--
+		return domain.ErrAlreadyExists
+	}
+	return r.TaskRepository.Create(ctx, task)
+}
+
+func TestCreateTaskRetriesOnCollision(t *testing.T) {
+	repo := &collidingRepository{TaskRepository: memory.NewTaskRepository(), collisions: 3}
+	service, err := NewTaskService(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	task := mustCreate(t, service, "Task", "Description")
+	if repo.collisions != 0 {
+		t.Errorf("collisions left: %d", repo.collisions)
+	}
+	if _, err := service.GetTask(context.Background(), task.ID); err != nil {
+		t.Errorf("error: %v", err)
+	}
+}
+
+type failingRepository struct {
+	*memory.TaskRepository
+}
+
+var errStorage = errors.New("storage failure")
+
+func (r *failingRepository) Update(ctx context.Context, task *domain.Task) error {
+	return errStorage
+}
+
+func TestUpdateTaskRepositoryError(t *testing.T) {
+	service, err := NewTaskService(context.Background(), &failingRepository{TaskRepository: memory.NewTaskRepository()})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	createdTask := mustCreate(t, service, "Test Task", "Test Description")
+
+	_, err = service.UpdateTask(context.Background(), createdTask.ID, domain.CompleteOptions{})
+	if !errors.Is(err, errStorage) {
+		t.Fatalf("want errStorage, got %v", err)
+	}
+
+	task, err := service.GetTask(context.Background(), createdTask.ID)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if task.Done {
+		t.Error("done persisted")
+	}
+}
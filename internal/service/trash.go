@@ -0,0 +1,251 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// trash — доступные из контекста задачи в корзине. Их нет в индексах сервиса, поэтому корзина
+// каждый раз читается из хранилища.
+type trash struct {
+	tasks    map[int]*domain.Task
+	children map[int][]int
+}
+
+func loadTrash(ctx context.Context, repo repository.TaskRepository) (*trash, error) {
+	tasks, err := repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &trash{
+		tasks:    make(map[int]*domain.Task),
+		children: make(map[int][]int),
+	}
+	for _, task := range tasks {
+		if !task.IsDeleted() || !task.VisibleTo(ctx) {
+			continue
+		}
+		t.tasks[task.ID] = task
+		if task.ParentID != nil {
+			t.children[*task.ParentID] = append(t.children[*task.ParentID], task.ID)
+		}
+	}
+	return t, nil
+}
+
+// subtree возвращает задачу id и тех её потомков в корзине, для которых keep
+// вернул true. Подзадачи идут раньше родителей.
+func (t *trash) subtree(id int, keep func(task *domain.Task) bool) []int {
+	var ids []int
+	seen := make(map[int]bool)
+	var walk func(id int)
+	walk = func(id int) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		for _, child := range t.children[id] {
+			if keep(t.tasks[child]) {
+				walk(child)
+			}
+		}
+		ids = append(ids, id)
+	}
+	walk(id)
+	return ids
+}
+
+// ListTrash возвращает задачи из корзины, последние удалённые — первыми.
+func (s *TaskService) ListTrash(ctx context.Context) ([]*domain.Task, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	t, err := loadTrash(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*domain.Task, 0, len(t.tasks))
+	for _, task := range t.tasks {
+		tasks = append(tasks, task)
+	}
+	slices.SortFunc(tasks, func(a, b *domain.Task) int {
+		if c := b.DeletedAt.Compare(*a.DeletedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины вместе с подзадачами, удалёнными
+// вместе с ней. Если родитель задачи тоже в корзине, возвращается
+// domain.ErrParentDeleted: сначала нужно восстановить его. Блокирующие
+// задачи, удалённые навсегда, убираются из blocked_by; задачи из корзины
+// остаются.
+func (s *TaskService) RestoreTask(ctx context.Context, id int) (*domain.Task, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	restored, err := s.restoreTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	task := restored[0]
+	s.decorate(task)
+	return task, nil
+}
+
+// restoreTask возвращает задачи из корзины; первой идёт задача id.
+func (s *TaskService) restoreTask(ctx context.Context, id int) ([]*domain.Task, error) {
+	t, err := loadTrash(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := t.tasks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if root.ParentID != nil && !s.tree.exists(*root.ParentID) {
+		return nil, fmt.Errorf("%w: restore task %d first", domain.ErrParentDeleted, *root.ParentID)
+	}
+
+	ids := t.subtree(id, func(task *domain.Task) bool { return task.DeletedAt.Equal(*root.DeletedAt) })
+	// Родителей восстанавливаем раньше подзадач, чтобы не было живой
+	// подзадачи у родителя в корзине
+	slices.Reverse(ids)
+	restoring := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		restoring[id] = true
+	}
+
+	restored := make([]*domain.Task, 0, len(ids))
+	err = repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+		for _, id := range ids {
+			task := t.tasks[id]
+			task.DeletedAt = nil
+			task.BlockedBy = slices.DeleteFunc(task.BlockedBy, func(blocker int) bool {
+				return !s.tree.exists(blocker) && !restoring[blocker] && t.tasks[blocker] == nil
+			})
+			if err := saveTask(ctx, tx, domain.EventTaskRestored, task); err != nil {
+				return err
+			}
+			restored = append(restored, task)
+		}
+		return nil
+	})
+	if err != nil {
+		if len(ids) > 1 {
+			s.reindex(ctx, ids)
+		}
+		return nil, err
+	}
+
+	for _, task := range restored {
+		s.indexTask(task)
+	}
+	return restored, nil
+}
+
+// PurgeTask навсегда удаляет задачу из корзины вместе с её подзадачами в корзине.
+func (s *TaskService) PurgeTask(ctx context.Context, id int) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	t, err := loadTrash(ctx, s.repo)
+	if err != nil {
+		return err
+	}
+	if _, ok := t.tasks[id]; !ok {
+		return domain.ErrNotFound
+	}
+	return s.purge(ctx, t.subtree(id, func(*domain.Task) bool { return true }))
+}
+
+// PurgeTrash навсегда удаляет задачи, попавшие в корзину раньше before, и
+// возвращает их число. Нулевое before очищает всю корзину.
+func (s *TaskService) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	t, err := loadTrash(ctx, s.repo)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired []int
+	for id, task := range t.tasks {
+		if before.IsZero() || task.DeletedAt.Before(before) {
+			expired = append(expired, id)
+		}
+	}
+	slices.Sort(expired)
+
+	var ids []int
+	seen := make(map[int]bool)
+	for _, id := range expired {
+		for _, id := range t.subtree(id, func(*domain.Task) bool { return true }) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if err := s.purge(ctx, ids); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// purge удаляет задачи из хранилища и убирает их из blocked_by задач,
+// которые от них зависели. Подзадачи должны идти раньше родителей.
+func (s *TaskService) purge(ctx context.Context, ids []int) error {
+	purged := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		purged[id] = true
+	}
+	var dependents []int
+	for _, id := range ids {
+		dependents = append(dependents, s.deps.dependentIDs(id)...)
+	}
+	dependents = domain.NormalizeBlockers(dependents)
+
+	updated := make([]*domain.Task, 0, len(dependents))
+	err := repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+		for _, id := range dependents {
+			task, err := tx.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			task.BlockedBy = slices.DeleteFunc(task.BlockedBy, func(blocker int) bool { return purged[blocker] })
+			if err := saveTask(ctx, tx, domain.EventTaskContentUpdated, task); err != nil {
+				return err
+			}
+			updated = append(updated, task)
+		}
+		for _, id := range ids {
+			if err := tx.Delete(ctx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if len(dependents) > 0 {
+			s.reindex(ctx, dependents)
+		}
+		return err
+	}
+
+	for _, task := range updated {
+		s.deps.set(task)
+	}
+	return nil
+}
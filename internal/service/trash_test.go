@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func TestDeleteMovesToTrash(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	task := mustCreate(t, service, "Молоко", "Купить")
+
+	if err := service.DeleteTask(ctx, task.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := service.GetTask(ctx, task.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("get: %v", err)
+	}
+	if tasks := mustList(t, service); len(tasks) != 0 {
+		t.Errorf("list: %v", tasks)
+	}
+	if results, _ := service.SearchTasks(ctx, "молоко", 10); len(results) != 0 {
+		t.Errorf("search: %v", results)
+	}
+	if err := service.DeleteTask(ctx, task.ID, domain.DeleteOptions{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("second delete: %v", err)
+	}
+
+	trash, err := service.ListTrash(ctx)
+	if err != nil {
+		t.Fatalf("trash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != task.ID || trash[0].DeletedAt == nil {
+		t.Fatalf("trash: %v", trash)
+	}
+
+	restored, err := service.RestoreTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.IsDeleted() {
+		t.Errorf("restored task still deleted")
+	}
+	if results, _ := service.SearchTasks(ctx, "молоко", 10); len(results) != 1 {
+		t.Errorf("search after restore: %v", results)
+	}
+	if _, err := service.RestoreTask(ctx, task.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("restore live task: %v", err)
+	}
+}
+
+func TestRestoreSubtree(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	root := mustCreate(t, service, "Root", "D")
+	child := mustCreateChild(t, service, "Child", root.ID)
+	grandchild := mustCreateChild(t, service, "Grandchild", child.ID)
+
+	if err := service.DeleteTask(ctx, root.ID, domain.DeleteOptions{Cascade: true}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := service.RestoreTask(ctx, child.ID); !errors.Is(err, domain.ErrParentDeleted) {
+		t.Errorf("restore child first: %v", err)
+	}
+
+	if _, err := service.RestoreTask(ctx, root.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	node, err := service.GetSubtree(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("subtree: %v", err)
+	}
+	if len(node.Children) != 1 || len(node.Children[0].Children) != 1 || node.Children[0].Children[0].Task.ID != grandchild.ID {
+		t.Errorf("subtree was not restored")
+	}
+}
+
+func TestRestoreDropsMissingBlockers(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	blocker := mustCreate(t, service, "Blocker", "D")
+	other := mustCreate(t, service, "Other", "D")
+	task := mustCreateBlocked(t, service, "Task", "", blocker.ID, other.ID)
+
+	for _, id := range []int{task.ID, blocker.ID} {
+		if err := service.DeleteTask(ctx, id, domain.DeleteOptions{}); err != nil {
+			t.Fatalf("delete %d: %v", id, err)
+		}
+	}
+	if err := service.PurgeTask(ctx, blocker.ID); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+
+	restored, err := service.RestoreTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if !slices.Equal(restored.BlockedBy, []int{other.ID}) {
+		t.Errorf("blocked_by: %v", restored.BlockedBy)
+	}
+}
+
+func TestRestoreKeepsDependencies(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	blocker := mustCreate(t, service, "Blocker", "D")
+	task := mustCreateBlocked(t, service, "Task", "", blocker.ID)
+	dependent := mustCreateBlocked(t, service, "Dependent", "", task.ID)
+
+	if err := service.DeleteTask(ctx, task.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got, _ := service.GetTask(ctx, dependent.ID); got.IsBlocked() {
+		t.Errorf("dependent blocked by trashed task: %v", got.OpenBlockers)
+	}
+
+	restored, err := service.RestoreTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if !slices.Equal(restored.BlockedBy, []int{blocker.ID}) {
+		t.Errorf("restored blocked_by: %v", restored.BlockedBy)
+	}
+	got, _ := service.GetTask(ctx, dependent.ID)
+	if !slices.Equal(got.BlockedBy, []int{task.ID}) || !slices.Equal(got.OpenBlockers, []int{task.ID}) {
+		t.Errorf("dependent: blocked_by %v, open %v", got.BlockedBy, got.OpenBlockers)
+	}
+}
+
+func TestRestoreKeepsBlockersInTrash(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	blocker := mustCreate(t, service, "Blocker", "D")
+	task := mustCreateBlocked(t, service, "Task", "", blocker.ID)
+
+	for _, id := range []int{task.ID, blocker.ID} {
+		if err := service.DeleteTask(ctx, id, domain.DeleteOptions{}); err != nil {
+			t.Fatalf("delete %d: %v", id, err)
+		}
+	}
+	if _, err := service.RestoreTask(ctx, task.ID); err != nil {
+		t.Fatalf("restore task: %v", err)
+	}
+	if _, err := service.RestoreTask(ctx, blocker.ID); err != nil {
+		t.Fatalf("restore blocker: %v", err)
+	}
+	got, _ := service.GetTask(ctx, task.ID)
+	if !slices.Equal(got.OpenBlockers, []int{blocker.ID}) {
+		t.Errorf("open blockers: %v", got.OpenBlockers)
+	}
+}
+
+func TestPurgeTrashRetention(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	old := mustCreate(t, service, "Old", "D")
+	recent := mustCreate(t, service, "Recent", "D")
+	for _, id := range []int{old.ID, recent.ID} {
+		if err := service.DeleteTask(ctx, id, domain.DeleteOptions{}); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+	}
+
+	// Сдвигаем время удаления первой задачи в прошлое
+	task, _ := service.repo.Get(ctx, old.ID)
+	deletedAt := time.Now().Add(-48 * time.Hour)
+	task.DeletedAt = &deletedAt
+	if err := service.repo.Update(ctx, task); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	n, err := service.PurgeTrash(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("purge: %d %v", n, err)
+	}
+	trash, _ := service.ListTrash(ctx)
+	if len(trash) != 1 || trash[0].ID != recent.ID {
+		t.Errorf("trash: %v", trash)
+	}
+
+	if n, err := service.PurgeTrash(ctx, time.Time{}); err != nil || n != 1 {
+		t.Errorf("empty trash: %d %v", n, err)
+	}
+	if err := service.PurgeTask(ctx, recent.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("purge missing: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+// ListChildren возвращает прямые подзадачи в порядке создания.
+func (s *TaskService) ListChildren(ctx context.Context, id int) ([]*domain.Task, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	if !s.owns(ctx, id) {
+		return nil, domain.ErrNotFound
+	}
+
+	children, err := s.getTasks(ctx, s.tree.childIDs(id))
+	if err != nil {
+		return nil, err
+	}
+	sortTasks(children)
+	s.decorate(children...)
+	return children, nil
+}
+
+// GetSubtree возвращает задачу со всеми подзадачами на всех уровнях.
+func (s *TaskService) GetSubtree(ctx context.Context, id int) (*domain.TaskNode, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	task, err := getActive(ctx, s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildNode(ctx, task, map[int]bool{})
+}
+
+func (s *TaskService) buildNode(ctx context.Context, task *domain.Task, seen map[int]bool) (*domain.TaskNode, error) {
+	seen[task.ID] = true
+	s.decorate(task)
+
+	children, err := s.getTasks(ctx, s.tree.childIDs(task.ID))
+	if err != nil {
+		return nil, err
+	}
+	sortTasks(children)
+
+	node := &domain.TaskNode{Task: task, Children: make([]*domain.TaskNode, 0, len(children))}
+	for _, child := range children {
+		if seen[child.ID] {
+			continue
+		}
+		childNode, err := s.buildNode(ctx, child, seen)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	return node, nil
+}
+
+func sortTasks(tasks []*domain.Task) {
+	slices.SortFunc(tasks, func(a, b *domain.Task) int {
+		return domain.CompareTasks(a, b, domain.DefaultSort)
+	})
+}
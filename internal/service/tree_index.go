@@ -0,0 +1,152 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+type treeNode struct {
+	parent int
+	owner  int
+	status domain.Status
+}
+
+// treeIndex хранит иерархию задач: родителя и детей каждой задачи, а также
+// их владельцев и статусы, чтобы проверять доступ и считать прогресс без
+// обращения к хранилищу.
+// Внутренней синхронизации нет, доступ защищает мьютекс сервиса.
+type treeIndex struct {
+	nodes    map[int]treeNode
+	children map[int]map[int]struct{}
+}
+
+func newTreeIndex() *treeIndex {
+	return &treeIndex{
+		nodes:    make(map[int]treeNode),
+		children: make(map[int]map[int]struct{}),
+	}
+}
+
+func (idx *treeIndex) set(task *domain.Task) {
+	if old, ok := idx.nodes[task.ID]; ok {
+		idx.unlink(task.ID, old.parent)
+	}
+
+	node := treeNode{owner: task.OwnerID, status: task.CurrentStatus()}
+	if task.ParentID != nil {
+		node.parent = *task.ParentID
+		ids, ok := idx.children[node.parent]
+		if !ok {
+			ids = make(map[int]struct{})
+			idx.children[node.parent] = ids
+		}
+		ids[task.ID] = struct{}{}
+	}
+	idx.nodes[task.ID] = node
+}
+
+func (idx *treeIndex) remove(id int) {
+	if old, ok := idx.nodes[id]; ok {
+		idx.unlink(id, old.parent)
+	}
+	delete(idx.nodes, id)
+}
+
+func (idx *treeIndex) unlink(id int, parent int) {
+	if parent == 0 {
+		return
+	}
+	delete(idx.children[parent], id)
+	if len(idx.children[parent]) == 0 {
+		delete(idx.children, parent)
+	}
+}
+
+func (idx *treeIndex) exists(id int) bool {
+	_, ok := idx.nodes[id]
+	return ok
+}
+
+func (idx *treeIndex) owner(id int) int {
+	return idx.nodes[id].owner
+}
+
+func (idx *treeIndex) isClosed(id int) bool {
+	return idx.nodes[id].status.IsClosed()
+}
+
+func (idx *treeIndex) hasChildren(id int) bool {
+	return len(idx.children[id]) > 0
+}
+
+// childIDs возвращает прямых потомков в порядке возрастания ID.
+func (idx *treeIndex) childIDs(id int) []int {
+	ids := make([]int, 0, len(idx.children[id]))
+	for child := range idx.children[id] {
+		ids = append(ids, child)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// descendants возвращает всех потомков задачи: каждый родитель идёт
+// раньше своих детей.
+func (idx *treeIndex) descendants(id int) []int {
+	var result []int
+	seen := map[int]bool{id: true}
+	queue := idx.childIDs(id)
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = queue[1:]
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		result = append(result, child)
+		queue = append(queue, idx.childIDs(child)...)
+	}
+	return result
+}
+
+// isAncestor сообщает, лежит ли ancestor на пути от id к корню.
+func (idx *treeIndex) isAncestor(ancestor int, id int) bool {
+	// Ограничение числа шагов защищает от зацикливания на повреждённых данных
+	for steps := 0; id != 0 && steps <= len(idx.nodes); steps++ {
+		if id == ancestor {
+			return true
+		}
+		id = idx.nodes[id].parent
+	}
+	return false
+}
+
+// depth возвращает число предков задачи.
+func (idx *treeIndex) depth(id int) int {
+	depth := 0
+	for id = idx.nodes[id].parent; id != 0 && depth <= len(idx.nodes); id = idx.nodes[id].parent {
+		depth++
+	}
+	return depth
+}
+
+// progress считает выполненные подзадачи на всех уровнях. Отменённые
+// подзадачи в прогресс не входят. Для задачи без подзадач возвращает nil.
+func (idx *treeIndex) progress(id int) *domain.Progress {
+	if !idx.hasChildren(id) {
+		return nil
+	}
+
+	var p domain.Progress
+	for _, child := range idx.descendants(id) {
+		switch idx.nodes[child].status {
+		case domain.StatusCancelled:
+		case domain.StatusDone:
+			p.Done++
+			p.Total++
+		default:
+			p.Total++
+		}
+	}
+	return &p
+}
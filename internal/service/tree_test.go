@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository/memory"
+)
+
+func mustCreateChild(t *testing.T, service *TaskService, headline string, parentID int) *domain.Task {
+	t.Helper()
+	task, err := service.CreateTask(context.Background(), domain.TaskInput{Headline: headline, Description: "D", ParentID: &parentID})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return task
+}
+
+func TestSubtasksProgressRollup(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	root := mustCreate(t, service, "Release", "D")
+	backend := mustCreateChild(t, service, "Backend", root.ID)
+	mustCreateChild(t, service, "Frontend", root.ID)
+	api := mustCreateChild(t, service, "API", backend.ID)
+	mustCreateChild(t, service, "Migrations", backend.ID)
+
+	if _, err := service.UpdateTask(ctx, api.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	got, err := service.GetTask(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Subtasks == nil || got.Subtasks.Done != 1 || got.Subtasks.Total != 4 {
+		t.Errorf("root progress: %+v", got.Subtasks)
+	}
+	got, _ = service.GetTask(ctx, backend.ID)
+	if got.Subtasks == nil || got.Subtasks.Done != 1 || got.Subtasks.Total != 2 || got.Subtasks.Percent() != 50 {
+		t.Errorf("backend progress: %+v", got.Subtasks)
+	}
+	got, _ = service.GetTask(ctx, api.ID)
+	if got.Subtasks != nil {
+		t.Errorf("leaf progress: %+v", got.Subtasks)
+	}
+}
+
+func TestListChildrenAndSubtree(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	root := mustCreate(t, service, "Release", "D")
+	first := mustCreateChild(t, service, "First", root.ID)
+	second := mustCreateChild(t, service, "Second", root.ID)
+	nested := mustCreateChild(t, service, "Nested", first.ID)
+
+	children, err := service.ListChildren(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("children: %v", err)
+	}
+	if len(children) != 2 || children[0].ID != first.ID || children[1].ID != second.ID {
+		t.Errorf("children: %v", children)
+	}
+
+	tree, err := service.GetSubtree(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("subtree: %v", err)
+	}
+	if len(tree.Children) != 2 || len(tree.Children[0].Children) != 1 || tree.Children[0].Children[0].Task.ID != nested.ID {
+		t.Errorf("subtree: %+v", tree)
+	}
+
+	if _, err := service.ListChildren(ctx, 99999); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("children of missing: %v", err)
+	}
+	if _, err := service.GetSubtree(ctx, 99999); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("subtree of missing: %v", err)
+	}
+}
+
+func TestSubtaskParentValidation(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	root := mustCreate(t, service, "Root", "D")
+	child := mustCreateChild(t, service, "Child", root.ID)
+	grandchild := mustCreateChild(t, service, "Grandchild", child.ID)
+
+	missing := 99999
+	if _, err := service.CreateTask(ctx, domain.TaskInput{Headline: "H", Description: "D", ParentID: &missing}); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("missing parent: %v", err)
+	}
+
+	for _, parentID := range []int{root.ID, grandchild.ID} {
+		_, err := service.UpdateContent(ctx, root.ID, domain.TaskInput{Headline: "Root", Description: "D", ParentID: &parentID})
+		if !errors.Is(err, domain.ErrCycle) {
+			t.Errorf("parent %d: want ErrCycle, got %v", parentID, err)
+		}
+	}
+
+	// Перенос поддерева в другое место дерева допустим
+	other := mustCreate(t, service, "Other", "D")
+	if _, err := service.UpdateContent(ctx, child.ID, domain.TaskInput{Headline: "Child", Description: "D", ParentID: &other.ID}); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	got, _ := service.GetTask(ctx, other.ID)
+	if got.Subtasks == nil || got.Subtasks.Total != 2 {
+		t.Errorf("progress after move: %+v", got.Subtasks)
+	}
+	got, _ = service.GetTask(ctx, root.ID)
+	if got.Subtasks != nil {
+		t.Errorf("old parent progress: %+v", got.Subtasks)
+	}
+}
+
+func TestCascadeComplete(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	root := mustCreate(t, service, "Root", "D")
+	done := mustCreateChild(t, service, "Done", root.ID)
+	open := mustCreateChild(t, service, "Open", done.ID)
+
+	completed, err := service.UpdateTask(ctx, done.ID, domain.CompleteOptions{})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	doneAt := *completed.CompletedAt
+
+	task, err := service.UpdateTask(ctx, root.ID, domain.CompleteOptions{Cascade: true})
+	if err != nil {
+		t.Fatalf("cascade: %v", err)
+	}
+	if !task.Done || task.Subtasks == nil || task.Subtasks.Done != 2 {
+		t.Errorf("root: %+v %+v", task, task.Subtasks)
+	}
+
+	got, _ := service.GetTask(ctx, open.ID)
+	if !got.Done {
+		t.Error("nested subtask not completed")
+	}
+	got, _ = service.GetTask(ctx, done.ID)
+	if !got.CompletedAt.Equal(doneAt) {
+		t.Error("completed_at of done subtask changed")
+	}
+}
+
+func TestCascadeDelete(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+	root := mustCreate(t, service, "Root", "D")
+	child := mustCreateChild(t, service, "Child", root.ID)
+	mustCreateChild(t, service, "Grandchild", child.ID)
+	other := mustCreate(t, service, "Other", "D")
+
+	if err := service.DeleteTask(ctx, root.ID, domain.DeleteOptions{}); !errors.Is(err, domain.ErrHasSubtasks) {
+		t.Fatalf("want ErrHasSubtasks, got %v", err)
+	}
+	if err := service.DeleteTask(ctx, root.ID, domain.DeleteOptions{Cascade: true}); err != nil {
+		t.Fatalf("cascade: %v", err)
+	}
+
+	tasks := mustList(t, service)
+	if len(tasks) != 1 || tasks[0].ID != other.ID {
+		t.Errorf("left: %v", tasks)
+	}
+}
+
+type failingSecondDeleteRepository struct {
+	*memory.TaskRepository
+	deletes int
+}
+
+func (r *failingSecondDeleteRepository) Delete(ctx context.Context, id int) error {
+	r.deletes++
+	if r.deletes > 1 {
+		return errStorage
+	}
+	return r.TaskRepository.Delete(ctx, id)
+}
+
+func TestPurgePartialFailure(t *testing.T) {
+	service, err := NewTaskService(context.Background(), &failingSecondDeleteRepository{TaskRepository: memory.NewTaskRepository()})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	ctx := context.Background()
+	root := mustCreate(t, service, "Root", "D")
+	child := mustCreateChild(t, service, "Child", root.ID)
+
+	if err := service.DeleteTask(ctx, root.ID, domain.DeleteOptions{Cascade: true}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := service.PurgeTask(ctx, root.ID); !errors.Is(err, errStorage) {
+		t.Fatalf("want errStorage, got %v", err)
+	}
+
+	// Хранилище без транзакций успело удалить подзадачу, родитель остался в корзине
+	trash, err := service.ListTrash(ctx)
+	if err != nil {
+		t.Fatalf("trash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != root.ID {
+		t.Errorf("trash: %v", trash)
+	}
+	if _, err := service.RestoreTask(ctx, child.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("child: %v", err)
+	}
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// Операции, которые клиент может отменить.
+const (
+	undoCreate   = "create"
+	undoUpdate   = "update"
+	undoComplete = "complete"
+	undoDelete   = "delete"
+)
+
+const defaultUndoDepth = 20
+
+// undoOp — операция клиента, которую можно отменить и повторить.
+type undoOp struct {
+	kind   string
+	taskID int
+	// before и after — содержимое задачи до и после изменения (undoUpdate).
+	before domain.TaskInput
+	after  domain.TaskInput
+	// statuses — выполненные задачи в состоянии до выполнения, spawned —
+	// созданные при этом повторения (undoComplete).
+	statuses     []*domain.Task
+	completeOpts domain.CompleteOptions
+	spawned      []int
+	// cascade — задача удалялась вместе с подзадачами (undoDelete).
+	cascade bool
+	// expected — затронутые задачи сразу после операции или её повтора.
+	// Если какая-то из них с тех пор изменилась, отменять операцию нельзя.
+	expected []*domain.Task
+}
+
+type undoHistory struct {
+	undo []*undoOp
+	redo []*undoOp
+}
+
+// undoStacks — стеки отмены и повтора каждого клиента. Клиент — автор
+// изменений вместе с пользователем из контекста, см. undoClient. Защищены
+// блокировкой сервиса.
+type undoStacks struct {
+	depth   int
+	clients map[string]*undoHistory
+}
+
+func newUndoStacks(depth int) *undoStacks {
+	return &undoStacks{
+		depth:   depth,
+		clients: make(map[string]*undoHistory),
+	}
+}
+
+// undoClient возвращает клиента, чьи стеки используются в ctx. Стеки
+// разных пользователей не пересекаются, даже если автор назван одинаково.
+// Запрос без пользователя и автора клиента не называет: общий стек всех
+// анонимных клиентов позволил бы отменять чужие операции, поэтому
+// возвращается false.
+func undoClient(ctx context.Context) (string, bool) {
+	actor := domain.ActorFrom(ctx)
+	if domain.UserID(ctx) == 0 && actor == domain.AnonymousActor {
+		return "", false
+	}
+	return strconv.Itoa(domain.UserID(ctx)) + "/" + actor, true
+}
+
+func (u *undoStacks) history(client string) *undoHistory {
+	h, ok := u.clients[client]
+	if !ok {
+		h = &undoHistory{}
+		u.clients[client] = h
+	}
+	return h
+}
+
+// push кладёт операцию на стек, вытесняя самые старые сверх depth.
+func (u *undoStacks) push(stack []*undoOp, op *undoOp) []*undoOp {
+	stack = append(stack, op)
+	if len(stack) > u.depth {
+		stack = stack[len(stack)-u.depth:]
+	}
+	return stack
+}
+
+func pop(stack *[]*undoOp) *undoOp {
+	n := len(*stack)
+	if n == 0 {
+		return nil
+	}
+	op := (*stack)[n-1]
+	*stack = (*stack)[:n-1]
+	return op
+}
+
+// undoEntry дополняет операцию состоянием задач ids сразу после неё. Если
+// отмена отключена или состояние не прочитать, возвращается nil: без него
+// нельзя проверить, что задачи не меняли, поэтому такую операцию не отменить.
+func (s *TaskService) undoEntry(ctx context.Context, op *undoOp, ids ...int) *undoOp {
+	if s.undo.depth <= 0 {
+		return nil
+	}
+	expected, err := s.currentTasks(ctx, ids)
+	if err != nil {
+		return nil
+	}
+	op.expected = expected
+	return op
+}
+
+// pushUndo кладёт операцию на стек отмены клиента из ctx. Новая операция
+// очищает стек повтора. Операции анонимного клиента не запоминаются.
+func (s *TaskService) pushUndo(ctx context.Context, op *undoOp) {
+	client, ok := undoClient(ctx)
+	if op == nil || !ok {
+		return
+	}
+	h := s.undo.history(client)
+	h.undo = s.undo.push(h.undo, op)
+	h.redo = nil
+}
+
+func (s *TaskService) currentTasks(ctx context.Context, ids []int) ([]*domain.Task, error) {
+	tasks := make([]*domain.Task, 0, len(ids))
+	for _, id := range ids {
+		task, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// checkUnchanged проверяет, что задачи не менялись после операции.
+func (s *TaskService) checkUnchanged(ctx context.Context, expected []*domain.Task) error {
+	for _, want := range expected {
+		got, err := s.repo.Get(ctx, want.ID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: task %d no longer exists", domain.ErrUndoConflict, want.ID)
+		}
+		if err != nil {
+			return err
+		}
+		if changes := domain.Diff(want, got); len(changes) > 0 {
+			return fmt.Errorf("%w: task %d changed %s", domain.ErrUndoConflict, want.ID, changes[0].Field)
+		}
+	}
+	return nil
+}
+
+// Undo отменяет последнюю операцию клиента из ctx: созданная задача уходит в
+// корзину, изменённая получает прежнее содержимое, выполненная — прежний
+// статус (созданные повторения уходят в корзину), удалённая возвращается
+// из корзины. Если затронутые задачи изменились после операции,
+// возвращается domain.ErrUndoConflict. Операция, которую не удалось
+// отменить, убирается из стека. Анонимный клиент получает
+// domain.ErrAnonymousClient, см. undoClient.
+func (s *TaskService) Undo(ctx context.Context) (*domain.UndoResult, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	client, ok := undoClient(ctx)
+	if !ok {
+		return nil, domain.ErrAnonymousClient
+	}
+	h := s.undo.history(client)
+	op := pop(&h.undo)
+	if op == nil {
+		return nil, domain.ErrNothingToUndo
+	}
+	if err := s.checkUnchanged(ctx, op.expected); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.applyUndo(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	if op.expected, err = s.currentTasks(ctx, ids); err != nil {
+		return nil, err
+	}
+	h.redo = s.undo.push(h.redo, op)
+	return s.undoResult(ctx, op)
+}
+
+// Redo повторяет последнюю отменённую операцию клиента из ctx.
+func (s *TaskService) Redo(ctx context.Context) (*domain.UndoResult, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	client, ok := undoClient(ctx)
+	if !ok {
+		return nil, domain.ErrAnonymousClient
+	}
+	h := s.undo.history(client)
+	op := pop(&h.redo)
+	if op == nil {
+		return nil, domain.ErrNothingToRedo
+	}
+	if err := s.checkUnchanged(ctx, op.expected); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.applyRedo(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	if op.expected, err = s.currentTasks(ctx, ids); err != nil {
+		return nil, err
+	}
+	h.undo = s.undo.push(h.undo, op)
+	return s.undoResult(ctx, op)
+}
+
+// applyUndo выполняет обратную операцию и возвращает ID затронутых задач.
+func (s *TaskService) applyUndo(ctx context.Context, op *undoOp) ([]int, error) {
+	switch op.kind {
+	case undoCreate:
+		return s.deleteTask(ctx, op.taskID, domain.DeleteOptions{})
+	case undoUpdate:
+		if _, err := s.updateContent(ctx, op.taskID, op.before); err != nil {
+			return nil, err
+		}
+		return []int{op.taskID}, nil
+	case undoComplete:
+		for _, id := range op.spawned {
+			if _, err := s.deleteTask(ctx, id, domain.DeleteOptions{}); err != nil {
+				return nil, err
+			}
+		}
+		return s.restoreStatuses(ctx, op.statuses)
+	case undoDelete:
+		return s.restoreIDs(ctx, op.taskID)
+	default:
+		return nil, fmt.Errorf("unknown undo operation %q", op.kind)
+	}
+}
+
+// applyRedo повторяет операцию и возвращает ID затронутых задач.
+func (s *TaskService) applyRedo(ctx context.Context, op *undoOp) ([]int, error) {
+	switch op.kind {
+	case undoCreate:
+		return s.restoreIDs(ctx, op.taskID)
+	case undoUpdate:
+		if _, err := s.updateContent(ctx, op.taskID, op.after); err != nil {
+			return nil, err
+		}
+		return []int{op.taskID}, nil
+	case undoComplete:
+		res, err := s.transition(ctx, op.taskID, domain.StatusDone, op.completeOpts)
+		if err != nil {
+			return nil, err
+		}
+		op.statuses, op.spawned = res.before, res.spawned
+		ids := slices.Clone(op.spawned)
+		for _, task := range op.statuses {
+			ids = append(ids, task.ID)
+		}
+		return ids, nil
+	case undoDelete:
+		return s.deleteTask(ctx, op.taskID, domain.DeleteOptions{Cascade: op.cascade})
+	default:
+		return nil, fmt.Errorf("unknown undo operation %q", op.kind)
+	}
+}
+
+func (s *TaskService) restoreIDs(ctx context.Context, id int) ([]int, error) {
+	restored, err := s.restoreTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(restored))
+	for _, task := range restored {
+		ids = append(ids, task.ID)
+	}
+	return ids, nil
+}
+
+// restoreStatuses возвращает задачам статус из prev в одной транзакции.
+func (s *TaskService) restoreStatuses(ctx context.Context, prev []*domain.Task) ([]int, error) {
+	ids := make([]int, 0, len(prev))
+	restored := make([]*domain.Task, 0, len(prev))
+	err := repository.WithinTx(ctx, s.repo, func(tx repository.TaskRepository) error {
+		for _, p := range prev {
+			task, err := getActive(ctx, tx, p.ID)
+			if err != nil {
+				return err
+			}
+			task.RestoreStatus(p)
+			if err := saveTask(ctx, tx, domain.StatusEvent(task.CurrentStatus()), task); err != nil {
+				return err
+			}
+			ids = append(ids, task.ID)
+			restored = append(restored, task)
+		}
+		return nil
+	})
+	if err != nil {
+		if len(prev) > 1 {
+			ids = ids[:0]
+			for _, p := range prev {
+				ids = append(ids, p.ID)
+			}
+			s.reindex(ctx, ids)
+		}
+		return nil, err
+	}
+
+	for _, task := range restored {
+		s.indexTask(task)
+	}
+	return ids, nil
+}
+
+func (s *TaskService) undoResult(ctx context.Context, op *undoOp) (*domain.UndoResult, error) {
+	task, err := s.repo.Get(ctx, op.taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !task.IsDeleted() {
+		s.decorate(task)
+	}
+	return &domain.UndoResult{Operation: op.kind, TaskID: op.taskID, Task: task}, nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func TestUndoRedoContentUpdate(t *testing.T) {
+	service := newTestService()
+	ctx := domain.WithActor(context.Background(), "alice")
+
+	task, err := service.CreateTask(ctx, domain.TaskInput{Headline: "v1", Description: "D"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for _, headline := range []string{"v2", "v3"} {
+		if _, err := service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: headline, Description: "D"}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+
+	for _, want := range []string{"v2", "v1"} {
+		res, err := service.Undo(ctx)
+		if err != nil {
+			t.Fatalf("undo: %v", err)
+		}
+		if res.Operation != undoUpdate || res.Task.Headline != want {
+			t.Errorf("undo: %s %q, want %q", res.Operation, res.Task.Headline, want)
+		}
+	}
+	res, err := service.Redo(ctx)
+	if err != nil || res.Task.Headline != "v2" {
+		t.Fatalf("redo: %v %+v", err, res)
+	}
+
+	// Новая операция очищает стек повтора
+	if _, err := service.UpdateContent(ctx, task.ID, domain.TaskInput{Headline: "v4", Description: "D"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := service.Redo(ctx); !errors.Is(err, domain.ErrNothingToRedo) {
+		t.Errorf("redo after new operation: %v", err)
+	}
+
+	// У другого клиента свой стек
+	if _, err := service.Undo(domain.WithActor(context.Background(), "bob")); !errors.Is(err, domain.ErrNothingToUndo) {
+		t.Errorf("bob undo: %v", err)
+	}
+}
+
+func TestUndoRejectedAfterForeignChange(t *testing.T) {
+	service := newTestService()
+	alice := domain.WithActor(context.Background(), "alice")
+	bob := domain.WithActor(context.Background(), "bob")
+
+	task, err := service.CreateTask(alice, domain.TaskInput{Headline: "v1", Description: "D"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := service.UpdateContent(alice, task.ID, domain.TaskInput{Headline: "v2", Description: "D"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := service.UpdateContent(bob, task.ID, domain.TaskInput{Headline: "bob", Description: "D"}); err != nil {
+		t.Fatalf("bob update: %v", err)
+	}
+
+	if _, err := service.Undo(alice); !errors.Is(err, domain.ErrUndoConflict) {
+		t.Fatalf("undo: want ErrUndoConflict, got %v", err)
+	}
+	got, _ := service.GetTask(alice, task.ID)
+	if got.Headline != "bob" {
+		t.Errorf("task changed by rejected undo: %q", got.Headline)
+	}
+}
+
+func TestUndoCreateCompleteDelete(t *testing.T) {
+	service := newTestService()
+	ctx := domain.WithActor(context.Background(), "alice")
+
+	task, err := service.CreateTask(ctx, domain.TaskInput{Headline: "Task", Description: "D"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := service.UpdateTask(ctx, task.ID, domain.CompleteOptions{}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if err := service.DeleteTask(ctx, task.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	res, err := service.Undo(ctx)
+	if err != nil || res.Operation != undoDelete || res.Task.IsDeleted() {
+		t.Fatalf("undo delete: %v %+v", err, res)
+	}
+	res, err = service.Undo(ctx)
+	if err != nil || res.Operation != undoComplete || res.Task.CurrentStatus() != domain.StatusTodo || res.Task.CompletedAt != nil {
+		t.Fatalf("undo complete: %v %+v", err, res)
+	}
+	res, err = service.Undo(ctx)
+	if err != nil || res.Operation != undoCreate || !res.Task.IsDeleted() {
+		t.Fatalf("undo create: %v %+v", err, res)
+	}
+	if _, err := service.GetTask(ctx, task.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("created task still active: %v", err)
+	}
+
+	if _, err := service.Redo(ctx); err != nil {
+		t.Fatalf("redo create: %v", err)
+	}
+	res, err = service.Redo(ctx)
+	if err != nil || !res.Task.Done {
+		t.Fatalf("redo complete: %v %+v", err, res)
+	}
+}
+
+func TestUndoDepth(t *testing.T) {
+	service := newTestService()
+	WithUndoDepth(1)(service)
+	ctx := domain.WithActor(context.Background(), "alice")
+
+	for _, headline := range []string{"First", "Second"} {
+		if _, err := service.CreateTask(ctx, domain.TaskInput{Headline: headline, Description: "D"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if _, err := service.Undo(ctx); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if _, err := service.Undo(ctx); !errors.Is(err, domain.ErrNothingToUndo) {
+		t.Errorf("undo beyond depth: %v", err)
+	}
+}
+
+func TestUndoDeleteKeepsDependencies(t *testing.T) {
+	service := newTestService()
+	ctx := domain.WithActor(context.Background(), "alice")
+	blocker := mustCreate(t, service, "Blocker", "D")
+	task := mustCreateBlocked(t, service, "Task", "", blocker.ID)
+
+	if err := service.DeleteTask(ctx, blocker.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := service.Undo(ctx); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	got, _ := service.GetTask(ctx, task.ID)
+	if !slices.Equal(got.BlockedBy, []int{blocker.ID}) || !slices.Equal(got.OpenBlockers, []int{blocker.ID}) {
+		t.Errorf("after undo: blocked_by %v, open %v", got.BlockedBy, got.OpenBlockers)
+	}
+
+	if _, err := service.Redo(ctx); err != nil {
+		t.Fatalf("redo: %v", err)
+	}
+	if got, _ := service.GetTask(ctx, task.ID); got.IsBlocked() {
+		t.Errorf("after redo: open %v", got.OpenBlockers)
+	}
+}
+
+func TestUndoRequiresClient(t *testing.T) {
+	service := newTestService()
+	anonymous := context.Background()
+
+	task := mustCreate(t, service, "Task", "D")
+	if err := service.DeleteTask(anonymous, task.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := service.Undo(anonymous); !errors.Is(err, domain.ErrAnonymousClient) {
+		t.Errorf("anonymous undo: %v", err)
+	}
+	if _, err := service.Redo(anonymous); !errors.Is(err, domain.ErrAnonymousClient) {
+		t.Errorf("anonymous redo: %v", err)
+	}
+
+	// Операции анонимного клиента не попадают ни в чей стек
+	if _, err := service.Undo(domain.WithActor(anonymous, "alice")); !errors.Is(err, domain.ErrNothingToUndo) {
+		t.Errorf("undo by named client: %v", err)
+	}
+	user := domain.WithUser(anonymous, &domain.User{ID: 1, Name: "alice"})
+	if _, err := service.Undo(user); !errors.Is(err, domain.ErrNothingToUndo) {
+		t.Errorf("undo by user: %v", err)
+	}
+}
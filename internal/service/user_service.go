@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/S1FFFkA/todo-list/internal/auth"
+	"github.com/S1FFFkA/todo-list/internal/domain"
+	"github.com/S1FFFkA/todo-list/internal/repository"
+)
+
+// UserService управляет учётными записями пользователей и их ключами API.
+type UserService struct {
+	repo repository.UserRepository
+	keys repository.APIKeyRepository
+}
+
+func NewUserService(repo repository.UserRepository, keys repository.APIKeyRepository) *UserService {
+	return &UserService{repo: repo, keys: keys}
+}
+
+// CreateUser заводит пользователя. Имя нормализуется, см.
+// domain.NormalizeUserName; если оно занято, возвращается
+// domain.ErrAlreadyExists. Пароль необязателен: без него пользователь
+// входит только по ключам API.
+func (s *UserService) CreateUser(ctx context.Context, name string, password string) (*domain.User, error) {
+	name, err := domain.NormalizeUserName(name)
+	if err != nil {
+		return nil, err
+	}
+	var passwordHash string
+	if password != "" {
+		if err := domain.ValidatePassword(password); err != nil {
+			return nil, err
+		}
+		if passwordHash, err = auth.HashPassword(ctx, password); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		_, err := s.repo.GetByName(ctx, name)
+		if err == nil {
+			return nil, fmt.Errorf("%w: user %q", domain.ErrAlreadyExists, name)
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+
+		user := &domain.User{ID: generateID(), Name: name, PasswordHash: passwordHash, CreatedAt: time.Now()}
+		// ErrAlreadyExists означает, что занят ID или имя: имя проверим
+		// на следующем круге
+		err = s.repo.Create(ctx, user)
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+}
+
+// CreateAPIKey выпускает ключ API пользователю из контекста и возвращает
+// его вместе с секретом. Секрет не сохраняется, получить его повторно
+// нельзя.
+func (s *UserService) CreateAPIKey(ctx context.Context, name string) (*domain.APIKey, string, error) {
+	user, ok := domain.UserFrom(ctx)
+	if !ok {
+		return nil, "", domain.ErrUnauthorized
+	}
+	name, err := domain.NormalizeAPIKeyName(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for {
+		key, secret, err := domain.NewAPIKey(user.ID, name, time.Now())
+		if err != nil {
+			return nil, "", err
+		}
+		key.ID = generateID()
+		err = s.keys.Create(ctx, key)
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return key, secret, nil
+	}
+}
+
+// ListAPIKeys возвращает ключи пользователя из контекста, включая
+// отозванные.
+func (s *UserService) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	user, ok := domain.UserFrom(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	return s.keys.ListByUser(ctx, user.ID)
+}
+
+// RevokeAPIKey отзывает ключ пользователя из контекста. Чужой ключ
+// считается несуществующим.
+func (s *UserService) RevokeAPIKey(ctx context.Context, id int) error {
+	user, ok := domain.UserFrom(ctx)
+	if !ok {
+		return domain.ErrUnauthorized
+	}
+	key, err := s.keys.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key.UserID != user.ID {
+		return domain.ErrNotFound
+	}
+	return s.keys.Revoke(ctx, id, time.Now())
+}
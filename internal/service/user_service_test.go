@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/S1FFFkA/todo-list/internal/domain"
+)
+
+func TestCreateUser(t *testing.T) {
+	users, _ := newTestAuth(t)
+	ctx := context.Background()
+
+	user, err := users.CreateUser(ctx, " Alice ", "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if user.ID == 0 || user.Name != "alice" || user.CreatedAt.IsZero() {
+		t.Errorf("created user: %+v", user)
+	}
+	if _, err := users.CreateUser(ctx, "ALICE", ""); !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("duplicate: want ErrAlreadyExists, got %v", err)
+	}
+	if _, err := users.CreateUser(ctx, "-bob", ""); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("invalid name: want ErrInvalidRequest, got %v", err)
+	}
+	if _, err := users.CreateUser(ctx, "bob", "short"); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("short password: want ErrInvalidRequest, got %v", err)
+	}
+
+	bob, err := users.CreateUser(ctx, "bob", "correct horse")
+	if err != nil {
+		t.Fatalf("create with password: %v", err)
+	}
+	if !bob.HasPassword() || strings.Contains(bob.PasswordHash, "correct horse") {
+		t.Errorf("password hash: %q", bob.PasswordHash)
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	users, authService := newTestAuth(t)
+	alice, err := users.CreateUser(context.Background(), "alice", "")
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	ctx := domain.WithUser(context.Background(), alice)
+
+	key, secret, err := users.CreateAPIKey(ctx, " laptop ")
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	if key.Name != "laptop" || key.UserID != alice.ID || !strings.HasPrefix(secret, key.Hint) || key.Hash == secret {
+		t.Errorf("key: %+v, secret %q", key, secret)
+	}
+
+	identity, err := authService.Authenticate(context.Background(), secret)
+	if err != nil || identity.User.ID != alice.ID || identity.Method != domain.AuthAPIKey {
+		t.Fatalf("authenticate: %+v, %v", identity, err)
+	}
+	keys, err := users.ListAPIKeys(ctx)
+	if err != nil || len(keys) != 1 || keys[0].LastUsedAt == nil {
+		t.Fatalf("list: %+v, %v", keys, err)
+	}
+	if _, err := authService.Authenticate(context.Background(), secret+"x"); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("wrong key: want ErrUnauthorized, got %v", err)
+	}
+
+	bob := domain.WithUser(context.Background(), &domain.User{ID: alice.ID + 1, Name: "bob"})
+	if err := users.RevokeAPIKey(bob, key.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("revoke foreign key: want ErrNotFound, got %v", err)
+	}
+	if err := users.RevokeAPIKey(ctx, key.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := authService.Authenticate(context.Background(), secret); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("revoked key: want ErrUnauthorized, got %v", err)
+	}
+	if _, _, err := users.CreateAPIKey(context.Background(), "anonymous"); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("create without user: want ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestTasksScopedToUser(t *testing.T) {
+	service := newTestService()
+	alice := domain.WithUser(context.Background(), &domain.User{ID: 1, Name: "alice"})
+	bob := domain.WithUser(context.Background(), &domain.User{ID: 2, Name: "bob"})
+
+	task, err := service.CreateTask(alice, domain.TaskInput{Headline: "Secret plan", Description: "D", Tags: []string{"private"}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if task.OwnerID != 1 {
+		t.Errorf("owner = %d, want 1", task.OwnerID)
+	}
+
+	if _, err := service.GetTask(bob, task.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("get: want ErrNotFound, got %v", err)
+	}
+	if _, err := service.UpdateContent(bob, task.ID, domain.TaskInput{Headline: "Mine", Description: "D"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("update: want ErrNotFound, got %v", err)
+	}
+	if err := service.DeleteTask(bob, task.ID, domain.DeleteOptions{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("delete: want ErrNotFound, got %v", err)
+	}
+	if _, err := service.TaskHistory(bob, task.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("history: want ErrNotFound, got %v", err)
+	}
+	if tasks, _ := service.GetAllTasks(bob); len(tasks) != 0 {
+		t.Errorf("list: %+v", tasks)
+	}
+	if hits, _ := service.SearchTasks(bob, "secret", 10); len(hits) != 0 {
+		t.Errorf("search: %+v", hits)
+	}
+	if tags, _ := service.ListTags(bob); len(tags) != 0 {
+		t.Errorf("tags: %+v", tags)
+	}
+	if tasks, _ := service.GetAllTasks(context.Background()); len(tasks) != 0 {
+		t.Errorf("request without user sees owned tasks: %+v", tasks)
+	}
+
+	parent := task.ID
+	if _, err := service.CreateTask(bob, domain.TaskInput{Headline: "Child", Description: "D", ParentID: &parent}); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("foreign parent: want ErrInvalidRequest, got %v", err)
+	}
+	if _, err := service.CreateTask(bob, domain.TaskInput{Headline: "Blocked", Description: "D", BlockedBy: []int{task.ID}}); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("foreign blocker: want ErrInvalidRequest, got %v", err)
+	}
+
+	if err := service.DeleteTask(alice, task.ID, domain.DeleteOptions{}); err != nil {
+		t.Fatalf("delete own: %v", err)
+	}
+	if trash, _ := service.ListTrash(bob); len(trash) != 0 {
+		t.Errorf("trash: %+v", trash)
+	}
+	if _, err := service.Undo(bob); !errors.Is(err, domain.ErrNothingToUndo) {
+		t.Errorf("undo: want ErrNothingToUndo, got %v", err)
+	}
+	if _, err := service.Undo(alice); err != nil {
+		t.Errorf("undo own delete: %v", err)
+	}
+}